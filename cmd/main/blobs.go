@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+
+	"ads/internal/adapters/fsblob"
+	"ads/internal/adapters/s3blob"
+	"ads/internal/ads"
+)
+
+// mediaPath is where the images of the filesystem store are served.
+const mediaPath = "/media"
+
+// newBlobStore picks the store of images by BLOB_STORE: "s3" for an
+// S3-compatible storage set up by the S3_* variables, the local directory
+// MEDIA_DIR otherwise. The handler, if any, serves the images at mediaPath.
+func newBlobStore() (ads.BlobStore, http.Handler, error) {
+	if os.Getenv("BLOB_STORE") == "s3" {
+		store := s3blob.New(s3blob.Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PublicURL: os.Getenv("S3_PUBLIC_URL"),
+		}, nil)
+		return store, nil, nil
+	}
+
+	dir := os.Getenv("MEDIA_DIR")
+	if dir == "" {
+		dir = "./media"
+	}
+	store, err := fsblob.New(dir, os.Getenv("MEDIA_URL")+mediaPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return store, store.Handler(), nil
+}
+
+// thumbnailConfig reads the renditions made of uploaded images from
+// THUMBNAILS, as ads.ParseRenditionSpecs takes them, and the number of
+// workers making them from THUMBNAIL_WORKERS.
+func thumbnailConfig() ([]ads.RenditionSpec, int, error) {
+	specs := ads.DefaultRenditions
+	if value := os.Getenv("THUMBNAILS"); value != "" {
+		var err error
+		if specs, err = ads.ParseRenditionSpecs(value); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	workers := 2
+	if value := os.Getenv("THUMBNAIL_WORKERS"); value != "" {
+		var err error
+		if workers, err = strconv.Atoi(value); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return specs, workers, nil
+}
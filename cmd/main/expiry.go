@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// expiryConfig reads how long ads stay published from AD_TTL, how long
+// before their expiry the authors are warned from AD_EXPIRY_NOTICE and how
+// often the sweeper runs from EXPIRY_SWEEP_INTERVAL, all as Go durations.
+func expiryConfig() (ttl time.Duration, notice time.Duration, interval time.Duration, err error) {
+	durations := []struct {
+		name  string
+		value *time.Duration
+		def   time.Duration
+	}{
+		{"AD_TTL", &ttl, 30 * 24 * time.Hour},
+		{"AD_EXPIRY_NOTICE", &notice, 3 * 24 * time.Hour},
+		{"EXPIRY_SWEEP_INTERVAL", &interval, time.Minute},
+	}
+	for _, d := range durations {
+		*d.value = d.def
+		value := os.Getenv(d.name)
+		if value == "" {
+			continue
+		}
+		if *d.value, err = time.ParseDuration(value); err != nil || *d.value <= 0 {
+			return 0, 0, 0, fmt.Errorf("%s must be a positive duration", d.name)
+		}
+	}
+	return ttl, notice, interval, nil
+}
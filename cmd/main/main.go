@@ -11,13 +11,14 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// the runtime image has no zoneinfo for the tz filters
+	_ "time/tzdata"
 
-	"ads/internal/adapters/adrepo"
 	"ads/internal/adapters/pgrepo"
-	"ads/internal/adapters/userrepo"
 	"ads/internal/app"
 	grpcPort "ads/internal/ports/grpc"
 	"ads/internal/ports/httpgin"
+	"ads/schema"
 
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sync/errgroup"
@@ -39,13 +40,102 @@ func main() {
 		logrus.Fatalf("failed to initialize db: %s", err.Error())
 	}
 
-	a := app.NewApp(adrepo.New(), userrepo.New(), pgrepo.NewAuthPostgres(db))
+	migrator, err := pgrepo.NewMigrator(db, schema.FS)
+	if err != nil {
+		logrus.Fatalf("failed to read migrations: %s", err.Error())
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), migrator, os.Args[2:]); err != nil {
+			logrus.Fatalf("migrate: %s", err.Error())
+		}
+		return
+	}
+
+	applied, err := migrator.Up(context.Background())
+	if err != nil {
+		logrus.Fatalf("failed to migrate db: %s", err.Error())
+	}
+	log.Printf("applied %d migration(s)\n", applied)
+
+	users := pgrepo.NewUserPostgres(db)
+	signingKey := os.Getenv("SIGNING_KEY")
+	if signingKey == "" {
+		logrus.Fatalf("SIGNING_KEY is not set")
+	}
+	if signingKey == "change-me" {
+		logrus.Fatalf("SIGNING_KEY is the published placeholder, set a random secret")
+	}
+
+	blobs, media, err := newBlobStore()
+	if err != nil {
+		logrus.Fatalf("failed to initialize blob store: %s", err.Error())
+	}
+
+	renditions, workers, err := thumbnailConfig()
+	if err != nil {
+		logrus.Fatalf("failed to configure thumbnails: %s", err.Error())
+	}
+
+	ttl, notice, sweepInterval, err := expiryConfig()
+	if err != nil {
+		logrus.Fatalf("failed to configure expiry: %s", err.Error())
+	}
+
+	review, err := reviewConfig()
+	if err != nil {
+		logrus.Fatalf("failed to configure review: %s", err.Error())
+	}
+	reportThreshold, err := reportThresholdConfig()
+	if err != nil {
+		logrus.Fatalf("failed to configure reports: %s", err.Error())
+	}
+
+	opts := []app.Option{
+		app.WithSigningKey([]byte(signingKey)),
+		app.WithBlobStore(blobs),
+		app.WithThumbnails(renditions, workers),
+		app.WithAdExpiry(ttl, notice),
+		app.WithExpirySweeper(sweepInterval),
+		app.WithReportThreshold(reportThreshold),
+	}
+	if review {
+		opts = append(opts, app.WithReview())
+	}
+	a := app.NewApp(pgrepo.NewAdPostgres(db), users, users, opts...)
+	defer a.Close()
+
+	if err := loadScreeningRules(a); err != nil {
+		logrus.Fatalf("failed to load screening rules: %s", err.Error())
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "sessions" {
+		if err := runSessions(context.Background(), a, os.Args[2:]); err != nil {
+			logrus.Fatalf("sessions: %s", err.Error())
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "users" {
+		if err := runUsers(context.Background(), a, os.Args[2:]); err != nil {
+			logrus.Fatalf("users: %s", err.Error())
+		}
+		return
+	}
 
 	svr := httpgin.NewHTTPServer(":18080", a)
 
+	handler := svr.Handler
+	if media != nil {
+		mux := http.NewServeMux()
+		mux.Handle(mediaPath+"/", media)
+		mux.Handle("/", svr.Handler)
+		handler = mux
+	}
+
 	httpServer := &http.Server{
 		Addr:    ":18080",
-		Handler: svr.Handler,
+		Handler: handler,
 	}
 
 	port := ":50054"
@@ -57,6 +147,7 @@ func main() {
 	grpcServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(grpcPort.UnaryServerInterceptorPanicMethod),
 		grpc.ChainUnaryInterceptor(grpcPort.UnaryServerInterceptorLogMethod),
+		grpc.ChainUnaryInterceptor(grpcPort.UnaryServerInterceptorAuth(a)),
 	)
 
 	svc := grpcPort.NewService(a)
@@ -65,9 +156,16 @@ func main() {
 	eg, ctx := errgroup.WithContext(context.Background())
 
 	sigQuit := make(chan os.Signal, 1)
-	signal.Ignore(syscall.SIGHUP, syscall.SIGPIPE)
+	signal.Ignore(syscall.SIGPIPE)
 	signal.Notify(sigQuit, syscall.SIGINT, syscall.SIGTERM)
 
+	// SIGHUP reloads the screening rules
+	sigReload := make(chan os.Signal, 1)
+	signal.Notify(sigReload, syscall.SIGHUP)
+	eg.Go(func() error {
+		return reloadScreeningRules(ctx, a, sigReload)
+	})
+
 	eg.Go(func() error {
 		select {
 		case s := <-sigQuit:
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// reviewConfig reads from AD_REVIEW whether the ads wait for a moderator
+// before they are published, which they do unless it is false.
+func reviewConfig() (bool, error) {
+	value := os.Getenv("AD_REVIEW")
+	if value == "" {
+		return true, nil
+	}
+	review, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("AD_REVIEW must be true or false")
+	}
+	return review, nil
+}
+
+// reportThresholdConfig reads from REPORT_THRESHOLD how many open reports
+// hide an ad, 3 unless it is set.
+func reportThresholdConfig() (int, error) {
+	value := os.Getenv("REPORT_THRESHOLD")
+	if value == "" {
+		return 3, nil
+	}
+	threshold, err := strconv.Atoi(value)
+	if err != nil || threshold <= 0 {
+		return 0, fmt.Errorf("REPORT_THRESHOLD must be a positive number")
+	}
+	return threshold, nil
+}
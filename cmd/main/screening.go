@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"ads/internal/app"
+)
+
+// loadScreeningRules sets the screening rules of a from the JSON file named
+// by SCREENING_RULES, and keeps none when it is not set.
+func loadScreeningRules(a app.App) error {
+	path := os.Getenv("SCREENING_RULES")
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read SCREENING_RULES: %w", err)
+	}
+	rules, err := app.ParseScreeningRules(data)
+	if err != nil {
+		return err
+	}
+	return a.SetScreeningRules(rules)
+}
+
+// reloadScreeningRules loads the screening rules again on every signal until
+// ctx is done. The rules in use stay when the new ones fail to load.
+func reloadScreeningRules(ctx context.Context, a app.App, signals <-chan os.Signal) error {
+	for {
+		select {
+		case <-signals:
+			if err := loadScreeningRules(a); err != nil {
+				log.Printf("failed to reload screening rules: %s\n", err.Error())
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"ads/internal/app"
+)
+
+const sessionsUsage = "usage: app sessions revoke <user_id>"
+
+// runSessions handles the "sessions" subcommand.
+func runSessions(ctx context.Context, a app.App, args []string) error {
+	if len(args) != 2 || args[0] != "revoke" {
+		return errors.New(sessionsUsage)
+	}
+
+	userID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return errors.New(sessionsUsage)
+	}
+
+	if err := a.RevokeUserSessions(operatorContext(ctx), userID); err != nil {
+		return err
+	}
+	fmt.Printf("revoked sessions of user %d\n", userID)
+
+	return nil
+}
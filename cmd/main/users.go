@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"ads/internal/app"
+	"ads/internal/user"
+)
+
+const usersUsage = "usage: app users role <user_id> user|moderator|admin"
+
+// runUsers handles the "users" subcommand. It is how the first admin is made.
+func runUsers(ctx context.Context, a app.App, args []string) error {
+	if len(args) != 3 || args[0] != "role" {
+		return errors.New(usersUsage)
+	}
+
+	userID, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return errors.New(usersUsage)
+	}
+
+	u, err := a.SetUserRole(operatorContext(ctx), userID, user.Role(args[2]))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("user %d is now %s\n", u.UserID, u.Role)
+
+	return nil
+}
+
+// operatorContext lets the command line act as an admin.
+func operatorContext(ctx context.Context) context.Context {
+	return app.ContextWithPrincipal(ctx, app.Principal{Role: user.RoleAdmin})
+}
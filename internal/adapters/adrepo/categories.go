@@ -0,0 +1,115 @@
+package adrepo
+
+import (
+	"context"
+	"fmt"
+
+	"ads/internal/ads"
+)
+
+func (r *AdRepositoryMap) AddCategory(ctx context.Context, category *ads.Category) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.slugTaken(category.Slug, -1) {
+		return 0, fmt.Errorf("slug is already taken")
+	}
+	r.countCategoryID += 1
+	c := *category
+	c.ID = r.countCategoryID
+	r.categories[c.ID] = &c
+	category.ID = c.ID
+
+	return c.ID, nil
+}
+
+func (r *AdRepositoryMap) UpdateCategory(ctx context.Context, category *ads.Category) (*ads.Category, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	c, ok := r.categories[category.ID]
+	if !ok {
+		return nil, fmt.Errorf("is no such category")
+	}
+	if r.slugTaken(category.Slug, category.ID) {
+		return nil, fmt.Errorf("slug is already taken")
+	}
+	*c = *category
+
+	result := *c
+	return &result, nil
+}
+
+func (r *AdRepositoryMap) GetCategory(ctx context.Context, categoryID int64) (*ads.Category, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	c, ok := r.categories[categoryID]
+	if !ok {
+		return nil, fmt.Errorf("is no such category")
+	}
+	result := *c
+	return &result, nil
+}
+
+func (r *AdRepositoryMap) GetCategoryBySlug(ctx context.Context, slug string) (*ads.Category, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, c := range r.categories {
+		if c.Slug == slug {
+			result := *c
+			return &result, nil
+		}
+	}
+	return nil, fmt.Errorf("is no such category")
+}
+
+func (r *AdRepositoryMap) ListCategories(ctx context.Context) ([]*ads.Category, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.listCategories(), nil
+}
+
+func (r *AdRepositoryMap) listCategories() []*ads.Category {
+	result := make([]*ads.Category, 0, len(r.categories))
+	for id := int64(0); id <= r.countCategoryID; id++ {
+		if c, ok := r.categories[id]; ok {
+			copied := *c
+			result = append(result, &copied)
+		}
+	}
+	return result
+}
+
+func (r *AdRepositoryMap) DeleteCategory(ctx context.Context, categoryID int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.categories[categoryID]; !ok {
+		return fmt.Errorf("is no such category")
+	}
+	for _, c := range r.categories {
+		if c.ParentID != nil && *c.ParentID == categoryID {
+			return ads.ErrCategoryNotEmpty
+		}
+	}
+	for _, ad := range r.mapRep {
+		if ad.CategoryID == categoryID {
+			return ads.ErrCategoryNotEmpty
+		}
+	}
+	delete(r.categories, categoryID)
+
+	return nil
+}
+
+func (r *AdRepositoryMap) slugTaken(slug string, exceptID int64) bool {
+	for _, c := range r.categories {
+		if c.Slug == slug && c.ID != exceptID {
+			return true
+		}
+	}
+	return false
+}
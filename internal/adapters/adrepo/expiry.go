@@ -0,0 +1,69 @@
+package adrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ads/internal/ads"
+)
+
+func (r *AdRepositoryMap) Renew(ctx context.Context, adID int64, from ads.Status, expiresAt time.Time) (*ads.Ad, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	ad, ok := r.mapRep[keyID(adID)]
+	if !ok {
+		return nil, fmt.Errorf("is no such ad")
+	}
+	if ad.Status != from {
+		return nil, ads.ErrStatusChanged
+	}
+	ad.UpdateDate = time.Now().UTC()
+	ad.Version++
+	ad.Status = ads.StatusPublished
+	ad.ExpiresAt = &expiresAt
+	ad.ExpiryNotified = false
+
+	result := *ad
+	return &result, nil
+}
+
+func (r *AdRepositoryMap) ExpireAds(ctx context.Context, now time.Time) ([]*ads.Ad, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.changeExpiring(now, func(ad *ads.Ad) bool {
+		ad.Status = ads.StatusArchived
+		ad.UpdateDate = time.Now().UTC()
+		ad.Version++
+		return true
+	}), nil
+}
+
+func (r *AdRepositoryMap) ClaimExpiring(ctx context.Context, before time.Time) ([]*ads.Ad, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.changeExpiring(before, func(ad *ads.Ad) bool {
+		if ad.ExpiryNotified {
+			return false
+		}
+		ad.ExpiryNotified = true
+		return true
+	}), nil
+}
+
+// changeExpiring applies change to the published ads expiring by t and
+// returns copies of those it changed, in the order of ids.
+func (r *AdRepositoryMap) changeExpiring(t time.Time, change func(ad *ads.Ad) bool) []*ads.Ad {
+	result := []*ads.Ad{}
+	for _, ad := range r.mapRep {
+		if ad.Published() && ad.Expired(t) && change(ad) {
+			changed := *ad
+			result = append(result, &changed)
+		}
+	}
+	ads.SortAds(result, "")
+	return result
+}
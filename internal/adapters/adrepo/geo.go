@@ -0,0 +1,73 @@
+package adrepo
+
+import "ads/internal/ads"
+
+// geoPrecisions is the length of the longest geohashes of the grid, whose
+// cells are about 5 km wide.
+const geoPrecisions = 5
+
+// maxGeoCells is how many cells a query looks in at most. It looks at the
+// finest precision covering its area with no more cells.
+const maxGeoCells = 32
+
+// geoIndex is a grid of geohash cells holding the ads located in them, at
+// every precision up to geoPrecisions.
+type geoIndex struct {
+	cells map[string]map[keyID]bool
+}
+
+func newGeoIndex() *geoIndex {
+	return &geoIndex{cells: make(map[string]map[keyID]bool)}
+}
+
+// hashes are the cells of ad at every precision, none for an ad with no
+// location.
+func (g *geoIndex) hashes(ad *ads.Ad) []string {
+	l := ad.Location()
+	if l == nil {
+		return nil
+	}
+	hash := ads.Geohash(*l, geoPrecisions)
+	result := make([]string, 0, geoPrecisions)
+	for p := 1; p <= geoPrecisions; p++ {
+		result = append(result, hash[:p])
+	}
+	return result
+}
+
+func (g *geoIndex) add(ad *ads.Ad) {
+	for _, hash := range g.hashes(ad) {
+		if g.cells[hash] == nil {
+			g.cells[hash] = make(map[keyID]bool)
+		}
+		g.cells[hash][keyID(ad.ID)] = true
+	}
+}
+
+// remove drops ad from the grid. It must be called before ad moves.
+func (g *geoIndex) remove(ad *ads.Ad) {
+	for _, hash := range g.hashes(ad) {
+		delete(g.cells[hash], keyID(ad.ID))
+		if len(g.cells[hash]) == 0 {
+			delete(g.cells, hash)
+		}
+	}
+}
+
+// within lists the ads in the cells covering b, a superset of the ads in b.
+func (g *geoIndex) within(b ads.Box) map[keyID]bool {
+	result := make(map[keyID]bool)
+	for p := geoPrecisions; p >= 1; p-- {
+		hashes, ok := ads.GeohashesCovering(b, p, maxGeoCells)
+		if !ok {
+			continue
+		}
+		for _, hash := range hashes {
+			for id := range g.cells[hash] {
+				result[id] = true
+			}
+		}
+		break
+	}
+	return result
+}
@@ -0,0 +1,155 @@
+package adrepo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"ads/internal/ads"
+)
+
+// The images of an ad are kept in its Images, in the order of positions.
+
+func (r *AdRepositoryMap) AddImage(ctx context.Context, image *ads.Image) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	ad, ok := r.mapRep[keyID(image.AdID)]
+	if !ok {
+		return 0, fmt.Errorf("is no such ad")
+	}
+	if image.Position != len(ad.Images) {
+		return 0, fmt.Errorf("image position %d is taken", image.Position)
+	}
+
+	r.countImageID += 1
+	img := *image
+	img.ID = r.countImageID
+	ad.Images = append(ad.Images, &img)
+	ad.Version++
+	image.ID = img.ID
+
+	return img.ID, nil
+}
+
+func (r *AdRepositoryMap) DeleteImage(ctx context.Context, adID int64, imageID int64) (*ads.Image, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	ad, ok := r.mapRep[keyID(adID)]
+	if !ok {
+		return nil, fmt.Errorf("is no such ad")
+	}
+
+	for i, img := range ad.Images {
+		if img.ID != imageID {
+			continue
+		}
+		images := append([]*ads.Image{}, ad.Images[:i]...)
+		for _, next := range ad.Images[i+1:] {
+			moved := *next
+			moved.Position--
+			images = append(images, &moved)
+		}
+		ad.Images = images
+		ad.Version++
+		return img, nil
+	}
+
+	return nil, fmt.Errorf("is no such image")
+}
+
+func (r *AdRepositoryMap) ReorderImages(ctx context.Context, adID int64, imageIDs []int64) error {
+	r.Lock()
+	defer r.Unlock()
+
+	ad, ok := r.mapRep[keyID(adID)]
+	if !ok {
+		return fmt.Errorf("is no such ad")
+	}
+
+	byID := make(map[int64]*ads.Image)
+	for _, img := range ad.Images {
+		byID[img.ID] = img
+	}
+	if len(imageIDs) != len(byID) {
+		return fmt.Errorf("not every image is ordered")
+	}
+
+	images := make([]*ads.Image, 0, len(imageIDs))
+	for pos, id := range imageIDs {
+		img, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("is no such image")
+		}
+		delete(byID, id)
+		moved := *img
+		moved.Position = pos
+		images = append(images, &moved)
+	}
+	ad.Images = images
+	ad.Version++
+
+	return nil
+}
+
+func (r *AdRepositoryMap) AddRendition(ctx context.Context, adID int64, rendition *ads.Rendition) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	ad, ok := r.mapRep[keyID(adID)]
+	if !ok {
+		return 0, fmt.Errorf("is no such ad")
+	}
+
+	for i, img := range ad.Images {
+		if img.ID != rendition.ImageID {
+			continue
+		}
+		r.countRenditionID += 1
+		rend := *rendition
+		rend.ID = r.countRenditionID
+		rendition.ID = rend.ID
+
+		withRendition := *img
+		withRendition.Renditions = append(append([]*ads.Rendition{}, img.Renditions...), &rend)
+		images := append([]*ads.Image{}, ad.Images...)
+		images[i] = &withRendition
+		ad.Images = images
+		ad.Version++
+
+		return rend.ID, nil
+	}
+
+	return 0, fmt.Errorf("is no such image")
+}
+
+func (r *AdRepositoryMap) ImagesLackingRenditions(ctx context.Context, names []string, before time.Time) ([]*ads.Image, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	var images []*ads.Image
+	for _, ad := range r.mapRep {
+		for _, img := range ad.Images {
+			if img.CreateDate.Before(before) && lacksRendition(img, names) {
+				found := *img
+				images = append(images, &found)
+			}
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		return images[i].ID < images[j].ID
+	})
+
+	return images, nil
+}
+
+func lacksRendition(img *ads.Image, names []string) bool {
+	for _, name := range names {
+		if img.Rendition(name) == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -3,7 +3,6 @@ package adrepo
 import (
 	"context"
 	"fmt"
-	"strings"
 	"sync"
 	"time"
 
@@ -17,113 +16,196 @@ type AdRepositoryMap struct {
 	sync.Mutex
 	countID int64
 	mapRep map[keyID]adStructType
+
+	countCategoryID int64
+	categories map[int64]*ads.Category
+
+	countImageID int64
+	countRenditionID int64
+
+	countReportID int64
+	reports map[int64]*ads.Report
+
+	countRevisionID int64
+	revisions map[int64][]*ads.Revision // by ad, in the order of numbers
+
+	index *searchIndex
+	geo *geoIndex
 }
 
+// The ads are guarded by the mutex, as the thumbnail workers change them in
+// the background, and leave the repository only as copies.
+
 func (r *AdRepositoryMap) Add(ctx context.Context, ad *ads.Ad) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
 	r.countID += 1
 	ad.ID = r.countID
-	r.mapRep[keyID(r.countID)] = ad
+	stored := *ad
+	r.mapRep[keyID(r.countID)] = &stored
+	r.index.add(&stored)
+	r.geo.add(&stored)
+	r.addRevision(ads.NewRevision(&stored, stored.AuthorID), stored.CreateDate)
 
 	return r.countID, nil
 }
 
-func (r *AdRepositoryMap) ChangeStatus(ctx context.Context, adID int64, published bool, authorID int64) (*ads.Ad, error) {
+func (r *AdRepositoryMap) ChangeStatus(ctx context.Context, adID int64, from ads.Status, to ads.Status, reason string, expiresAt *time.Time, held bool) (*ads.Ad, error) {
+	r.Lock()
+	defer r.Unlock()
+
 	ad, ok := r.mapRep[keyID(adID)]
 
 	if !ok {
 		return nil, fmt.Errorf("is no such ad")
 	}
+	if ad.Status != from {
+		return nil, ads.ErrStatusChanged
+	}
 	ad.UpdateDate = time.Now().UTC()
-	ad.Published = published
-
-	return ad, nil
+	ad.Version++
+	ad.Status = to
+	ad.RejectionReason = reason
+	ad.Held = held
+	ad.ExpiresAt = nil
+	if expiresAt != nil {
+		expires := *expiresAt
+		ad.ExpiresAt = &expires
+	}
+	ad.ExpiryNotified = false
+
+	result := *ad
+	return &result, nil
 }
 
-func (r *AdRepositoryMap) Update(ctx context.Context, authorID int64, title string, text string, adID int64) (*ads.Ad, error) {
-	ad, ok := r.mapRep[keyID(adID)]
+func (r *AdRepositoryMap) Update(ctx context.Context, edit *ads.Revision, screening ads.Screening, version *int64, review bool) (*ads.Ad, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	ad, ok := r.mapRep[keyID(edit.AdID)]
 	
 	if !ok {
 		return nil, fmt.Errorf("is no such ad")
 	}
+	if version != nil && ad.Version != *version {
+		return nil, ads.ErrVersionMismatch
+	}
 
+	r.index.remove(ad)
+	r.geo.remove(ad)
 	ad.UpdateDate = time.Now().UTC()
-	ad.Title = title
-	ad.Text = text
-
-	return ad, nil
+	ad.Version++
+	edit.Apply(ad)
+	ad.Screening = screening
+	if review && ad.Published() {
+		ad.Status = ads.StatusPendingReview
+		ad.ExpiresAt = nil
+		ad.ExpiryNotified = false
+	}
+	r.index.add(ad)
+	r.geo.add(ad)
+	r.addRevision(edit, ad.UpdateDate)
+
+	result := *ad
+	return &result, nil
 }
 
 func (r *AdRepositoryMap) GetAd(ctx context.Context, adID int64) (*ads.Ad, error) {
+	r.Lock()
+	defer r.Unlock()
+
 	ad, ok := r.mapRep[keyID(adID)]
 	if !ok {
 		return nil, fmt.Errorf("is no such ad")
 	}
-	return ad, nil
+	result := *ad
+	return &result, nil
 }
 
-func (r *AdRepositoryMap) ListAds(ctx context.Context) ([]*ads.Ad, error) {
+func (r *AdRepositoryMap) Find(ctx context.Context, q ads.Query) (*ads.List, error) {
+	r.Lock()
+	defer r.Unlock()
+
 	if r.mapRep == nil {
 		return nil, fmt.Errorf("not map repository")
 	}
-	result := []*ads.Ad{}
-	for _, ad := range r.mapRep {
-		if ad.Published {
-			result = append(result, ad)
+
+	var inTree map[int64]bool
+	if q.CategoryID != nil {
+		categories := r.listCategories()
+		inTree = make(map[int64]bool)
+		for _, id := range ads.Subtree(categories, *q.CategoryID) {
+			inTree[id] = true
 		}
 	}
-	if len(result) == 0 {
-		return nil, fmt.Errorf("not found ad")
-	}
 
-	return result, nil
-}
+	match := func(ad *ads.Ad) bool {
+		return q.Match(ad) && (inTree == nil || inTree[ad.CategoryID])
+	}
 
-func(r *AdRepositoryMap) Search(ctx context.Context, title string) ([]*ads.Ad, error) {
-	var result []*ads.Ad
-	for _, i := range r.mapRep {
-		if strings.HasPrefix(i.Title, title) {
-			result = append(result, i)
+	origin := q.Geo.Origin()
+	found := func(ad *ads.Ad) *ads.Ad {
+		copied := *ad
+		if origin != nil {
+			distance := ads.DistanceKm(*origin, *ad.Location())
+			copied.Distance = &distance
 		}
+		return &copied
 	}
-	if len(result) == 0 {
-		return nil, fmt.Errorf("not found ad")
-	}
-	return result, nil
-}
 
-func (r *AdRepositoryMap) ListAdsAuthor(ctx context.Context, author int64) ([]*ads.Ad, error) {
-	if r.mapRep == nil {
-		return nil, fmt.Errorf("not map repository")
-	}
 	result := []*ads.Ad{}
-	for _, ad := range r.mapRep {
-		if ad.AuthorID == author {
-			result = append(result, ad)
+	if q.Search != "" {
+		for id, rank := range r.index.search(ads.ParseSearch(q.Search)) {
+			if ad := r.mapRep[id]; match(ad) {
+				ad := found(ad)
+				ad.Rank = rank
+				result = append(result, ad)
+			}
+		}
+	} else if bounds := q.Geo.Bounds(); bounds != nil {
+		for id := range r.geo.within(*bounds) {
+			if ad := r.mapRep[id]; match(ad) {
+				result = append(result, found(ad))
+			}
+		}
+	} else {
+		for _, ad := range r.mapRep {
+			if match(ad) {
+				result = append(result, found(ad))
+			}
 		}
 	}
-	if len(result) == 0 {
-		return nil, fmt.Errorf("not found ad")
+
+	var facets *ads.Facets
+	if q.Facets {
+		facets = ads.CountFacets(result)
+	}
+	list, err := ads.Paginate(result, q.Page)
+	if err != nil {
+		return nil, err
 	}
-	return result, nil
+	list.Facets = facets
+	return list, nil
 }
 
-func (r *AdRepositoryMap) ListAdsDate(ctx context.Context, day int64) ([]*ads.Ad, error) {
-	if r.mapRep == nil {
-		return nil, fmt.Errorf("not map repository")
-	}
-	result := []*ads.Ad{}
+func (r *AdRepositoryMap) Suggest(ctx context.Context, query string, limit int) (*ads.Suggestions, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	var titles []string
 	for _, ad := range r.mapRep {
-		if int64(ad.CreateDate.Day()) == day {
-			result = append(result, ad)
+		if ad.Published() {
+			titles = append(titles, ad.Title)
 		}
 	}
-	if len(result) == 0 {
-		return nil, fmt.Errorf("not found ad")
-	}
-	return result, nil
+	return ads.Suggest(titles, query, limit), nil
 }
 
 func (r *AdRepositoryMap) DeleteAd(ctx context.Context, authorID int64, adID int64) (*ads.Ad, error) {
+	r.Lock()
+	defer r.Unlock()
+
 	ad, ok := r.mapRep[keyID(adID)]
 	if !ok {
 		return nil, fmt.Errorf("not delete")
@@ -131,6 +213,10 @@ func (r *AdRepositoryMap) DeleteAd(ctx context.Context, authorID int64, adID int
 	fmt.Println(ad.AuthorID, authorID)
 	if ad.AuthorID == authorID {
 		delete(r.mapRep, keyID(adID))
+		r.index.remove(ad)
+		r.geo.remove(ad)
+		r.deleteReports(adID)
+		delete(r.revisions, adID)
 		return ad, nil
 	}
 
@@ -141,5 +227,15 @@ func New() ads.RepositryAd {
 	return &AdRepositoryMap{
 		countID: -1,
 		mapRep: make(map[keyID]adStructType),
+		countCategoryID: -1,
+		categories: make(map[int64]*ads.Category),
+		countImageID: -1,
+		countRenditionID: -1,
+		countReportID: -1,
+		reports: make(map[int64]*ads.Report),
+		countRevisionID: -1,
+		revisions: make(map[int64][]*ads.Revision),
+		index: newSearchIndex(),
+		geo: newGeoIndex(),
 		}
 }
@@ -0,0 +1,108 @@
+package adrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ads/internal/ads"
+)
+
+func (r *AdRepositoryMap) AddReport(ctx context.Context, report *ads.Report) (int64, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.mapRep[keyID(report.AdID)]; !ok {
+		return 0, fmt.Errorf("is no such ad")
+	}
+	for _, other := range r.reports {
+		if other.AdID == report.AdID && other.ReporterID == report.ReporterID {
+			return 0, ads.ErrAlreadyReported
+		}
+	}
+
+	r.countReportID += 1
+	stored := *report
+	stored.ID = r.countReportID
+	r.reports[stored.ID] = &stored
+	report.ID = stored.ID
+
+	return stored.ID, nil
+}
+
+func (r *AdRepositoryMap) GetReport(ctx context.Context, reportID int64) (*ads.Report, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	report, ok := r.reports[reportID]
+	if !ok {
+		return nil, fmt.Errorf("is no such report")
+	}
+	result := *report
+	return &result, nil
+}
+
+func (r *AdRepositoryMap) ListReports(ctx context.Context, q ads.ReportQuery) ([]*ads.Report, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	result := []*ads.Report{}
+	for id := int64(0); id <= r.countReportID && len(result) < q.Limit; id++ {
+		report, ok := r.reports[id]
+		if !ok || !matchReport(q, report) {
+			continue
+		}
+		copied := *report
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func matchReport(q ads.ReportQuery, report *ads.Report) bool {
+	if q.AdID != nil && report.AdID != *q.AdID {
+		return false
+	}
+	if q.Status != nil && report.Status != *q.Status {
+		return false
+	}
+	return q.AfterID == nil || report.ID > *q.AfterID
+}
+
+func (r *AdRepositoryMap) ResolveReports(ctx context.Context, adID int64, reportID *int64, status ads.ReportStatus, moderatorID int64) ([]*ads.Report, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if reportID != nil {
+		report, ok := r.reports[*reportID]
+		if !ok || report.AdID != adID {
+			return nil, fmt.Errorf("is no such report")
+		}
+		if report.Status != ads.ReportOpen {
+			return nil, ads.ErrReportResolved
+		}
+	}
+
+	now := time.Now().UTC()
+	result := []*ads.Report{}
+	for id := int64(0); id <= r.countReportID; id++ {
+		report, ok := r.reports[id]
+		if !ok || report.AdID != adID || report.Status != ads.ReportOpen || reportID != nil && id != *reportID {
+			continue
+		}
+		report.Status = status
+		report.ResolvedBy = &moderatorID
+		report.ResolveDate = &now
+		copied := *report
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+// deleteReports removes the reports of an ad that is deleted.
+func (r *AdRepositoryMap) deleteReports(adID int64) {
+	for id, report := range r.reports {
+		if report.AdID == adID {
+			delete(r.reports, id)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package adrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ads/internal/ads"
+)
+
+// addRevision stores rev as the next revision of its ad, made at date.
+func (r *AdRepositoryMap) addRevision(rev *ads.Revision, date time.Time) {
+	history := r.revisions[rev.AdID]
+	var before *ads.Revision
+	if len(history) > 0 {
+		before = history[len(history)-1]
+	}
+
+	r.countRevisionID += 1
+	rev.ID = r.countRevisionID
+	rev.Number = len(history) + 1
+	rev.Diff = rev.DiffFrom(before)
+	rev.CreateDate = date
+	stored := *rev
+	r.revisions[rev.AdID] = append(history, &stored)
+}
+
+func (r *AdRepositoryMap) ListRevisions(ctx context.Context, adID int64) ([]*ads.Revision, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if _, ok := r.mapRep[keyID(adID)]; !ok {
+		return nil, fmt.Errorf("is no such ad")
+	}
+	result := []*ads.Revision{}
+	for _, rev := range r.revisions[adID] {
+		copied := *rev
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func (r *AdRepositoryMap) GetRevision(ctx context.Context, adID int64, number int) (*ads.Revision, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	history := r.revisions[adID]
+	if number < 1 || number > len(history) {
+		return nil, fmt.Errorf("is no such revision")
+	}
+	result := *history[number-1]
+	return &result, nil
+}
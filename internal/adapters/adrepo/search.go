@@ -0,0 +1,105 @@
+package adrepo
+
+import "ads/internal/ads"
+
+// searchIndex is an inverted index from a word to its positions in every ad
+// that has it. The words of the text are numbered after a gap following the
+// title, so a phrase never runs from the title into the text.
+type searchIndex struct {
+	postings map[string]map[keyID][]int
+	// titles holds the number of title words of each ad
+	titles map[keyID]int
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		postings: make(map[string]map[keyID][]int),
+		titles:   make(map[keyID]int),
+	}
+}
+
+func (x *searchIndex) add(ad *ads.Ad) {
+	id := keyID(ad.ID)
+	title := ads.Tokenize(ad.Title)
+	x.titles[id] = len(title)
+
+	words := append(title, "")
+	words = append(words, ads.Tokenize(ad.Text)...)
+	for pos, word := range words {
+		if word == "" {
+			continue
+		}
+		if x.postings[word] == nil {
+			x.postings[word] = make(map[keyID][]int)
+		}
+		x.postings[word][id] = append(x.postings[word][id], pos)
+	}
+}
+
+// remove drops ad from the index. It must be called before the title or
+// the text of ad change.
+func (x *searchIndex) remove(ad *ads.Ad) {
+	id := keyID(ad.ID)
+	for _, word := range append(ads.Tokenize(ad.Title), ads.Tokenize(ad.Text)...) {
+		delete(x.postings[word], id)
+		if len(x.postings[word]) == 0 {
+			delete(x.postings, word)
+		}
+	}
+	delete(x.titles, id)
+}
+
+// search ranks the ads having all of the phrases. An occurrence of a phrase
+// adds the weight of the field it starts in.
+func (x *searchIndex) search(phrases [][]string) map[keyID]float64 {
+	var ranks map[keyID]float64
+	for _, phrase := range phrases {
+		hits := x.phrase(phrase)
+		if ranks == nil {
+			ranks = hits
+			continue
+		}
+		for id := range ranks {
+			if rank, ok := hits[id]; ok {
+				ranks[id] += rank
+			} else {
+				delete(ranks, id)
+			}
+		}
+	}
+	return ranks
+}
+
+func (x *searchIndex) phrase(words []string) map[keyID]float64 {
+	hits := make(map[keyID]float64)
+	for id, starts := range x.postings[words[0]] {
+		for _, start := range starts {
+			if !x.follows(id, words[1:], start) {
+				continue
+			}
+			if start < x.titles[id] {
+				hits[id] += ads.TitleWeight
+			} else {
+				hits[id] += ads.TextWeight
+			}
+		}
+	}
+	return hits
+}
+
+// follows reports whether words come in the ad right after position start.
+func (x *searchIndex) follows(id keyID, words []string, start int) bool {
+	for i, word := range words {
+		found := false
+		for _, pos := range x.postings[word][id] {
+			if pos == start+i+1 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
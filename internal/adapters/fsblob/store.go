@@ -0,0 +1,115 @@
+// Package fsblob keeps blobs as files in a local directory and serves them
+// over HTTP.
+package fsblob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"ads/internal/ads"
+)
+
+type Store struct {
+	root    string
+	baseURL string
+}
+
+// New keeps blobs under root. Their URLs start with baseURL, where Handler
+// is to be mounted.
+func New(root string, baseURL string) (*Store, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	return &Store{root: root, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+var _ ads.BlobStore = (*Store)(nil)
+
+func (s *Store) Put(ctx context.Context, key string, contentType string, r io.Reader, size int64) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	// the blob appears under its name only when it is complete
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, io.LimitReader(r, size))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written != size {
+		return fmt.Errorf("blob %s is %d bytes, not %d", key, written, size)
+	}
+
+	return os.Rename(tmp.Name(), name)
+}
+
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(name)
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *Store) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// Handler serves the blobs at the path of the base URL. Directories are not
+// listed.
+func (s *Store) Handler() http.Handler {
+	prefix := s.baseURL
+	if u, err := url.Parse(s.baseURL); err == nil {
+		prefix = u.Path
+	}
+	files := http.FileServer(http.Dir(s.root))
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/") {
+			http.NotFound(w, r)
+			return
+		}
+		files.ServeHTTP(w, r)
+	}))
+}
+
+// path maps a key to a file under the root, refusing keys that climb out of
+// it.
+func (s *Store) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if clean == "/" || clean[1:] != key {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,412 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"ads/internal/ads"
+)
+
+const adColumns = "id, title, text, author_id, category_id, price, currency, lat, lon, city, status, rejection_reason, create_date, update_date, expires_at, expiry_notified, screening_score, screening_flags, version, held"
+
+var (
+	errNoSuchAd   = fmt.Errorf("is no such ad")
+	errNotDeleted = fmt.Errorf("didn`t delete")
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+type AdPostgres struct {
+	db *sqlx.DB
+}
+
+func (r *AdPostgres) Add(ctx context.Context, ad *ads.Ad) (int64, error) {
+	query := fmt.Sprintf("INSERT INTO %s (title, text, author_id, category_id, price, currency, lat, lon, city, status, create_date, update_date, screening_score, screening_flags, version) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING id", adsTable)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	row := tx.QueryRowContext(ctx, query, ad.Title, ad.Text, ad.AuthorID, ad.CategoryID, ad.Price, ad.Currency, ad.Lat, ad.Lon, ad.City, ad.Status, ad.CreateDate, ad.UpdateDate, ad.Screening.Score, ad.Screening.Flags, ad.Version)
+	if err := row.Scan(&ad.ID); err != nil {
+		return 0, err
+	}
+	if err := insertRevision(ctx, tx, ads.NewRevision(ad, ad.AuthorID), nil, ad.CreateDate); err != nil {
+		return 0, err
+	}
+
+	return ad.ID, tx.Commit()
+}
+
+// ChangeStatus checks the status in the UPDATE itself, so that of two
+// concurrent changes from the same status only one is made.
+func (r *AdPostgres) ChangeStatus(ctx context.Context, adID int64, from ads.Status, to ads.Status, reason string, expiresAt *time.Time, held bool) (*ads.Ad, error) {
+	query := fmt.Sprintf("UPDATE %s SET status = $1, rejection_reason = $2, expires_at = $3, expiry_notified = false, held = $4, update_date = $5, version = version + 1 WHERE id = $6 AND status = $7 RETURNING %s", adsTable, adColumns)
+
+	ad, err := r.getOne(ctx, query, to, reason, expiresAt, held, time.Now().UTC(), adID, from)
+	if errors.Is(err, errNoSuchAd) {
+		if _, err := r.GetAd(ctx, adID); err == nil {
+			return nil, ads.ErrStatusChanged
+		}
+	}
+	return ad, err
+}
+
+// Update locks the ad, so that concurrent updates check the version and
+// number and diff their revisions one after another.
+func (r *AdPostgres) Update(ctx context.Context, edit *ads.Revision, screening ads.Screening, version *int64, review bool) (*ads.Ad, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var before ads.Ad
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1 FOR UPDATE", adColumns, adsTable)
+	if err := tx.GetContext(ctx, &before, query, edit.AdID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNoSuchAd
+		}
+		return nil, err
+	}
+	if version != nil && before.Version != *version {
+		return nil, ads.ErrVersionMismatch
+	}
+
+	// the row is locked, so the status read is the one changed
+	status, expiresAt, notified := before.Status, before.ExpiresAt, before.ExpiryNotified
+	if review && before.Published() {
+		status, expiresAt, notified = ads.StatusPendingReview, nil, false
+	}
+
+	var ad ads.Ad
+	now := time.Now().UTC()
+	query = fmt.Sprintf("UPDATE %s SET title = $1, text = $2, price = $3, currency = $4, lat = $5, lon = $6, city = $7, screening_score = $8, screening_flags = $9, status = $10, expires_at = $11, expiry_notified = $12, update_date = $13, version = version + 1 WHERE id = $14 RETURNING %s", adsTable, adColumns)
+	if err := tx.GetContext(ctx, &ad, query, edit.Title, edit.Text, edit.Price, edit.Currency, edit.Lat, edit.Lon, edit.City, screening.Score, screening.Flags, status, expiresAt, notified, now, edit.AdID); err != nil {
+		return nil, err
+	}
+	if err := insertRevision(ctx, tx, edit, ads.NewRevision(&before, 0), now); err != nil {
+		return nil, err
+	}
+	if err := attachImages(ctx, tx, []*ads.Ad{&ad}); err != nil {
+		return nil, err
+	}
+
+	return &ad, tx.Commit()
+}
+
+func (r *AdPostgres) GetAd(ctx context.Context, adID int64) (*ads.Ad, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", adColumns, adsTable)
+
+	return r.getOne(ctx, query, adID)
+}
+
+// Find reads the page and, when asked for, the facets in one read-only
+// transaction, so the counts agree with the page. They are two statements
+// in the snapshot of the transaction, and the filter is matched twice.
+func (r *AdPostgres) Find(ctx context.Context, q ads.Query) (*ads.List, error) {
+	where, extra, args := queryWhere(q)
+	if !q.Facets {
+		return r.getPage(ctx, r.db, q.Page, extra, where, args...)
+	}
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	list, err := r.getPage(ctx, tx, q.Page, extra, where, args...)
+	if err != nil {
+		return nil, err
+	}
+	if list.Facets, err = getFacets(ctx, tx, where, args...); err != nil {
+		return nil, err
+	}
+
+	return list, tx.Commit()
+}
+
+// computed are the expressions of the columns a query adds to the ads it
+// finds, empty when it adds none.
+type computed struct {
+	rank     string // ranks the ads found by a search
+	distance string // km to the origin of the geo filter
+}
+
+// queryWhere writes the filters of q as a condition on placeholders for
+// args, and the expressions of the columns it computes.
+func queryWhere(q ads.Query) (string, computed, []any) {
+	where := []string{"true"}
+	var args []any
+	arg := func(value any) int {
+		args = append(args, value)
+		return len(args)
+	}
+
+	if q.AuthorID != nil {
+		where = append(where, fmt.Sprintf("author_id = $%d", arg(*q.AuthorID)))
+	}
+	if q.OtherAuthorID != nil {
+		where = append(where, fmt.Sprintf("author_id <> $%d", arg(*q.OtherAuthorID)))
+	}
+	if q.Published != nil {
+		where = append(where, fmt.Sprintf("(status = 'published') = $%d", arg(*q.Published)))
+	}
+	if q.Status != nil {
+		where = append(where, fmt.Sprintf("status = $%d", arg(*q.Status)))
+	}
+	if q.CreatedFrom != nil {
+		where = append(where, fmt.Sprintf("create_date >= $%d", arg(*q.CreatedFrom)))
+	}
+	if q.CreatedTo != nil {
+		where = append(where, fmt.Sprintf("create_date < $%d", arg(*q.CreatedTo)))
+	}
+	if q.UpdatedFrom != nil {
+		where = append(where, fmt.Sprintf("update_date >= $%d", arg(*q.UpdatedFrom)))
+	}
+	if q.UpdatedTo != nil {
+		where = append(where, fmt.Sprintf("update_date < $%d", arg(*q.UpdatedTo)))
+	}
+	if q.Text != "" {
+		where = append(where, fmt.Sprintf(`(title ILIKE $%[1]d ESCAPE '\' OR text ILIKE $%[1]d ESCAPE '\')`, arg("%"+likeEscaper.Replace(q.Text)+"%")))
+	}
+	var extra computed
+	if q.Search != "" {
+		n := arg(tsQuery(ads.ParseSearch(q.Search)))
+		where = append(where, fmt.Sprintf("search_vector @@ to_tsquery('simple', $%d)", n))
+		extra.rank = fmt.Sprintf("ts_rank(search_vector, to_tsquery('simple', $%d))::float8", n)
+	}
+	if q.CategoryID != nil {
+		where = append(where, fmt.Sprintf(`category_id IN (
+    WITH RECURSIVE tree AS (
+        SELECT id FROM %[1]s WHERE id = $%[2]d
+        UNION ALL
+        SELECT c.id FROM %[1]s c JOIN tree ON c.parent_id = tree.id
+    )
+    SELECT id FROM tree
+)`, categoriesTable, arg(*q.CategoryID)))
+	}
+	if q.Price.MinPrice != nil {
+		where = append(where, fmt.Sprintf("price >= $%d", arg(*q.Price.MinPrice)))
+	}
+	if q.Price.MaxPrice != nil {
+		where = append(where, fmt.Sprintf("price <= $%d", arg(*q.Price.MaxPrice)))
+	}
+	if q.Price.Currency != "" {
+		where = append(where, fmt.Sprintf("currency = $%d", arg(q.Price.Currency)))
+	}
+
+	if origin := q.Geo.Origin(); origin != nil {
+		where = append(where, "lat IS NOT NULL")
+		point := fmt.Sprintf("ll_to_earth($%d, $%d)", arg(origin.Lat), arg(origin.Lon))
+		extra.distance = fmt.Sprintf("earth_distance(%s, ll_to_earth(lat, lon)) / 1000", point)
+		if q.Geo.Near != nil && q.Geo.RadiusKm > 0 {
+			// the cube of earth_box is found by the GiST index and may
+			// hold points a bit farther than the radius
+			n := arg(q.Geo.RadiusKm * 1000)
+			where = append(where, fmt.Sprintf("earth_box(%[1]s, $%[2]d) @> ll_to_earth(lat, lon) AND earth_distance(%[1]s, ll_to_earth(lat, lon)) <= $%[2]d", point, n))
+		}
+	}
+	if b := q.Geo.Box; b != nil {
+		where = append(where, fmt.Sprintf("lat BETWEEN $%d AND $%d", arg(b.MinLat), arg(b.MaxLat)))
+		if b.Wraps() {
+			where = append(where, fmt.Sprintf("(lon >= $%d OR lon <= $%d)", arg(b.MinLon), arg(b.MaxLon)))
+		} else {
+			where = append(where, fmt.Sprintf("lon BETWEEN $%d AND $%d", arg(b.MinLon), arg(b.MaxLon)))
+		}
+	}
+	if q.City != "" {
+		where = append(where, fmt.Sprintf("lower(city) = lower($%d)", arg(q.City)))
+	}
+	if q.ActiveAt != nil {
+		where = append(where, fmt.Sprintf("(expires_at IS NULL OR expires_at > $%d)", arg(*q.ActiveAt)))
+	}
+
+	return strings.Join(where, " AND "), extra, args
+}
+
+// facetRow is a row of a grouping set: only the column grouped by is set.
+type facetRow struct {
+	CategoryID *int64  `db:"category_id"`
+	AuthorID   *int64  `db:"author_id"`
+	Bucket     *int    `db:"bucket"`
+	Month      *string `db:"month"`
+	Count      int64   `db:"count"`
+}
+
+// getFacets counts the ads matching where by category, author, price bucket
+// and month of creation in one pass over them. The buckets compare amounts,
+// so without a currency filter they mix the prices of every currency.
+func getFacets(ctx context.Context, db sqlx.QueryerContext, where string, args ...any) (*ads.Facets, error) {
+	args = append(args, pq.Array(ads.PriceBucketBounds))
+	query := fmt.Sprintf(`SELECT category_id, author_id, bucket, month, count(*) AS count FROM (
+    SELECT category_id, author_id, width_bucket(price, $%d::bigint[]) AS bucket,
+        to_char(create_date AT TIME ZONE 'UTC', 'YYYY-MM') AS month
+    FROM %s WHERE %s
+) matched
+GROUP BY GROUPING SETS ((category_id), (author_id), (bucket), (month))`, len(args), adsTable, where)
+
+	var rows []facetRow
+	if err := sqlx.SelectContext(ctx, db, &rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	counts := ads.NewFacetCounts()
+	for _, row := range rows {
+		switch {
+		case row.CategoryID != nil:
+			counts.Categories[*row.CategoryID] = row.Count
+		case row.AuthorID != nil:
+			counts.Authors[*row.AuthorID] = row.Count
+		case row.Bucket != nil:
+			counts.Prices[*row.Bucket] = row.Count
+		case row.Month != nil:
+			counts.Months[*row.Month] = row.Count
+		}
+	}
+
+	return counts.Facets(), nil
+}
+
+// tsQuery writes phrases as a tsquery. The words are letters and digits
+// only, so they need no quoting.
+func tsQuery(phrases [][]string) string {
+	parts := make([]string, 0, len(phrases))
+	for _, phrase := range phrases {
+		parts = append(parts, "("+strings.Join(phrase, " <-> ")+")")
+	}
+	return strings.Join(parts, " & ")
+}
+
+// Suggest ranks titles by pg_trgm similarity. Corrections are found with the
+// % operator, which uses the trigram index and the default threshold of
+// ads.SimilarityThreshold.
+func (r *AdPostgres) Suggest(ctx context.Context, query string, limit int) (*ads.Suggestions, error) {
+	const suggestQuery = `SELECT min(title) AS title, similarity(lower(title), $1) AS score
+FROM %s WHERE status = 'published' AND %s
+GROUP BY lower(title) ORDER BY score DESC, title LIMIT $3`
+
+	prefix := strings.ToLower(likeEscaper.Replace(query)) + "%"
+	result := &ads.Suggestions{Completions: []ads.Suggestion{}, Corrections: []ads.Suggestion{}}
+
+	completions := fmt.Sprintf(suggestQuery, adsTable, `lower(title) LIKE $2 ESCAPE '\'`)
+	if err := r.db.SelectContext(ctx, &result.Completions, completions, strings.ToLower(query), prefix, limit); err != nil {
+		return nil, err
+	}
+
+	corrections := fmt.Sprintf(suggestQuery, adsTable, `lower(title) % $1 AND lower(title) NOT LIKE $2 ESCAPE '\'`)
+	if err := r.db.SelectContext(ctx, &result.Corrections, corrections, strings.ToLower(query), prefix, limit); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *AdPostgres) DeleteAd(ctx context.Context, authorID int64, adID int64) (*ads.Ad, error) {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND author_id = $2 RETURNING %s", adsTable, adColumns)
+
+	ad, err := r.getOne(ctx, query, adID, authorID)
+	if errors.Is(err, errNoSuchAd) {
+		return nil, errNotDeleted
+	}
+
+	return ad, err
+}
+
+func (r *AdPostgres) getOne(ctx context.Context, query string, args ...any) (*ads.Ad, error) {
+	var ad ads.Ad
+	if err := r.db.GetContext(ctx, &ad, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNoSuchAd
+		}
+		return nil, err
+	}
+	if err := attachImages(ctx, r.db, []*ads.Ad{&ad}); err != nil {
+		return nil, err
+	}
+
+	return &ad, nil
+}
+
+var sortColumns = map[string]string{
+	"created": "create_date",
+	"updated": "update_date",
+	"title":   "title",
+	"price":   "price",
+}
+
+// getPage selects a page of ads matching where, which uses placeholders for
+// args. Paging is by keyset, (sort column, id) after the cursor, so writes
+// between requests do not shift later pages. extra are the columns the
+// query computes.
+func (r *AdPostgres) getPage(ctx context.Context, db sqlx.QueryerContext, page ads.Page, extra computed, where string, args ...any) (*ads.List, error) {
+	cursor, err := page.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := adColumns
+	if extra.rank != "" {
+		columns += ", " + extra.rank + " AS rank"
+	}
+	if extra.distance != "" {
+		columns += ", " + extra.distance + " AS distance"
+	}
+
+	column := sortColumns[page.Sort.Field()]
+	desc := page.Sort.Desc()
+	switch page.Sort {
+	case ads.SortRelevance:
+		// the best ranked ads go first
+		column, desc = extra.rank, true
+	case ads.SortDistance:
+		// the nearest ads go first
+		column, desc = extra.distance, false
+	}
+	order, cmp := "id", ">"
+	if column != "" {
+		order = column + ", id"
+		if desc {
+			order, cmp = column+" DESC, id", "<"
+		}
+	}
+
+	where = "(" + where + ")"
+	if cursor != nil {
+		args = append(args, cursor.ID)
+		id := len(args)
+		if column == "" {
+			where += fmt.Sprintf(" AND id > $%d", id)
+		} else {
+			args = append(args, cursor.Value())
+			where += fmt.Sprintf(" AND (%[1]s %[2]s $%[3]d OR (%[1]s = $%[3]d AND id > $%[4]d))", column, cmp, len(args), id)
+		}
+	}
+
+	args = append(args, page.Size()+1)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY %s LIMIT $%d", columns, adsTable, where, order, len(args))
+
+	list := []*ads.Ad{}
+	if err := sqlx.SelectContext(ctx, db, &list, query, args...); err != nil {
+		return nil, err
+	}
+	if err := attachImages(ctx, db, list); err != nil {
+		return nil, err
+	}
+
+	return ads.PageOf(list, page), nil
+}
+
+func NewAdPostgres(db *sqlx.DB) *AdPostgres {
+	return &AdPostgres{db: db}
+}
@@ -0,0 +1,90 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"ads/internal/ads"
+)
+
+const categoryColumns = "id, parent_id, name, slug"
+
+var errNoSuchCategory = fmt.Errorf("is no such category")
+
+func (r *AdPostgres) AddCategory(ctx context.Context, category *ads.Category) (int64, error) {
+	query := fmt.Sprintf("INSERT INTO %s (parent_id, name, slug) values ($1, $2, $3) RETURNING id", categoriesTable)
+
+	row := r.db.QueryRowContext(ctx, query, category.ParentID, category.Name, category.Slug)
+	if err := row.Scan(&category.ID); err != nil {
+		return 0, err
+	}
+
+	return category.ID, nil
+}
+
+func (r *AdPostgres) UpdateCategory(ctx context.Context, category *ads.Category) (*ads.Category, error) {
+	query := fmt.Sprintf("UPDATE %s SET parent_id = $1, name = $2, slug = $3 WHERE id = $4 RETURNING %s", categoriesTable, categoryColumns)
+
+	return r.getCategory(ctx, query, category.ParentID, category.Name, category.Slug, category.ID)
+}
+
+func (r *AdPostgres) GetCategory(ctx context.Context, categoryID int64) (*ads.Category, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", categoryColumns, categoriesTable)
+
+	return r.getCategory(ctx, query, categoryID)
+}
+
+func (r *AdPostgres) GetCategoryBySlug(ctx context.Context, slug string) (*ads.Category, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE slug = $1", categoryColumns, categoriesTable)
+
+	return r.getCategory(ctx, query, slug)
+}
+
+func (r *AdPostgres) ListCategories(ctx context.Context) ([]*ads.Category, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s ORDER BY id", categoryColumns, categoriesTable)
+
+	var result []*ads.Category
+	if err := r.db.SelectContext(ctx, &result, query); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *AdPostgres) DeleteCategory(ctx context.Context, categoryID int64) error {
+	query := fmt.Sprintf(`DELETE FROM %[1]s WHERE id = $1
+    AND NOT EXISTS (SELECT 1 FROM %[1]s WHERE parent_id = $1)
+    AND NOT EXISTS (SELECT 1 FROM %[2]s WHERE category_id = $1)`, categoriesTable, adsTable)
+
+	res, err := r.db.ExecContext(ctx, query, categoryID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		if _, err := r.GetCategory(ctx, categoryID); err != nil {
+			return err
+		}
+		return ads.ErrCategoryNotEmpty
+	}
+
+	return nil
+}
+
+func (r *AdPostgres) getCategory(ctx context.Context, query string, args ...any) (*ads.Category, error) {
+	var c ads.Category
+	if err := r.db.GetContext(ctx, &c, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNoSuchCategory
+		}
+		return nil, err
+	}
+
+	return &c, nil
+}
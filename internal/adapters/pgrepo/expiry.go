@@ -0,0 +1,50 @@
+package pgrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ads/internal/ads"
+)
+
+func (r *AdPostgres) Renew(ctx context.Context, adID int64, from ads.Status, expiresAt time.Time) (*ads.Ad, error) {
+	query := fmt.Sprintf("UPDATE %s SET status = 'published', expires_at = $1, expiry_notified = false, update_date = $2, version = version + 1 WHERE id = $3 AND status = $4 RETURNING %s", adsTable, adColumns)
+
+	ad, err := r.getOne(ctx, query, expiresAt, time.Now().UTC(), adID, from)
+	if errors.Is(err, errNoSuchAd) {
+		if _, err := r.GetAd(ctx, adID); err == nil {
+			return nil, ads.ErrStatusChanged
+		}
+	}
+	return ad, err
+}
+
+func (r *AdPostgres) ExpireAds(ctx context.Context, now time.Time) ([]*ads.Ad, error) {
+	query := fmt.Sprintf("UPDATE %s SET status = 'archived', update_date = $2, version = version + 1 WHERE status = 'published' AND expires_at <= $1 RETURNING %s", adsTable, adColumns)
+
+	return r.getChanged(ctx, query, now, time.Now().UTC())
+}
+
+// ClaimExpiring marks the ads in the same statement it finds them in, so
+// concurrent sweepers never claim an ad twice.
+func (r *AdPostgres) ClaimExpiring(ctx context.Context, before time.Time) ([]*ads.Ad, error) {
+	query := fmt.Sprintf("UPDATE %s SET expiry_notified = true WHERE status = 'published' AND NOT expiry_notified AND expires_at <= $1 RETURNING %s", adsTable, adColumns)
+
+	return r.getChanged(ctx, query, before)
+}
+
+// getChanged runs an UPDATE ... RETURNING and orders the ads it changed by
+// id.
+func (r *AdPostgres) getChanged(ctx context.Context, query string, args ...any) ([]*ads.Ad, error) {
+	list := []*ads.Ad{}
+	if err := r.db.SelectContext(ctx, &list, query, args...); err != nil {
+		return nil, err
+	}
+	if err := attachImages(ctx, r.db, list); err != nil {
+		return nil, err
+	}
+	ads.SortAds(list, "")
+	return list, nil
+}
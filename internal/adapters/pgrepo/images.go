@@ -0,0 +1,199 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+
+	"ads/internal/ads"
+)
+
+const imageColumns = "id, ad_id, key, url, content_type, size, position, create_date"
+const renditionColumns = "id, image_id, name, key, url, content_type, width, height, size"
+
+var errNoSuchImage = fmt.Errorf("is no such image")
+
+func (r *AdPostgres) AddImage(ctx context.Context, image *ads.Image) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf("INSERT INTO %s (ad_id, key, url, content_type, size, position, create_date) values ($1, $2, $3, $4, $5, $6, $7) RETURNING id", imagesTable)
+
+	row := tx.QueryRowContext(ctx, query, image.AdID, image.Key, image.URL, image.ContentType, image.Size, image.Position, image.CreateDate)
+	if err := row.Scan(&image.ID); err != nil {
+		return 0, err
+	}
+	if err := bumpVersion(ctx, tx, image.AdID); err != nil {
+		return 0, err
+	}
+
+	return image.ID, tx.Commit()
+}
+
+func (r *AdPostgres) DeleteImage(ctx context.Context, adID int64, imageID int64) (*ads.Image, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var image ads.Image
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1 AND ad_id = $2 RETURNING %s", imagesTable, imageColumns)
+	if err := tx.GetContext(ctx, &image, query, imageID, adID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNoSuchImage
+		}
+		return nil, err
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET position = position - 1 WHERE ad_id = $1 AND position > $2", imagesTable)
+	if _, err := tx.ExecContext(ctx, query, adID, image.Position); err != nil {
+		return nil, err
+	}
+	if err := bumpVersion(ctx, tx, adID); err != nil {
+		return nil, err
+	}
+
+	return &image, tx.Commit()
+}
+
+func (r *AdPostgres) ReorderImages(ctx context.Context, adID int64, imageIDs []int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// the positions are the indexes of the ids, from 0
+	query := fmt.Sprintf(`UPDATE %s i SET position = o.position - 1
+FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, position)
+WHERE i.id = o.id AND i.ad_id = $1`, imagesTable)
+	result, err := tx.ExecContext(ctx, query, adID, pq.Array(imageIDs))
+	if err != nil {
+		return err
+	}
+
+	var total int
+	query = fmt.Sprintf("SELECT count(*) FROM %s WHERE ad_id = $1", imagesTable)
+	if err := tx.GetContext(ctx, &total, query, adID); err != nil {
+		return err
+	}
+	if updated, _ := result.RowsAffected(); int(updated) != len(imageIDs) || total != len(imageIDs) {
+		return fmt.Errorf("not every image is ordered")
+	}
+	if err := bumpVersion(ctx, tx, adID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AdPostgres) AddRendition(ctx context.Context, adID int64, rendition *ads.Rendition) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`INSERT INTO %s (image_id, name, key, url, content_type, width, height, size)
+SELECT id, $3, $4, $5, $6, $7, $8, $9 FROM %s WHERE id = $1 AND ad_id = $2
+RETURNING id`, renditionsTable, imagesTable)
+
+	row := tx.QueryRowContext(ctx, query, rendition.ImageID, adID, rendition.Name, rendition.Key, rendition.URL,
+		rendition.ContentType, rendition.Width, rendition.Height, rendition.Size)
+	if err := row.Scan(&rendition.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, errNoSuchImage
+		}
+		return 0, err
+	}
+	if err := bumpVersion(ctx, tx, adID); err != nil {
+		return 0, err
+	}
+
+	return rendition.ID, tx.Commit()
+}
+
+func (r *AdPostgres) ImagesLackingRenditions(ctx context.Context, names []string, before time.Time) ([]*ads.Image, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s i
+WHERE create_date < $2 AND EXISTS (
+    SELECT 1 FROM unnest($1::text[]) AS n(name)
+    WHERE NOT EXISTS (SELECT 1 FROM %s r WHERE r.image_id = i.id AND r.name = n.name)
+)
+ORDER BY id`, imageColumns, imagesTable, renditionsTable)
+
+	var images []*ads.Image
+	if err := r.db.SelectContext(ctx, &images, query, pq.Array(names), before); err != nil {
+		return nil, err
+	}
+	return images, attachRenditions(ctx, r.db, images)
+}
+
+// bumpVersion counts a change of the images of an ad as a new version of
+// the ad.
+func bumpVersion(ctx context.Context, tx *sqlx.Tx, adID int64) error {
+	query := fmt.Sprintf("UPDATE %s SET version = version + 1 WHERE id = $1", adsTable)
+	_, err := tx.ExecContext(ctx, query, adID)
+	return err
+}
+
+// attachImages reads the images of list, with their renditions, into their
+// Images.
+func attachImages(ctx context.Context, db sqlx.QueryerContext, list []*ads.Ad) error {
+	if len(list) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*ads.Ad, len(list))
+	ids := make([]int64, 0, len(list))
+	for _, ad := range list {
+		byID[ad.ID] = ad
+		ids = append(ids, ad.ID)
+	}
+
+	var images []*ads.Image
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE ad_id = ANY($1) ORDER BY ad_id, position", imageColumns, imagesTable)
+	if err := sqlx.SelectContext(ctx, db, &images, query, pq.Array(ids)); err != nil {
+		return err
+	}
+	for _, image := range images {
+		ad := byID[image.AdID]
+		ad.Images = append(ad.Images, image)
+	}
+
+	return attachRenditions(ctx, db, images)
+}
+
+// attachRenditions reads the renditions of images into their Renditions.
+func attachRenditions(ctx context.Context, db sqlx.QueryerContext, images []*ads.Image) error {
+	if len(images) == 0 {
+		return nil
+	}
+
+	imageByID := make(map[int64]*ads.Image, len(images))
+	imageIDs := make([]int64, 0, len(images))
+	for _, image := range images {
+		imageByID[image.ID] = image
+		imageIDs = append(imageIDs, image.ID)
+	}
+
+	var renditions []*ads.Rendition
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE image_id = ANY($1) ORDER BY image_id, id", renditionColumns, renditionsTable)
+	if err := sqlx.SelectContext(ctx, db, &renditions, query, pq.Array(imageIDs)); err != nil {
+		return err
+	}
+	for _, rendition := range renditions {
+		image := imageByID[rendition.ImageID]
+		image.Renditions = append(image.Renditions, rendition)
+	}
+
+	return nil
+}
@@ -0,0 +1,280 @@
+package pgrepo
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	migrationsTable = "schema_migrations"
+	// migrationLockID is the pg_advisory_lock key shared by every replica,
+	// so only one of them applies migrations at a time.
+	migrationLockID = 4_207_316_155
+)
+
+var ErrSchemaTooNew = fmt.Errorf("database schema is newer than the binary")
+var ErrNoMigration = fmt.Errorf("no migration to revert")
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+	// Unknown is set for versions recorded in the database that this
+	// binary has no files for.
+	Unknown bool
+}
+
+type appliedMigration struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []Migration
+}
+
+// Migrations returns the migrations read from the source, ordered by version.
+func (m *Migrator) Migrations() []Migration {
+	return m.migrations
+}
+
+// Up applies every pending migration and returns how many were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		versions, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.checkVersions(versions); err != nil {
+			return err
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := versions[mig.Version]; ok {
+				continue
+			}
+			insert := fmt.Sprintf("INSERT INTO %s (version, name, applied_at) values ($1, $2, $3)", migrationsTable)
+			if err := m.exec(ctx, conn, mig.Up, insert, mig.Version, mig.Name, time.Now().UTC()); err != nil {
+				return fmt.Errorf("migration %06d_%s: %w", mig.Version, mig.Name, err)
+			}
+			applied++
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the latest applied migration and returns its version.
+func (m *Migrator) Down(ctx context.Context) (int64, error) {
+	var version int64
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		versions, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if err := m.checkVersions(versions); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := versions[mig.Version]; !ok {
+				continue
+			}
+			if mig.Down == "" {
+				return fmt.Errorf("migration %06d_%s has no down file", mig.Version, mig.Name)
+			}
+			remove := fmt.Sprintf("DELETE FROM %s WHERE version = $1", migrationsTable)
+			if err := m.exec(ctx, conn, mig.Down, remove, mig.Version); err != nil {
+				return fmt.Errorf("migration %06d_%s: %w", mig.Version, mig.Name, err)
+			}
+			version = mig.Version
+			return nil
+		}
+		return ErrNoMigration
+	})
+
+	return version, err
+}
+
+// Status lists the known migrations together with what the database has applied.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var result []MigrationStatus
+	err := m.withLock(ctx, func(conn *sqlx.Conn) error {
+		versions, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		known := make(map[int64]bool, len(m.migrations))
+		for _, mig := range m.migrations {
+			known[mig.Version] = true
+			st := MigrationStatus{Version: mig.Version, Name: mig.Name}
+			if a, ok := versions[mig.Version]; ok {
+				st.Applied = true
+				st.AppliedAt = a.AppliedAt
+			}
+			result = append(result, st)
+		}
+		for _, a := range versions {
+			if !known[a.Version] {
+				result = append(result, MigrationStatus{Version: a.Version, Name: a.Name, Applied: true, AppliedAt: a.AppliedAt, Unknown: true})
+			}
+		}
+		sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+		return nil
+	})
+
+	return result, err
+}
+
+// checkVersions refuses to work with a database migrated by a newer binary.
+func (m *Migrator) checkVersions(versions map[int64]appliedMigration) error {
+	var latest int64
+	if len(m.migrations) > 0 {
+		latest = m.migrations[len(m.migrations)-1].Version
+	}
+	for v := range versions {
+		if v > latest {
+			return fmt.Errorf("%w: database at version %d, binary knows up to %d", ErrSchemaTooNew, v, latest)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context, conn *sqlx.Conn) (map[int64]appliedMigration, error) {
+	query := fmt.Sprintf("SELECT version, name, applied_at FROM %s", migrationsTable)
+
+	var rows []appliedMigration
+	if err := conn.SelectContext(ctx, &rows, query); err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]appliedMigration, len(rows))
+	for _, r := range rows {
+		result[r.Version] = r
+	}
+	return result, nil
+}
+
+// exec runs a migration body and its bookkeeping statement in one transaction.
+func (m *Migrator) exec(ctx context.Context, conn *sqlx.Conn, body string, bookkeeping string, args ...any) error {
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, body); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, bookkeeping, args...); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// withLock holds the migration advisory lock on a dedicated connection while fn runs.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockID)
+	}()
+
+	create := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s
+(
+    version bigint not null primary key,
+    name varchar(255) not null,
+    applied_at timestamptz not null
+)`, migrationsTable)
+	if _, err := conn.ExecContext(ctx, create); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+func readMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, e := range entries {
+		match := migrationFile.FindStringSubmatch(e.Name())
+		if e.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", e.Name(), err)
+		}
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = mig
+		} else if mig.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by %q and %q", version, mig.Name, match[2])
+		}
+		if match[3] == "up" {
+			mig.Up = string(body)
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("migration %06d_%s has no up file", mig.Version, mig.Name)
+		}
+		result = append(result, *mig)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+
+	return result, nil
+}
+
+func NewMigrator(db *sqlx.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := readMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
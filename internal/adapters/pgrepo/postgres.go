@@ -8,7 +8,14 @@ import (
 )
 
 const (
-	usersTable = "users"
+	usersTable      = "users"
+	adsTable        = "ads"
+	sessionsTable   = "sessions"
+	categoriesTable = "categories"
+	imagesTable     = "ad_images"
+	renditionsTable = "ad_image_renditions"
+	reportsTable    = "ad_reports"
+	revisionsTable  = "ad_revisions"
 )
 
 type Config struct {
@@ -1,6 +1,9 @@
 package pgrepo
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/jmoiron/sqlx"
@@ -8,15 +11,21 @@ import (
 	"ads/internal/user"
 )
 
-type AuthPostgres struct {
+const userColumns = "id, name, email, activate, coalesce(username, '') AS username, coalesce(password_hash, '') AS password_hash, role"
+
+var errNoSuchUser = fmt.Errorf("not found user in db")
+
+// UserPostgres keeps profiles created through /user and accounts created
+// through /sign-up in the same users table.
+type UserPostgres struct {
 	db *sqlx.DB
 }
 
-func (r *AuthPostgres) CreateUserDb(user user.UserDb) (int, error) {
+func (r *UserPostgres) CreateUserDb(user user.User) (int, error) {
 	var id int
-	query := fmt.Sprintf("INSERT INTO %s (name, username, password_hash) values ($1, $2, $3) RETURNING id", usersTable)
+	query := fmt.Sprintf("INSERT INTO %s (name, username, password_hash, email, role) values ($1, $2, $3, $4, $5) RETURNING id", usersTable)
 
-	row := r.db.QueryRow(query, user.Name, user.Username, user.Password)
+	row := r.db.QueryRow(query, user.NickName, user.Username, user.Password, user.Email, user.Role)
 	if err := row.Scan(&id); err != nil {
 		return 0, err
 	}
@@ -24,6 +33,102 @@ func (r *AuthPostgres) CreateUserDb(user user.UserDb) (int, error) {
 	return id, nil
 }
 
-func NewAuthPostgres(db *sqlx.DB) *AuthPostgres {
-	return &AuthPostgres{db: db}
+func (r *UserPostgres) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE username = $1", userColumns, usersTable)
+
+	return r.getOne(ctx, query, username)
+}
+
+func (r *UserPostgres) UpdatePasswordHash(ctx context.Context, userID int64, hash string) error {
+	query := fmt.Sprintf("UPDATE %s SET password_hash = $1 WHERE id = $2", usersTable)
+
+	res, err := r.db.ExecContext(ctx, query, hash, userID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errNoSuchUser
+	}
+
+	return nil
+}
+
+func (r *UserPostgres) SetUserRole(ctx context.Context, userID int64, role user.Role) (*user.User, error) {
+	query := fmt.Sprintf("UPDATE %s SET role = $1 WHERE id = $2 RETURNING %s", usersTable, userColumns)
+
+	return r.getOne(ctx, query, role, userID)
+}
+
+func (r *UserPostgres) AddUser(ctx context.Context, user *user.User) (int64, error) {
+	query := fmt.Sprintf("INSERT INTO %s (name, email, activate, role) values ($1, $2, $3, $4) RETURNING id", usersTable)
+
+	row := r.db.QueryRowContext(ctx, query, user.NickName, user.Email, user.Activate, user.Role)
+	if err := row.Scan(&user.UserID); err != nil {
+		return 0, err
+	}
+
+	return user.UserID, nil
+}
+
+func (r *UserPostgres) UpdateUser(ctx context.Context, nickname string, email string, userID int64, activate bool) (*user.User, error) {
+	query := fmt.Sprintf("UPDATE %s SET name = $1, email = $2, activate = $3 WHERE id = $4 RETURNING %s", usersTable, userColumns)
+
+	return r.getOne(ctx, query, nickname, email, activate, userID)
+}
+
+func (r *UserPostgres) CheckUser(ctx context.Context, user_id int64) bool {
+	var exists bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE id = $1)", usersTable)
+
+	if err := r.db.GetContext(ctx, &exists, query, user_id); err != nil {
+		return false
+	}
+
+	return exists
+}
+
+func (r *UserPostgres) GetUser(ctx context.Context, user_id int64) (*user.User, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", userColumns, usersTable)
+
+	return r.getOne(ctx, query, user_id)
+}
+
+func (r *UserPostgres) DeleteUser(ctx context.Context, user_id int64) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", usersTable)
+
+	res, err := r.db.ExecContext(ctx, query, user_id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return errNoSuchUser
+	}
+
+	return nil
+}
+
+func (r *UserPostgres) getOne(ctx context.Context, query string, args ...any) (*user.User, error) {
+	var u user.User
+	if err := r.db.GetContext(ctx, &u, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNoSuchUser
+		}
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+func NewUserPostgres(db *sqlx.DB) *UserPostgres {
+	return &UserPostgres{db: db}
 }
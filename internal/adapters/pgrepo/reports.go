@@ -0,0 +1,109 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	"ads/internal/ads"
+)
+
+const reportColumns = "id, ad_id, reporter_id, reason, comment, status, create_date, resolved_by, resolve_date"
+
+var errNoSuchReport = fmt.Errorf("is no such report")
+
+// uniqueViolation is the SQLSTATE of a broken unique constraint.
+const uniqueViolation = "23505"
+
+func (r *AdPostgres) AddReport(ctx context.Context, report *ads.Report) (int64, error) {
+	query := fmt.Sprintf("INSERT INTO %s (ad_id, reporter_id, reason, comment, status, create_date) values ($1, $2, $3, $4, $5, $6) RETURNING id", reportsTable)
+
+	row := r.db.QueryRowContext(ctx, query, report.AdID, report.ReporterID, report.Reason, report.Comment, report.Status, report.CreateDate)
+	if err := row.Scan(&report.ID); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return 0, ads.ErrAlreadyReported
+		}
+		return 0, err
+	}
+
+	return report.ID, nil
+}
+
+func (r *AdPostgres) GetReport(ctx context.Context, reportID int64) (*ads.Report, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE id = $1", reportColumns, reportsTable)
+
+	var report ads.Report
+	if err := r.db.GetContext(ctx, &report, query, reportID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNoSuchReport
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *AdPostgres) ListReports(ctx context.Context, q ads.ReportQuery) ([]*ads.Report, error) {
+	where := []string{"true"}
+	var args []any
+	arg := func(value any) int {
+		args = append(args, value)
+		return len(args)
+	}
+
+	if q.AdID != nil {
+		where = append(where, fmt.Sprintf("ad_id = $%d", arg(*q.AdID)))
+	}
+	if q.Status != nil {
+		where = append(where, fmt.Sprintf("status = $%d", arg(*q.Status)))
+	}
+	if q.AfterID != nil {
+		where = append(where, fmt.Sprintf("id > $%d", arg(*q.AfterID)))
+	}
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s ORDER BY id LIMIT $%d", reportColumns, reportsTable, strings.Join(where, " AND "), arg(q.Limit))
+
+	result := []*ads.Report{}
+	if err := r.db.SelectContext(ctx, &result, query, args...); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ResolveReports locks the open reports it resolves, so that a report is
+// resolved once by concurrent moderators.
+func (r *AdPostgres) ResolveReports(ctx context.Context, adID int64, reportID *int64, status ads.ReportStatus, moderatorID int64) ([]*ads.Report, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if reportID != nil {
+		var current ads.ReportStatus
+		query := fmt.Sprintf("SELECT status FROM %s WHERE id = $1 AND ad_id = $2 FOR UPDATE", reportsTable)
+		if err := tx.GetContext(ctx, &current, query, *reportID, adID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errNoSuchReport
+			}
+			return nil, err
+		}
+		if current != ads.ReportOpen {
+			return nil, ads.ErrReportResolved
+		}
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET status = $1, resolved_by = $2, resolve_date = $3 WHERE ad_id = $4 AND status = 'open' AND ($5::bigint IS NULL OR id = $5) RETURNING %s", reportsTable, reportColumns)
+	result := []*ads.Report{}
+	if err := tx.SelectContext(ctx, &result, query, status, moderatorID, time.Now().UTC(), adID, reportID); err != nil {
+		return nil, err
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+
+	return result, tx.Commit()
+}
@@ -0,0 +1,55 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"ads/internal/ads"
+)
+
+const revisionColumns = "id, ad_id, number, editor_id, title, text, price, currency, lat, lon, city, restored_from, diff, create_date"
+
+var errNoSuchRevision = fmt.Errorf("is no such revision")
+
+// insertRevision stores rev as the next revision of its ad, with the diff
+// from before, the content of the ad until now, or none for a new ad. The
+// ad must be locked or new in tx.
+func insertRevision(ctx context.Context, tx *sqlx.Tx, rev *ads.Revision, before *ads.Revision, date time.Time) error {
+	rev.Diff = rev.DiffFrom(before)
+	rev.CreateDate = date
+
+	query := fmt.Sprintf("INSERT INTO %s (ad_id, number, editor_id, title, text, price, currency, lat, lon, city, restored_from, diff, create_date) SELECT $1, coalesce(max(number), 0) + 1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12 FROM %s WHERE ad_id = $1 RETURNING id, number", revisionsTable, revisionsTable)
+	row := tx.QueryRowContext(ctx, query, rev.AdID, rev.EditorID, rev.Title, rev.Text, rev.Price, rev.Currency, rev.Lat, rev.Lon, rev.City, rev.RestoredFrom, rev.Diff, rev.CreateDate)
+	return row.Scan(&rev.ID, &rev.Number)
+}
+
+func (r *AdPostgres) ListRevisions(ctx context.Context, adID int64) ([]*ads.Revision, error) {
+	if _, err := r.GetAd(ctx, adID); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE ad_id = $1 ORDER BY number", revisionColumns, revisionsTable)
+	result := []*ads.Revision{}
+	if err := r.db.SelectContext(ctx, &result, query, adID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *AdPostgres) GetRevision(ctx context.Context, adID int64, number int) (*ads.Revision, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE ad_id = $1 AND number = $2", revisionColumns, revisionsTable)
+
+	var rev ads.Revision
+	if err := r.db.GetContext(ctx, &rev, query, adID, number); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNoSuchRevision
+		}
+		return nil, err
+	}
+	return &rev, nil
+}
@@ -0,0 +1,81 @@
+package pgrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ads/internal/user"
+)
+
+const sessionColumns = "id, user_id, family_id, token_hash, created_at, expires_at, revoked_at"
+
+var errNoSuchSession = fmt.Errorf("not found session in db")
+
+func (r *UserPostgres) CreateSession(ctx context.Context, session *user.Session) (int64, error) {
+	query := fmt.Sprintf("INSERT INTO %s (user_id, family_id, token_hash, created_at, expires_at) values ($1, $2, $3, $4, $5) RETURNING id", sessionsTable)
+
+	row := r.db.QueryRowContext(ctx, query, session.UserID, session.FamilyID, session.TokenHash, session.CreatedAt, session.ExpiresAt)
+	if err := row.Scan(&session.ID); err != nil {
+		return 0, err
+	}
+
+	return session.ID, nil
+}
+
+func (r *UserPostgres) GetSessionByTokenHash(ctx context.Context, tokenHash string) (*user.Session, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE token_hash = $1", sessionColumns, sessionsTable)
+
+	var s user.Session
+	if err := r.db.GetContext(ctx, &s, query, tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errNoSuchSession
+		}
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *UserPostgres) RevokeSession(ctx context.Context, sessionID int64) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL", sessionsTable)
+
+	res, err := r.db.ExecContext(ctx, query, time.Now().UTC(), sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+func (r *UserPostgres) RevokeSessionFamily(ctx context.Context, familyID string) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL", sessionsTable)
+
+	_, err := r.db.ExecContext(ctx, query, time.Now().UTC(), familyID)
+	return err
+}
+
+func (r *UserPostgres) RevokeUserSessions(ctx context.Context, userID int64) error {
+	query := fmt.Sprintf("UPDATE %s SET revoked_at = $1 WHERE user_id = $2 AND revoked_at IS NULL", sessionsTable)
+
+	_, err := r.db.ExecContext(ctx, query, time.Now().UTC(), userID)
+	return err
+}
+
+func (r *UserPostgres) SessionFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	var active bool
+	query := fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE family_id = $1 AND revoked_at IS NULL AND expires_at > $2)", sessionsTable)
+
+	if err := r.db.GetContext(ctx, &active, query, familyID, time.Now().UTC()); err != nil {
+		return false, err
+	}
+
+	return active, nil
+}
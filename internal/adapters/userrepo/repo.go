@@ -15,15 +15,60 @@ type UserRepositoryMap struct {
 	sync.Mutex
 	countUserID int64
 	mapUser map[keyUserId]userStructType
+
+	countSessionID int64
+	sessions []*user.Session
 }
 
-func (ur *UserRepositoryMap) AddUser(ctx context.Context, user *user.User) (int64, error) {
+func (ur *UserRepositoryMap) AddUser(ctx context.Context, u *user.User) (int64, error) {
 	ur.countUserID += 1
-	user.UserID = ur.countUserID
-	ur.mapUser[keyUserId(ur.countUserID)] = user
+	u.UserID = ur.countUserID
+	if u.Role == "" {
+		u.Role = user.RoleUser
+	}
+	ur.mapUser[keyUserId(ur.countUserID)] = u
 	return ur.countUserID, nil
 }
 
+func (ur *UserRepositoryMap) CreateUserDb(user user.User) (int, error) {
+	for _, u := range ur.mapUser {
+		if user.Username != "" && u.Username == user.Username {
+			return 0, fmt.Errorf("username is already taken")
+		}
+	}
+	id, err := ur.AddUser(context.Background(), &user)
+	return int(id), err
+}
+
+func (ur *UserRepositoryMap) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
+	for _, u := range ur.mapUser {
+		if username != "" && u.Username == username {
+			return u, nil
+		}
+	}
+	return nil, fmt.Errorf("not found user in db")
+}
+
+func (ur *UserRepositoryMap) UpdatePasswordHash(ctx context.Context, userID int64, hash string) error {
+	user, ok := ur.mapUser[keyUserId(userID)]
+	if !ok {
+		return fmt.Errorf("not found user in db")
+	}
+	user.Password = hash
+
+	return nil
+}
+
+func (ur *UserRepositoryMap) SetUserRole(ctx context.Context, userID int64, role user.Role) (*user.User, error) {
+	user, ok := ur.mapUser[keyUserId(userID)]
+	if !ok {
+		return nil, fmt.Errorf("not found user in db")
+	}
+	user.Role = role
+
+	return user, nil
+}
+
 func (ur *UserRepositoryMap) UpdateUser(ctx context.Context, nickname string, email string, userId int64, activate bool) (*user.User, error) {
 	user, ok := ur.mapUser[keyUserId(userId)]
 	if !ok {
@@ -72,7 +117,7 @@ func (ur *UserRepositoryMap) DeleteUser(ctx context.Context, user_id int64) (err
 	return nil
 }
 
-func New() user.RepositoryUser {
+func New() *UserRepositoryMap {
 	return &UserRepositoryMap{
 		countUserID: -1,
 		mapUser: make(map[keyUserId]userStructType),
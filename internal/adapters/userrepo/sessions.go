@@ -0,0 +1,84 @@
+package userrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"ads/internal/user"
+)
+
+func (ur *UserRepositoryMap) CreateSession(ctx context.Context, session *user.Session) (int64, error) {
+	ur.Lock()
+	defer ur.Unlock()
+
+	ur.countSessionID += 1
+	s := *session
+	s.ID = ur.countSessionID
+	ur.sessions = append(ur.sessions, &s)
+	session.ID = s.ID
+
+	return s.ID, nil
+}
+
+func (ur *UserRepositoryMap) GetSessionByTokenHash(ctx context.Context, tokenHash string) (*user.Session, error) {
+	ur.Lock()
+	defer ur.Unlock()
+
+	for _, s := range ur.sessions {
+		if s.TokenHash == tokenHash {
+			result := *s
+			return &result, nil
+		}
+	}
+	return nil, fmt.Errorf("not found session in db")
+}
+
+func (ur *UserRepositoryMap) RevokeSession(ctx context.Context, sessionID int64) (bool, error) {
+	ur.Lock()
+	defer ur.Unlock()
+
+	for _, s := range ur.sessions {
+		if s.ID == sessionID && s.RevokedAt == nil {
+			now := time.Now().UTC()
+			s.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (ur *UserRepositoryMap) RevokeSessionFamily(ctx context.Context, familyID string) error {
+	ur.revokeWhere(func(s *user.Session) bool { return s.FamilyID == familyID })
+	return nil
+}
+
+func (ur *UserRepositoryMap) RevokeUserSessions(ctx context.Context, userID int64) error {
+	ur.revokeWhere(func(s *user.Session) bool { return s.UserID == userID })
+	return nil
+}
+
+func (ur *UserRepositoryMap) SessionFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	ur.Lock()
+	defer ur.Unlock()
+
+	now := time.Now()
+	for _, s := range ur.sessions {
+		if s.FamilyID == familyID && s.RevokedAt == nil && s.ExpiresAt.After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (ur *UserRepositoryMap) revokeWhere(match func(s *user.Session) bool) {
+	ur.Lock()
+	defer ur.Unlock()
+
+	now := time.Now().UTC()
+	for _, s := range ur.sessions {
+		if s.RevokedAt == nil && match(s) {
+			s.RevokedAt = &now
+		}
+	}
+}
@@ -1,13 +1,52 @@
 package ads
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// ErrVersionMismatch is returned for an update made to a version of the ad
+// that is no longer current.
+var ErrVersionMismatch = fmt.Errorf("ad version does not match")
 
 type Ad struct {
-	ID         int64
-	Title      string
-	Text       string
-	AuthorID   int64
-	Published  bool
-	CreateDate time.Time
-	UpdateDate time.Time
+	ID         int64  `db:"id"`
+	Title      string `db:"title"`
+	Text       string `db:"text"`
+	AuthorID   int64  `db:"author_id"`
+	CategoryID int64  `db:"category_id"`
+	Price      int64  `db:"price"` // minor units of Currency, e.g. kopecks
+	Currency   string `db:"currency"`
+	Status     Status `db:"status"`
+	// RejectionReason tells the author why a moderator rejected the ad, and
+	// is cleared once it is submitted again.
+	RejectionReason string    `db:"rejection_reason"`
+	CreateDate      time.Time `db:"create_date"`
+	UpdateDate      time.Time `db:"update_date"`
+	// Version is 1 for a new ad and grows by one with every change that
+	// moves UpdateDate and with every change of its images; see
+	// ErrVersionMismatch.
+	Version int64 `db:"version"`
+	// Held is set when a moderator rejects the ad or takes it down, and
+	// cleared once one publishes it: until then the ad goes through review
+	// to be published again, even with review off.
+	Held bool `db:"held"`
+	// ExpiresAt is set while the ad is published and kept once it expires;
+	// the ads already published when expiry came in were given 30 days from
+	// the migration, whatever AD_TTL is.
+	ExpiresAt      *time.Time `db:"expires_at"`
+	ExpiryNotified bool       `db:"expiry_notified"` // the author was warned of ExpiresAt
+	Lat            *float64   `db:"lat"`             // both set or both nil, see Location
+	Lon            *float64   `db:"lon"`
+	City           string     `db:"city"`
+	Rank           float64    `db:"rank"`     // relevance to the search, set in search results only
+	Distance       *float64   `db:"distance"` // km to the origin of the geo filter, set in its results only
+	Screening
+	Images []*Image `db:"-"` // in the order of their positions
+}
+
+// Expired reports whether ad was taken down by its expiry at now: it is
+// past ExpiresAt, whether or not the sweeper has archived it yet.
+func (ad *Ad) Expired(now time.Time) bool {
+	return ad.ExpiresAt != nil && !ad.ExpiresAt.After(now)
 }
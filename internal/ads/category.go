@@ -0,0 +1,41 @@
+package ads
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ErrCategoryNotEmpty is returned by repositories that refuse to delete a
+// category with subcategories or ads.
+var ErrCategoryNotEmpty = fmt.Errorf("category has subcategories or ads")
+
+// Category is a node of the category tree. Root categories have no parent.
+type Category struct {
+	ID       int64  `db:"id"`
+	ParentID *int64 `db:"parent_id"`
+	Name     string `db:"name"`
+	Slug     string `db:"slug"`
+}
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidSlug reports whether slug is lowercase words joined by dashes.
+func ValidSlug(slug string) bool {
+	return len(slug) <= 100 && slugPattern.MatchString(slug)
+}
+
+// Subtree returns id and the ids of all its descendants in categories.
+func Subtree(categories []*Category, id int64) []int64 {
+	children := make(map[int64][]int64)
+	for _, c := range categories {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+
+	result := []int64{id}
+	for i := 0; i < len(result); i++ {
+		result = append(result, children[result[i]]...)
+	}
+	return result
+}
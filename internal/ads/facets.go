@@ -0,0 +1,123 @@
+package ads
+
+import "sort"
+
+// PriceBucketBounds split prices, in minor units, into the buckets of the
+// price facet: below 1000, from 1000 to 5000, and so on up to 50000 and
+// above, in major units. The bounds are the same for every currency.
+var PriceBucketBounds = []int64{100000, 500000, 1000000, 5000000}
+
+// Facets count the ads matching a query, all of them and not only a page,
+// by the values of their fields.
+type Facets struct {
+	Categories []IDCount
+	Authors    []IDCount
+	Prices     []PriceBucket
+	Months     []MonthCount
+}
+
+// IDCount is the number of ads with a category or an author.
+type IDCount struct {
+	ID    int64
+	Count int64
+}
+
+// PriceBucket is the number of ads priced from Min up to, but not
+// including, Max. The last bucket has no Max.
+type PriceBucket struct {
+	Min   int64
+	Max   *int64
+	Count int64
+}
+
+// MonthCount is the number of ads created in a month, as "2006-01" in UTC.
+type MonthCount struct {
+	Month string
+	Count int64
+}
+
+// PriceBucketOf is the index of the price bucket of price.
+func PriceBucketOf(price int64) int {
+	return sort.Search(len(PriceBucketBounds), func(i int) bool {
+		return PriceBucketBounds[i] > price
+	})
+}
+
+// FacetCounts collects the counts of facets, keyed by the category id, the
+// author id, the index of the price bucket and the month.
+type FacetCounts struct {
+	Categories map[int64]int64
+	Authors    map[int64]int64
+	Prices     map[int]int64
+	Months     map[string]int64
+}
+
+func NewFacetCounts() *FacetCounts {
+	return &FacetCounts{
+		Categories: make(map[int64]int64),
+		Authors:    make(map[int64]int64),
+		Prices:     make(map[int]int64),
+		Months:     make(map[string]int64),
+	}
+}
+
+// CountFacets counts list.
+func CountFacets(list []*Ad) *Facets {
+	counts := NewFacetCounts()
+	for _, ad := range list {
+		counts.Categories[ad.CategoryID]++
+		counts.Authors[ad.AuthorID]++
+		counts.Prices[PriceBucketOf(ad.Price)]++
+		counts.Months[ad.CreateDate.UTC().Format("2006-01")]++
+	}
+	return counts.Facets()
+}
+
+// Facets orders the counts: categories and authors by count, the largest
+// first, prices by bucket and months by date. Empty buckets are left out.
+func (c *FacetCounts) Facets() *Facets {
+	f := &Facets{
+		Categories: idCounts(c.Categories),
+		Authors:    idCounts(c.Authors),
+		Prices:     []PriceBucket{},
+		Months:     []MonthCount{},
+	}
+
+	for i := 0; i <= len(PriceBucketBounds); i++ {
+		if c.Prices[i] == 0 {
+			continue
+		}
+		bucket := PriceBucket{Count: c.Prices[i]}
+		if i > 0 {
+			bucket.Min = PriceBucketBounds[i-1]
+		}
+		if i < len(PriceBucketBounds) {
+			max := PriceBucketBounds[i]
+			bucket.Max = &max
+		}
+		f.Prices = append(f.Prices, bucket)
+	}
+
+	for month, count := range c.Months {
+		f.Months = append(f.Months, MonthCount{Month: month, Count: count})
+	}
+	sort.Slice(f.Months, func(i, j int) bool {
+		return f.Months[i].Month < f.Months[j].Month
+	})
+
+	return f
+}
+
+func idCounts(counts map[int64]int64) []IDCount {
+	result := make([]IDCount, 0, len(counts))
+	for id, count := range counts {
+		result = append(result, IDCount{ID: id, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
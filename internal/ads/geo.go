@@ -0,0 +1,305 @@
+package ads
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// EarthRadiusKm is the radius of the sphere distances are measured on, the
+// one of earth() in the earthdistance extension of Postgres, so that both
+// repositories agree.
+const EarthRadiusKm = 6378.168
+
+// MaxCityLength is the longest city an ad can name.
+const MaxCityLength = 100
+
+// Location is a point on Earth in degrees.
+type Location struct {
+	Lat float64
+	Lon float64
+}
+
+// Valid reports whether l is a point on Earth.
+func (l Location) Valid() bool {
+	return l.Lat >= -90 && l.Lat <= 90 && l.Lon >= -180 && l.Lon <= 180
+}
+
+// Place is where an ad is: a point, when it is known, and a city written by
+// the author.
+type Place struct {
+	Location *Location
+	City     string
+}
+
+// Location is the point of the ad, or nil when it has none.
+func (ad *Ad) Location() *Location {
+	if ad.Lat == nil || ad.Lon == nil {
+		return nil
+	}
+	return &Location{Lat: *ad.Lat, Lon: *ad.Lon}
+}
+
+// SetPlace moves ad to p.
+func (ad *Ad) SetPlace(p Place) {
+	ad.Lat, ad.Lon = nil, nil
+	if p.Location != nil {
+		lat, lon := p.Location.Lat, p.Location.Lon
+		ad.Lat, ad.Lon = &lat, &lon
+	}
+	ad.City = p.City
+}
+
+// DistanceKm is the great-circle distance between a and b.
+func DistanceKm(a, b Location) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat, dLon := lat2-lat1, radians(b.Lon-a.Lon)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func degrees(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
+
+// Box is a range of latitudes and longitudes. A box with MinLon greater than
+// MaxLon crosses the antimeridian.
+type Box struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// ParseBox reads a box written as "min_lon,min_lat,max_lon,max_lat", the
+// order of GeoJSON.
+func ParseBox(value string) (Box, error) {
+	var b Box
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return b, fmt.Errorf("bbox must be min_lon,min_lat,max_lon,max_lat")
+	}
+	values := make([]float64, 4)
+	for i, part := range parts {
+		if _, err := fmt.Sscan(strings.TrimSpace(part), &values[i]); err != nil {
+			return b, fmt.Errorf("bbox must be min_lon,min_lat,max_lon,max_lat")
+		}
+	}
+	b = Box{MinLon: values[0], MinLat: values[1], MaxLon: values[2], MaxLat: values[3]}
+	return b, b.Validate()
+}
+
+// Validate checks that b lies on Earth with its south below its north.
+func (b Box) Validate() error {
+	if !(Location{Lat: b.MinLat, Lon: b.MinLon}).Valid() || !(Location{Lat: b.MaxLat, Lon: b.MaxLon}).Valid() {
+		return fmt.Errorf("bbox must lie within latitudes -90 to 90 and longitudes -180 to 180")
+	}
+	if b.MinLat > b.MaxLat {
+		return fmt.Errorf("bbox min_lat is greater than max_lat")
+	}
+	return nil
+}
+
+// Wraps reports whether b crosses the antimeridian.
+func (b Box) Wraps() bool {
+	return b.MinLon > b.MaxLon
+}
+
+// Contains reports whether l lies in b, edges included.
+func (b Box) Contains(l Location) bool {
+	if l.Lat < b.MinLat || l.Lat > b.MaxLat {
+		return false
+	}
+	if b.Wraps() {
+		return l.Lon >= b.MinLon || l.Lon <= b.MaxLon
+	}
+	return l.Lon >= b.MinLon && l.Lon <= b.MaxLon
+}
+
+// Center is the middle of b, on the right side of the antimeridian.
+func (b Box) Center() Location {
+	maxLon := b.MaxLon
+	if b.Wraps() {
+		maxLon += 360
+	}
+	lon := (b.MinLon + maxLon) / 2
+	if lon > 180 {
+		lon -= 360
+	}
+	return Location{Lat: (b.MinLat + b.MaxLat) / 2, Lon: lon}
+}
+
+// BoxAround is the smallest box holding the circle of radiusKm around
+// center. Near a pole it takes every longitude.
+func BoxAround(center Location, radiusKm float64) Box {
+	angle := radiusKm / EarthRadiusKm
+	b := Box{
+		MinLat: center.Lat - degrees(angle),
+		MaxLat: center.Lat + degrees(angle),
+		MinLon: -180,
+		MaxLon: 180,
+	}
+	if b.MinLat <= -90 || b.MaxLat >= 90 || angle >= math.Pi/2 {
+		b.MinLat, b.MaxLat = math.Max(b.MinLat, -90), math.Min(b.MaxLat, 90)
+		return b
+	}
+
+	dLon := degrees(math.Asin(math.Sin(angle) / math.Cos(radians(center.Lat))))
+	if dLon >= 180 {
+		return b
+	}
+	b.MinLon, b.MaxLon = center.Lon-dLon, center.Lon+dLon
+	if b.MinLon < -180 {
+		b.MinLon += 360
+	}
+	if b.MaxLon > 180 {
+		b.MaxLon -= 360
+	}
+	return b
+}
+
+// GeoFilter selects ads by their location. With Radius set only the ads
+// within that many km of Near match; with Box set only the ads in it. The
+// distances of the ads are measured from Near, or from the center of Box
+// without it. Ads with no location never match a GeoFilter in use.
+type GeoFilter struct {
+	Near     *Location
+	RadiusKm float64
+	Box      *Box
+}
+
+// Active reports whether f selects anything.
+func (f GeoFilter) Active() bool {
+	return f.Near != nil || f.Box != nil
+}
+
+// Origin is where distances are measured from, or nil for no filter.
+func (f GeoFilter) Origin() *Location {
+	if f.Near != nil {
+		return f.Near
+	}
+	if f.Box != nil {
+		center := f.Box.Center()
+		return &center
+	}
+	return nil
+}
+
+// Match reports whether ad is in the area of f.
+func (f GeoFilter) Match(ad *Ad) bool {
+	if !f.Active() {
+		return true
+	}
+	l := ad.Location()
+	if l == nil {
+		return false
+	}
+	if f.Near != nil && f.RadiusKm > 0 && DistanceKm(*f.Near, *l) > f.RadiusKm {
+		return false
+	}
+	return f.Box == nil || f.Box.Contains(*l)
+}
+
+// Bounds is a box holding every location f matches, or nil when f doesn't
+// bound them.
+func (f GeoFilter) Bounds() *Box {
+	if f.Box != nil {
+		return f.Box
+	}
+	if f.Near != nil && f.RadiusKm > 0 {
+		b := BoxAround(*f.Near, f.RadiusKm)
+		return &b
+	}
+	return nil
+}
+
+// geohashAlphabet is the base 32 of geohashes.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// Geohash encodes l as a geohash of precision characters. The cell of a
+// geohash is inside the cell of each of its prefixes.
+func Geohash(l Location, precision int) string {
+	minLat, maxLat := -90.0, 90.0
+	minLon, maxLon := -180.0, 180.0
+
+	hash := make([]byte, 0, precision)
+	bits, ch := 0, 0
+	even := true
+	for len(hash) < precision {
+		if even {
+			mid := (minLon + maxLon) / 2
+			if l.Lon >= mid {
+				ch = ch<<1 | 1
+				minLon = mid
+			} else {
+				ch <<= 1
+				maxLon = mid
+			}
+		} else {
+			mid := (minLat + maxLat) / 2
+			if l.Lat >= mid {
+				ch = ch<<1 | 1
+				minLat = mid
+			} else {
+				ch <<= 1
+				maxLat = mid
+			}
+		}
+		even = !even
+		if bits++; bits == 5 {
+			hash = append(hash, geohashAlphabet[ch])
+			bits, ch = 0, 0
+		}
+	}
+	return string(hash)
+}
+
+// GeohashCell is the size in degrees of the cells of geohashes of
+// precision characters.
+func GeohashCell(precision int) (latDeg, lonDeg float64) {
+	bits := 5 * precision
+	lonBits := (bits + 1) / 2
+	latBits := bits / 2
+	return 180 / math.Pow(2, float64(latBits)), 360 / math.Pow(2, float64(lonBits))
+}
+
+// GeohashesCovering lists the geohashes of precision characters whose cells
+// cover b, or false when there are more than max of them.
+func GeohashesCovering(b Box, precision int, max int) ([]string, bool) {
+	latDeg, lonDeg := GeohashCell(precision)
+	latCells := int(math.Pow(2, float64(5*precision/2)))
+	lonCells := int(math.Pow(2, float64((5*precision+1)/2)))
+
+	cell := func(value, min, size float64, cells int) int {
+		i := int(math.Floor((value - min) / size))
+		if i >= cells {
+			i = cells - 1
+		}
+		return i
+	}
+
+	lat0, lat1 := cell(b.MinLat, -90, latDeg, latCells), cell(b.MaxLat, -90, latDeg, latCells)
+	lon0, lon1 := cell(b.MinLon, -180, lonDeg, lonCells), cell(b.MaxLon, -180, lonDeg, lonCells)
+	lonCount := lon1 - lon0 + 1
+	if b.Wraps() {
+		lonCount = lonCells - lon0 + lon1 + 1
+	}
+	if (lat1-lat0+1)*lonCount > max {
+		return nil, false
+	}
+
+	hashes := make([]string, 0, (lat1-lat0+1)*lonCount)
+	for i := lat0; i <= lat1; i++ {
+		for n := 0; n < lonCount; n++ {
+			j := (lon0 + n) % lonCells
+			center := Location{Lat: -90 + (float64(i)+0.5)*latDeg, Lon: -180 + (float64(j)+0.5)*lonDeg}
+			hashes = append(hashes, Geohash(center, precision))
+		}
+	}
+	return hashes, true
+}
@@ -0,0 +1,173 @@
+package ads
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxImageSize is the largest image, in bytes, an ad can have.
+	MaxImageSize = 5 << 20
+	// MaxImages is the number of images an ad can have.
+	MaxImages = 10
+)
+
+// imageTypes maps the content types of images to the extensions of their
+// blobs. They are the types the thumbnails are rendered from, so WebP is
+// not one.
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// ImageExtension returns the file extension for an image content type, or
+// false for a type ads can't have.
+func ImageExtension(contentType string) (string, bool) {
+	ext, ok := imageTypes[contentType]
+	return ext, ok
+}
+
+// Image is a picture of an ad. Its content is kept in a BlobStore under Key
+// and served from URL. Position orders the images of an ad from 0.
+type Image struct {
+	ID          int64     `db:"id"`
+	AdID        int64     `db:"ad_id"`
+	Key         string    `db:"key"`
+	URL         string    `db:"url"`
+	ContentType string    `db:"content_type"`
+	Size        int64     `db:"size"`
+	Position    int       `db:"position"`
+	CreateDate  time.Time `db:"create_date"`
+	// Renditions are filled in by the thumbnail workers after the upload,
+	// in the order they are made.
+	Renditions []*Rendition `db:"-"`
+}
+
+// Rendition returns the rendition of the image named name, or nil when it
+// has none yet.
+func (img *Image) Rendition(name string) *Rendition {
+	for _, rendition := range img.Renditions {
+		if rendition.Name == name {
+			return rendition
+		}
+	}
+	return nil
+}
+
+// Rendition is a resized copy of an image, made by a RenditionSpec.
+type Rendition struct {
+	ID          int64  `db:"id"`
+	ImageID     int64  `db:"image_id"`
+	Name        string `db:"name"`
+	Key         string `db:"key"`
+	URL         string `db:"url"`
+	ContentType string `db:"content_type"`
+	Width       int    `db:"width"`
+	Height      int    `db:"height"`
+	Size        int64  `db:"size"`
+}
+
+// RenditionSpec describes a rendition: the image is scaled down, keeping
+// its proportions, to fit in Width by Height pixels and encoded as Format,
+// "jpeg" or "png". A zero Width or Height doesn't limit that side.
+type RenditionSpec struct {
+	Name   string
+	Width  int
+	Height int
+	Format string
+}
+
+// DefaultRenditions are made when no others are configured.
+var DefaultRenditions = []RenditionSpec{
+	{Name: "thumb", Width: 160, Height: 160, Format: "jpeg"},
+	{Name: "medium", Width: 640, Height: 640, Format: "jpeg"},
+}
+
+// renditionTypes maps the formats of renditions to their content types.
+var renditionTypes = map[string]string{
+	"jpeg": "image/jpeg",
+	"png":  "image/png",
+}
+
+// ContentType is the content type of the renditions made by s.
+func (s RenditionSpec) ContentType() string {
+	return renditionTypes[s.Format]
+}
+
+// ParseRenditionSpecs reads specs written as "name:WIDTHxHEIGHT:format" and
+// separated by commas, such as "thumb:160x160:jpeg,wide:1280x0:png".
+func ParseRenditionSpecs(value string) ([]RenditionSpec, error) {
+	var specs []RenditionSpec
+	names := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("rendition %q is not name:WIDTHxHEIGHT:format", item)
+		}
+		spec := RenditionSpec{Name: parts[0], Format: parts[2]}
+
+		size := strings.Split(parts[1], "x")
+		if len(size) != 2 {
+			return nil, fmt.Errorf("rendition %q has no WIDTHxHEIGHT", item)
+		}
+		var err error
+		if spec.Width, err = strconv.Atoi(size[0]); err != nil {
+			return nil, fmt.Errorf("rendition %q: %w", item, err)
+		}
+		if spec.Height, err = strconv.Atoi(size[1]); err != nil {
+			return nil, fmt.Errorf("rendition %q: %w", item, err)
+		}
+
+		if err := spec.Validate(); err != nil {
+			return nil, err
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("rendition %q is given twice", spec.Name)
+		}
+		names[spec.Name] = true
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// Validate checks that s names a rendition of a known format and of some
+// size.
+func (s RenditionSpec) Validate() error {
+	if s.Name == "" || strings.ContainsAny(s.Name, "/_ ") {
+		return fmt.Errorf("rendition name %q must be a word", s.Name)
+	}
+	if s.Width < 0 || s.Height < 0 || s.Width == 0 && s.Height == 0 {
+		return fmt.Errorf("rendition %q must have a positive width or height", s.Name)
+	}
+	if s.ContentType() == "" {
+		return fmt.Errorf("rendition %q has unknown format %q", s.Name, s.Format)
+	}
+	return nil
+}
+
+// RenditionKey names the blob of a rendition after the blob of its image.
+func RenditionKey(imageKey string, spec RenditionSpec) string {
+	if dot := strings.LastIndex(imageKey, "."); dot > strings.LastIndex(imageKey, "/") {
+		imageKey = imageKey[:dot]
+	}
+	ext, _ := ImageExtension(spec.ContentType())
+	return imageKey + "_" + spec.Name + ext
+}
+
+// BlobStore keeps the content of images.
+type BlobStore interface {
+	// Put stores size bytes read from r under key, replacing a blob
+	// stored under it before.
+	Put(ctx context.Context, key string, contentType string, r io.Reader, size int64) error
+	// Get reads the whole blob under key.
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes the blob under key; a missing blob is not an error.
+	Delete(ctx context.Context, key string) error
+	// URL is where clients download the blob under key from.
+	URL(key string) string
+}
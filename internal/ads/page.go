@@ -0,0 +1,264 @@
+package ads
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+// ErrInvalidCursor is returned for a cursor that was not made by NextCursor
+// for the same sort order.
+var ErrInvalidCursor = fmt.Errorf("invalid cursor")
+
+// Sort orders a list of ads by a field, descending when prefixed with "-".
+// Ties are broken by id, so every order is total. The zero value orders by id.
+// SortRelevance puts the best search results first and SortDistance the
+// nearest ads to the origin of the geo filter; neither has a reverse.
+type Sort string
+
+const (
+	SortCreatedAsc  Sort = "created"
+	SortCreatedDesc Sort = "-created"
+	SortUpdatedAsc  Sort = "updated"
+	SortUpdatedDesc Sort = "-updated"
+	SortTitleAsc    Sort = "title"
+	SortTitleDesc   Sort = "-title"
+	SortPriceAsc    Sort = "price"
+	SortPriceDesc   Sort = "-price"
+	SortRelevance   Sort = "relevance"
+	SortDistance    Sort = "distance"
+)
+
+// Valid reports whether s is a known sort order.
+func (s Sort) Valid() bool {
+	switch s.Field() {
+	case "", "created", "updated", "title", "price":
+		return s != "-"
+	case "relevance":
+		return s == SortRelevance
+	case "distance":
+		return s == SortDistance
+	}
+	return false
+}
+
+// Field is the name of the field s orders by.
+func (s Sort) Field() string {
+	return strings.TrimPrefix(string(s), "-")
+}
+
+// Desc reports whether s is a descending order.
+func (s Sort) Desc() bool {
+	return strings.HasPrefix(string(s), "-")
+}
+
+// Less reports whether a goes before b in the order.
+func (s Sort) Less(a, b *Ad) bool {
+	c := 0
+	switch s.Field() {
+	case "created":
+		c = compareTime(a.CreateDate, b.CreateDate)
+	case "updated":
+		c = compareTime(a.UpdateDate, b.UpdateDate)
+	case "title":
+		c = strings.Compare(a.Title, b.Title)
+	case "price":
+		switch {
+		case a.Price < b.Price:
+			c = -1
+		case a.Price > b.Price:
+			c = 1
+		}
+	case "relevance":
+		switch {
+		case a.Rank > b.Rank:
+			c = -1
+		case a.Rank < b.Rank:
+			c = 1
+		}
+	case "distance":
+		da, db := distance(a), distance(b)
+		switch {
+		case da < db:
+			c = -1
+		case da > db:
+			c = 1
+		}
+	}
+	if s.Desc() {
+		c = -c
+	}
+	if c != 0 {
+		return c < 0
+	}
+	return a.ID < b.ID
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+	return 0
+}
+
+// distance is the distance of ad, putting the ads with none last.
+func distance(ad *Ad) float64 {
+	if ad.Distance == nil {
+		return math.Inf(1)
+	}
+	return *ad.Distance
+}
+
+// SortAds orders list by s.
+func SortAds(list []*Ad, s Sort) {
+	sort.Slice(list, func(i, j int) bool {
+		return s.Less(list[i], list[j])
+	})
+}
+
+// Page asks for at most Limit ads in Sort order after Cursor, which is
+// empty for the first page.
+type Page struct {
+	Limit  int
+	Cursor string
+	Sort   Sort
+}
+
+// Size is the number of ads on the page.
+func (p Page) Size() int {
+	if p.Limit <= 0 {
+		return DefaultLimit
+	}
+	return p.Limit
+}
+
+// List is a page of ads. NextCursor is empty on the last page. Facets are
+// set when the query asks for them.
+type List struct {
+	Ads        []*Ad
+	NextCursor string
+	Facets     *Facets
+}
+
+// Cursor is the position after the last ad of a page: its id and the value
+// of the sort field. It is handed to clients as an opaque string.
+type Cursor struct {
+	Sort  Sort      `json:"s"`
+	ID    int64     `json:"id"`
+	Time  time.Time `json:"t,omitempty"`
+	Title string    `json:"k,omitempty"`
+	Price int64     `json:"p,omitempty"`
+	Rank  float64   `json:"r,omitempty"`
+	// Distance is nil for an ad with no distance.
+	Distance *float64 `json:"d,omitempty"`
+}
+
+// NextCursor encodes the position of ad in the order s.
+func NextCursor(ad *Ad, s Sort) string {
+	c := Cursor{Sort: s, ID: ad.ID}
+	switch s.Field() {
+	case "created":
+		c.Time = ad.CreateDate
+	case "updated":
+		c.Time = ad.UpdateDate
+	case "title":
+		c.Title = ad.Title
+	case "price":
+		c.Price = ad.Price
+	case "relevance":
+		c.Rank = ad.Rank
+	case "distance":
+		c.Distance = ad.Distance
+	}
+
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reads the cursor of p, or returns nil for the first page.
+func (p Page) DecodeCursor() (*Cursor, error) {
+	if p.Cursor == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(p.Cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != p.Sort {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// Value is the value of the sort field at the cursor.
+func (c *Cursor) Value() any {
+	switch c.Sort.Field() {
+	case "created", "updated":
+		return c.Time
+	case "title":
+		return c.Title
+	case "price":
+		return c.Price
+	case "relevance":
+		return c.Rank
+	case "distance":
+		return c.Distance
+	}
+	return nil
+}
+
+// ad is a stand-in for the last ad of the previous page.
+func (c *Cursor) ad() *Ad {
+	return &Ad{ID: c.ID, CreateDate: c.Time, UpdateDate: c.Time, Title: c.Title, Price: c.Price, Rank: c.Rank, Distance: c.Distance}
+}
+
+// After reports whether ad goes after the cursor.
+func (c *Cursor) After(ad *Ad) bool {
+	return c.Sort.Less(c.ad(), ad)
+}
+
+// Paginate sorts list and cuts the page out of it.
+func Paginate(list []*Ad, p Page) (*List, error) {
+	cursor, err := p.DecodeCursor()
+	if err != nil {
+		return nil, err
+	}
+
+	SortAds(list, p.Sort)
+	rest := list
+	if cursor != nil {
+		rest = []*Ad{}
+		for _, ad := range list {
+			if cursor.After(ad) {
+				rest = append(rest, ad)
+			}
+		}
+	}
+
+	return PageOf(rest, p), nil
+}
+
+// PageOf makes a page of ads that are already sorted and start after the
+// cursor. Repositories fetch one ad more than the page size to tell whether
+// there is a next page.
+func PageOf(rest []*Ad, p Page) *List {
+	if len(rest) <= p.Size() {
+		return &List{Ads: rest}
+	}
+	page := rest[:p.Size()]
+	return &List{Ads: page, NextCursor: NextCursor(page[len(page)-1], p.Sort)}
+}
@@ -0,0 +1,35 @@
+package ads
+
+// DefaultCurrency prices ads created without a currency.
+const DefaultCurrency = "RUB"
+
+// currencies are the ISO 4217 codes ads can be priced in.
+var currencies = map[string]bool{
+	"RUB": true, "USD": true, "EUR": true, "GBP": true, "CNY": true, "JPY": true,
+	"CHF": true, "KZT": true, "BYN": true, "UAH": true, "TRY": true, "AED": true,
+	"AMD": true, "GEL": true, "UZS": true, "KGS": true, "AZN": true, "INR": true,
+}
+
+// ValidCurrency reports whether code is a supported ISO 4217 currency code.
+func ValidCurrency(code string) bool {
+	return currencies[code]
+}
+
+// PriceFilter selects published ads by price. Prices are compared in minor
+// units, so bounds make sense together with a currency.
+type PriceFilter struct {
+	MinPrice *int64
+	MaxPrice *int64
+	Currency string
+}
+
+// Match reports whether ad falls within the filter.
+func (f PriceFilter) Match(ad *Ad) bool {
+	if f.MinPrice != nil && ad.Price < *f.MinPrice {
+		return false
+	}
+	if f.MaxPrice != nil && ad.Price > *f.MaxPrice {
+		return false
+	}
+	return f.Currency == "" || ad.Currency == f.Currency
+}
@@ -0,0 +1,100 @@
+package ads
+
+import (
+	"strings"
+	"time"
+)
+
+// Query selects a page of ads. Every set field narrows the result; the zero
+// Query matches all ads, published or not.
+type Query struct {
+	AuthorID *int64
+	// OtherAuthorID leaves out the ads of that author.
+	OtherAuthorID *int64
+	// Published keeps the ads in StatusPublished, or those in any other.
+	Published *bool
+	Status    *Status
+	// The From bounds are inclusive and the To bounds exclusive.
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	UpdatedFrom *time.Time
+	UpdatedTo   *time.Time
+	// Text is looked for in the title and the text, ignoring case.
+	Text string
+	// Search is a full-text query, see ParseSearch. Repositories match it
+	// and rank the ads they find by it.
+	Search string
+	// CategoryID includes the descendants of the category.
+	CategoryID *int64
+	Price      PriceFilter
+	// ActiveAt keeps the ads that have not expired by then.
+	ActiveAt *time.Time
+	// Geo keeps the ads in an area and measures their distances.
+	Geo GeoFilter
+	// City is compared ignoring case.
+	City string
+	Page Page
+	// Facets asks for the facets of all the ads matching the query.
+	Facets bool
+}
+
+// Match reports whether ad satisfies every filter of q but the category,
+// which needs the category tree, and the search, which needs an index.
+func (q Query) Match(ad *Ad) bool {
+	if q.AuthorID != nil && ad.AuthorID != *q.AuthorID {
+		return false
+	}
+	if q.OtherAuthorID != nil && ad.AuthorID == *q.OtherAuthorID {
+		return false
+	}
+	if q.Published != nil && ad.Published() != *q.Published {
+		return false
+	}
+	if q.Status != nil && ad.Status != *q.Status {
+		return false
+	}
+	if q.CreatedFrom != nil && ad.CreateDate.Before(*q.CreatedFrom) {
+		return false
+	}
+	if q.CreatedTo != nil && !ad.CreateDate.Before(*q.CreatedTo) {
+		return false
+	}
+	if q.UpdatedFrom != nil && ad.UpdateDate.Before(*q.UpdatedFrom) {
+		return false
+	}
+	if q.UpdatedTo != nil && !ad.UpdateDate.Before(*q.UpdatedTo) {
+		return false
+	}
+	if q.Text != "" && !containsFold(ad.Title, q.Text) && !containsFold(ad.Text, q.Text) {
+		return false
+	}
+	if q.ActiveAt != nil && ad.Expired(*q.ActiveAt) {
+		return false
+	}
+	if q.City != "" && !strings.EqualFold(ad.City, q.City) {
+		return false
+	}
+	return q.Price.Match(ad) && q.Geo.Match(ad)
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// DayRange is the latest day on or before now that is the given day of its
+// month, as a range from its midnight to the next one in the location of
+// now. Months too short for the day are skipped, so day 31 in April is the
+// 31st of March.
+func DayRange(day int, now time.Time) (from, to time.Time) {
+	year, month, _ := now.Date()
+	if day > now.Day() {
+		month--
+	}
+	for {
+		from = time.Date(year, month, day, 0, 0, 0, 0, now.Location())
+		if from.Day() == day {
+			return from, from.AddDate(0, 0, 1)
+		}
+		month--
+	}
+}
@@ -0,0 +1,83 @@
+package ads
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxReportComment is the longest comment a report can have.
+const MaxReportComment = 1000
+
+// ErrAlreadyReported is returned for a second report of an ad by the same
+// user.
+var ErrAlreadyReported = fmt.Errorf("ad is already reported by the user")
+
+// ErrReportResolved is returned for resolving a report that is not open.
+var ErrReportResolved = fmt.Errorf("report is already resolved")
+
+// ReportReason is why a reader reports an ad.
+type ReportReason string
+
+const (
+	ReportScam       ReportReason = "scam"
+	ReportSpam       ReportReason = "spam"
+	ReportProhibited ReportReason = "prohibited"
+	ReportOffensive  ReportReason = "offensive"
+	ReportOther      ReportReason = "other"
+)
+
+// Valid reports whether r is a known reason.
+func (r ReportReason) Valid() bool {
+	switch r {
+	case ReportScam, ReportSpam, ReportProhibited, ReportOffensive, ReportOther:
+		return true
+	}
+	return false
+}
+
+// ReportStatus is open until a moderator upholds or dismisses the report.
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"
+	ReportUpheld    ReportStatus = "upheld"
+	ReportDismissed ReportStatus = "dismissed"
+)
+
+// Valid reports whether s is a known status.
+func (s ReportStatus) Valid() bool {
+	return s == ReportOpen || s == ReportUpheld || s == ReportDismissed
+}
+
+// Report is a complaint of a reader about an ad. ResolvedBy and ResolveDate
+// are set once it is resolved.
+type Report struct {
+	ID          int64        `db:"id"`
+	AdID        int64        `db:"ad_id"`
+	ReporterID  int64        `db:"reporter_id"`
+	Reason      ReportReason `db:"reason"`
+	Comment     string       `db:"comment"`
+	Status      ReportStatus `db:"status"`
+	CreateDate  time.Time    `db:"create_date"`
+	ResolvedBy  *int64       `db:"resolved_by"`
+	ResolveDate *time.Time   `db:"resolve_date"`
+}
+
+// ReportQuery selects reports in the order of ids. Every set field narrows
+// the result.
+type ReportQuery struct {
+	AdID   *int64
+	Status *ReportStatus
+	// Cursor is the NextCursor of the page before; the repositories read
+	// it as AfterID, the reports after that one.
+	Cursor  string
+	AfterID *int64
+	Limit   int
+}
+
+// ReportList is a page of reports. NextCursor reads the page after it, and
+// is empty on the last page.
+type ReportList struct {
+	Reports    []*Report
+	NextCursor string
+}
@@ -1,15 +1,79 @@
 package ads
 
-import "context"
+import (
+	"context"
+	"time"
+)
 //go:generate mockery --output ../tests/mocks --name RepositryAd
 type RepositryAd interface {
-	ListAds(ctx context.Context) ([]*Ad, error)
+	// Find returns a page of ads matching the query in q.Page.Sort order. An
+	// invalid cursor fails with ErrInvalidCursor. With q.Search set, the ads
+	// found have their Rank filled in, and with q.Geo in use their Distance.
+	Find(ctx context.Context, q Query) (*List, error)
+	// Suggest offers titles of published ads for a search query, see Suggest.
+	Suggest(ctx context.Context, query string, limit int) (*Suggestions, error)
 	GetAd(ctx context.Context, adID int64) (*Ad, error)
+	// Add stores ad with its first revision, made by the author.
 	Add(ctx context.Context, ad *Ad) (int64, error)
-	ChangeStatus(ctx context.Context, adID int64, published bool, authorID int64) (*Ad, error)
-	Update(ctx context.Context, authorID int64, title string, text string, adID int64) (*Ad, error)
-	Search(ctx context.Context, title string) ([]*Ad, error)
-	ListAdsAuthor(ctx context.Context, author int64) ([]*Ad, error)
-	ListAdsDate(ctx context.Context, day int64) ([]*Ad, error)
+	// ChangeStatus moves the ad from status from to status to, with the
+	// rejection reason, the expiry at expiresAt, none when nil, and held as
+	// Ad.Held. It fails with ErrStatusChanged when the ad is no longer in
+	// from.
+	ChangeStatus(ctx context.Context, adID int64, from Status, to Status, reason string, expiresAt *time.Time, held bool) (*Ad, error)
+	// Update puts the content of edit and the screening into the ad
+	// edit.AdID and stores edit as its next revision, filling in its ID,
+	// Number, Diff and CreateDate. With version set it fails with
+	// ErrVersionMismatch unless the ad is at that version. With review set a
+	// published ad goes back to StatusPendingReview in the same change.
+	Update(ctx context.Context, edit *Revision, screening Screening, version *int64, review bool) (*Ad, error)
+	// ListRevisions returns the revisions of an ad by number.
+	ListRevisions(ctx context.Context, adID int64) ([]*Revision, error)
+	GetRevision(ctx context.Context, adID int64, number int) (*Revision, error)
 	DeleteAd(ctx context.Context, authorID int64, adId int64) (*Ad, error)
+
+	// Renew publishes the ad again until expiresAt, to be warned anew. Like
+	// ChangeStatus it fails with ErrStatusChanged when the ad is no longer
+	// in from.
+	Renew(ctx context.Context, adID int64, from Status, expiresAt time.Time) (*Ad, error)
+	// ExpireAds archives the published ads expiring by now and returns
+	// them.
+	ExpireAds(ctx context.Context, now time.Time) ([]*Ad, error)
+	// ClaimExpiring marks the published ads expiring by before whose authors
+	// were not warned yet, and returns them, so that every warning is sent
+	// once even by several sweepers.
+	ClaimExpiring(ctx context.Context, before time.Time) ([]*Ad, error)
+
+	// AddImage stores the image of an ad, at the position set in it.
+	AddImage(ctx context.Context, image *Image) (int64, error)
+	// DeleteImage removes an image of an ad and moves the images after it
+	// one position up.
+	DeleteImage(ctx context.Context, adID int64, imageID int64) (*Image, error)
+	// ReorderImages puts the images of an ad, all of them, in the order of
+	// imageIDs.
+	ReorderImages(ctx context.Context, adID int64, imageIDs []int64) error
+	// AddRendition stores a rendition of an image of an ad; it fails once the
+	// image is deleted.
+	AddRendition(ctx context.Context, adID int64, rendition *Rendition) (int64, error)
+	// ImagesLackingRenditions returns the images added before before that
+	// have no rendition by one of names, with the renditions they have, by
+	// id.
+	ImagesLackingRenditions(ctx context.Context, names []string, before time.Time) ([]*Image, error)
+
+	// AddReport fails with ErrAlreadyReported when the reporter has
+	// reported the ad before.
+	AddReport(ctx context.Context, report *Report) (int64, error)
+	GetReport(ctx context.Context, reportID int64) (*Report, error)
+	ListReports(ctx context.Context, q ReportQuery) ([]*Report, error)
+	// ResolveReports resolves the open reports of an ad with status, only
+	// reportID when it is set, and returns them. A report that is not open
+	// fails with ErrReportResolved.
+	ResolveReports(ctx context.Context, adID int64, reportID *int64, status ReportStatus, moderatorID int64) ([]*Report, error)
+
+	AddCategory(ctx context.Context, category *Category) (int64, error)
+	UpdateCategory(ctx context.Context, category *Category) (*Category, error)
+	GetCategory(ctx context.Context, categoryID int64) (*Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*Category, error)
+	ListCategories(ctx context.Context) ([]*Category, error)
+	// DeleteCategory fails for a category that has subcategories or ads.
+	DeleteCategory(ctx context.Context, categoryID int64) error
 }
@@ -0,0 +1,133 @@
+package ads
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Revision is the content of an ad as an edit left it. Revision 1 is the ad
+// as created, and every update adds the next one; revisions are never
+// changed.
+type Revision struct {
+	ID       int64    `db:"id"`
+	AdID     int64    `db:"ad_id"`
+	Number   int      `db:"number"`
+	EditorID int64    `db:"editor_id"`
+	Title    string   `db:"title"`
+	Text     string   `db:"text"`
+	Price    int64    `db:"price"`
+	Currency string   `db:"currency"`
+	Lat      *float64 `db:"lat"`
+	Lon      *float64 `db:"lon"`
+	City     string   `db:"city"`
+	// RestoredFrom is the number of the revision this one restores.
+	RestoredFrom *int      `db:"restored_from"`
+	Diff         Diff      `db:"diff"` // from the revision before
+	CreateDate   time.Time `db:"create_date"`
+}
+
+// NewRevision takes the content of ad as edited by editorID.
+func NewRevision(ad *Ad, editorID int64) *Revision {
+	return &Revision{
+		AdID:     ad.ID,
+		EditorID: editorID,
+		Title:    ad.Title,
+		Text:     ad.Text,
+		Price:    ad.Price,
+		Currency: ad.Currency,
+		Lat:      ad.Lat,
+		Lon:      ad.Lon,
+		City:     ad.City,
+	}
+}
+
+// Place is where the ad of the revision was.
+func (r *Revision) Place() Place {
+	located := Ad{Lat: r.Lat, Lon: r.Lon, City: r.City}
+	return Place{Location: located.Location(), City: r.City}
+}
+
+// SetPlace moves the ad of the revision to p.
+func (r *Revision) SetPlace(p Place) {
+	var located Ad
+	located.SetPlace(p)
+	r.Lat, r.Lon, r.City = located.Lat, located.Lon, located.City
+}
+
+// Apply puts the content of r into ad.
+func (r *Revision) Apply(ad *Ad) {
+	ad.Title = r.Title
+	ad.Text = r.Text
+	ad.Price = r.Price
+	ad.Currency = r.Currency
+	ad.SetPlace(r.Place())
+}
+
+// Change is a field of an ad changed by a revision, with the values written
+// as text.
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Diff lists the fields a revision changed, in the order of the fields of
+// Revision. It is stored as JSON.
+type Diff []Change
+
+// DiffFrom lists the changes from before to r; before is nil for the first
+// revision.
+func (r *Revision) DiffFrom(before *Revision) Diff {
+	if before == nil {
+		before = &Revision{}
+	}
+	diff := Diff{}
+	add := func(field string, old string, new string) {
+		if old != new {
+			diff = append(diff, Change{Field: field, Old: old, New: new})
+		}
+	}
+	add("title", before.Title, r.Title)
+	add("text", before.Text, r.Text)
+	add("price", strconv.FormatInt(before.Price, 10), strconv.FormatInt(r.Price, 10))
+	add("currency", before.Currency, r.Currency)
+	add("location", formatLocation(before.Place().Location), formatLocation(r.Place().Location))
+	add("city", before.City, r.City)
+	return diff
+}
+
+// formatLocation writes l as "lat,lon", or empty when there is none.
+func formatLocation(l *Location) string {
+	if l == nil {
+		return ""
+	}
+	return strconv.FormatFloat(l.Lat, 'f', -1, 64) + "," + strconv.FormatFloat(l.Lon, 'f', -1, 64)
+}
+
+// Value implements driver.Valuer.
+func (d Diff) Value() (driver.Value, error) {
+	if d == nil {
+		d = Diff{}
+	}
+	return json.Marshal(d)
+}
+
+// Scan implements sql.Scanner.
+func (d *Diff) Scan(src any) error {
+	var data []byte
+	switch src := src.(type) {
+	case []byte:
+		data = src
+	case string:
+		data = []byte(src)
+	case nil:
+		*d = Diff{}
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into Diff", src)
+	}
+	return json.Unmarshal(data, d)
+}
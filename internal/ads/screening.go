@@ -0,0 +1,14 @@
+package ads
+
+// Screening is what the screening rules found in an ad when it was last
+// written: the sum of the scores of the rules it matched and the names of
+// those that hold it for review, comma-separated.
+type Screening struct {
+	Score float64 `db:"screening_score"`
+	Flags string  `db:"screening_flags"`
+}
+
+// Flagged reports whether a rule holds the ad for review.
+func (s Screening) Flagged() bool {
+	return s.Flags != ""
+}
@@ -0,0 +1,42 @@
+package ads
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Weights of a word found in the title and in the text of an ad when ranking
+// search results. They are the defaults of ts_rank in Postgres, where the
+// title is weighted A and the text B.
+const (
+	TitleWeight = 1.0
+	TextWeight  = 0.4
+)
+
+// Tokenize splits s into lowercase words of letters and digits, the way ads
+// are indexed for search.
+func Tokenize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// ParseSearch splits a search query into phrases: the words in double quotes
+// make one phrase and every other word is a phrase of its own. An ad matches
+// the query when it has all of the phrases.
+func ParseSearch(query string) [][]string {
+	var phrases [][]string
+	for i, part := range strings.Split(query, `"`) {
+		words := Tokenize(part)
+		if i%2 == 1 {
+			if len(words) > 0 {
+				phrases = append(phrases, words)
+			}
+			continue
+		}
+		for _, word := range words {
+			phrases = append(phrases, []string{word})
+		}
+	}
+	return phrases
+}
@@ -0,0 +1,56 @@
+package ads
+
+import "fmt"
+
+// MaxRejectionReason is the longest reason a moderator can give for
+// rejecting an ad.
+const MaxRejectionReason = 500
+
+// ErrStatusChanged is returned for a change of status made from a status the
+// ad is no longer in.
+var ErrStatusChanged = fmt.Errorf("ad status changed meanwhile")
+
+// Status is where an ad is in its lifecycle. An author submits a draft for
+// review, a moderator publishes or rejects it, and a published ad is
+// archived when it expires or is taken down.
+type Status string
+
+const (
+	StatusDraft         Status = "draft"
+	StatusPendingReview Status = "pending_review"
+	StatusPublished     Status = "published"
+	StatusRejected      Status = "rejected"
+	StatusArchived      Status = "archived"
+)
+
+// transitions lists the statuses an ad can go to from each status. An
+// archived ad goes back to published only by renewal, and a published one
+// to review only when the screening flags a change of it.
+var transitions = map[Status][]Status{
+	StatusDraft:         {StatusPendingReview, StatusArchived},
+	StatusPendingReview: {StatusPublished, StatusRejected, StatusDraft},
+	StatusPublished:     {StatusArchived, StatusPendingReview},
+	StatusRejected:      {StatusPendingReview, StatusDraft, StatusArchived},
+	StatusArchived:      {StatusPendingReview, StatusPublished, StatusDraft},
+}
+
+// Valid reports whether s is a known status.
+func (s Status) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanBecome reports whether an ad in s can go to status to.
+func (s Status) CanBecome(to Status) bool {
+	for _, next := range transitions[s] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Published reports whether ad is shown to everyone.
+func (ad *Ad) Published() bool {
+	return ad.Status == StatusPublished
+}
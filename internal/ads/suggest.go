@@ -0,0 +1,98 @@
+package ads
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultSuggestLimit = 5
+	MaxSuggestLimit     = 20
+
+	// SimilarityThreshold is the least similarity of a correction, the
+	// default of pg_trgm.similarity_threshold.
+	SimilarityThreshold = 0.3
+)
+
+// Suggestion is a title of published ads offered for a search query, scored
+// by its similarity to the query.
+type Suggestion struct {
+	Title string  `db:"title"`
+	Score float64 `db:"score"`
+}
+
+// Suggestions are the titles starting with a query and the titles the query
+// may be a misspelling of, the best first.
+type Suggestions struct {
+	Completions []Suggestion
+	Corrections []Suggestion
+}
+
+// Trigrams returns the trigrams of the words of s the way pg_trgm makes them:
+// each word is lowercased and padded with two spaces in front and one after.
+func Trigrams(s string) map[string]bool {
+	result := make(map[string]bool)
+	for _, word := range Tokenize(s) {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			result[string(padded[i:i+3])] = true
+		}
+	}
+	return result
+}
+
+// Similarity is the share of trigrams a and b have in common, from 0 to 1,
+// as the similarity function of pg_trgm computes it.
+func Similarity(a, b string) float64 {
+	ta, tb := Trigrams(a), Trigrams(b)
+	shared := 0
+	for t := range ta {
+		if tb[t] {
+			shared++
+		}
+	}
+	if shared == 0 {
+		return 0
+	}
+	return float64(shared) / float64(len(ta)+len(tb)-shared)
+}
+
+// Suggest picks suggestions for query out of titles, keeping at most limit
+// of each kind. Titles differing only in case are offered once.
+func Suggest(titles []string, query string, limit int) *Suggestions {
+	prefix := strings.ToLower(query)
+	seen := make(map[string]string)
+	for _, title := range titles {
+		key := strings.ToLower(title)
+		if other, ok := seen[key]; !ok || title < other {
+			seen[key] = title
+		}
+	}
+
+	result := &Suggestions{Completions: []Suggestion{}, Corrections: []Suggestion{}}
+	for key, title := range seen {
+		s := Suggestion{Title: title, Score: Similarity(key, prefix)}
+		if strings.HasPrefix(key, prefix) {
+			result.Completions = append(result.Completions, s)
+		} else if s.Score >= SimilarityThreshold {
+			result.Corrections = append(result.Corrections, s)
+		}
+	}
+
+	result.Completions = bestSuggestions(result.Completions, limit)
+	result.Corrections = bestSuggestions(result.Corrections, limit)
+	return result
+}
+
+func bestSuggestions(list []Suggestion, limit int) []Suggestion {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Score != list[j].Score {
+			return list[i].Score > list[j].Score
+		}
+		return list[i].Title < list[j].Title
+	})
+	if len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
@@ -2,9 +2,11 @@ package app
 
 import (
 	"context"
-	"crypto/sha1"
+	"errors"
 	"fmt"
+	"io"
 	"time"
+	"unicode/utf8"
 
 	"ads/internal/ads"
 	"ads/internal/user"
@@ -15,6 +17,8 @@ import (
 var ErrBadRequest = fmt.Errorf("bad request")
 var ErrForbidden = fmt.Errorf("forbidden")
 var ErrNotFound = fmt.Errorf("not found user in db")
+var ErrUnauthorized = fmt.Errorf("unauthorized")
+var ErrPreconditionFailed = fmt.Errorf("precondition failed")
 
 type validateStruct struct {
 	Text string `json:"text"`
@@ -34,27 +38,124 @@ type appStruct struct {
 }
 
 type AdApp interface {
-	CreateAd(ctx context.Context, title string, text string, authorID int64) (*ads.Ad, error)
-	ChangeAdStatus(ctx context.Context, adID int64, published bool, authorID int64) (*ads.Ad, error)
-	UpdateAd(ctx context.Context, authorID int64, title string, text string, adID int64) (*ads.Ad, error)
+	// CreateAd places the ad at place, nowhere when it is nil. CreateAd and
+	// UpdateAd screen the ads by the rules of SetScreeningRules.
+	CreateAd(ctx context.Context, title string, text string, categoryID int64, price int64, currency string, place *ads.Place) (*ads.Ad, error)
+	// ChangeAdStatus submits an ad for review, or publishes it for the term
+	// of WithAdExpiry right away when WithReview is off and no screening
+	// rule flagged it. Unpublishing archives a published ad and returns an
+	// ad under review to the drafts; either way is a no-op for an ad
+	// already there.
+	ChangeAdStatus(ctx context.Context, adID int64, published bool) (*ads.Ad, error)
+	RenewAd(ctx context.Context, adID int64) (*ads.Ad, error)
+	// ModerationQueue lists the ads pending review, the longest waiting
+	// first unless page.Sort says otherwise. It is empty rather than an
+	// error when there are none.
+	ModerationQueue(ctx context.Context, page ads.Page) (*ads.List, error)
+	ApproveAd(ctx context.Context, adID int64) (*ads.Ad, error)
+	// RejectAd sends an ad back to its author with reason, which is
+	// required.
+	RejectAd(ctx context.Context, adID int64, reason string) (*ads.Ad, error)
+	SetScreeningRules(rules []ScreeningRule) error
+	// ReportAd files a report of a published ad by the caller, once per
+	// ad; see WithReportThreshold.
+	ReportAd(ctx context.Context, adID int64, reason ads.ReportReason, comment string) (*ads.Report, error)
+	ListReports(ctx context.Context, q ads.ReportQuery) (*ads.ReportList, error)
+	ResolveReport(ctx context.Context, reportID int64, status ads.ReportStatus) (*ads.Report, error)
+	// SweepAds takes the expired ads down once; WithExpirySweeper runs it
+	// in the background.
+	SweepAds(ctx context.Context) error
+	// UpdateAd keeps the current price when currency is empty and the
+	// current place when place is nil. A published ad goes back to review
+	// when WithReview is on or the screening flags it. Every update is kept as a revision.
+	// With version set the update fails with ErrPreconditionFailed unless
+	// the ad is still at that version.
+	UpdateAd(ctx context.Context, title string, text string, price int64, currency string, place *ads.Place, version *int64, adID int64) (*ads.Ad, error)
+	// ListRevisions and GetRevision show the history of an ad to its
+	// author and to moderators.
+	ListRevisions(ctx context.Context, adID int64) ([]*ads.Revision, error)
+	GetRevision(ctx context.Context, adID int64, number int) (*ads.Revision, error)
+	// RestoreRevision updates the ad to the content of an older revision;
+	// version is checked as in UpdateAd.
+	RestoreRevision(ctx context.Context, adID int64, number int, version *int64) (*ads.Ad, error)
+	// GetAd returns a published ad to anyone and any other ad to its
+	// author and moderators only.
 	GetAd(ctx context.Context, adID int64) (*ads.Ad, error)
-	ListAds(ctx context.Context) ([]*ads.Ad, error)
-	SearchAdByName(ctx context.Context, title string) ([]*ads.Ad, error)
-	ListAdsAuthor(ctx context.Context, author int64) ([]*ads.Ad, error)
-	ListAdsDate(ctx context.Context, day int64) ([]*ads.Ad, error)
-	DeleteAd(ctx context.Context, authorID int64, adID int64) (*ads.Ad, error)
+	// FindAds runs a composed query; the list methods below are shortcuts for it.
+	FindAds(ctx context.Context, q ads.Query) (*ads.List, error)
+	ListAds(ctx context.Context, page ads.Page) (*ads.List, error)
+	// SearchAds runs a full-text search in q.Search, narrowed by the other
+	// filters of q and ordered by relevance unless q.Page.Sort says otherwise.
+	SearchAds(ctx context.Context, q ads.Query) (*ads.List, error)
+	// SuggestAds completes and corrects a search query from the titles of
+	// published ads; limit is per kind of suggestion, 0 for the default.
+	SuggestAds(ctx context.Context, query string, limit int) (*ads.Suggestions, error)
+	ListAdsAuthor(ctx context.Context, author int64, page ads.Page) (*ads.List, error)
+	ListAdsDate(ctx context.Context, day int64, page ads.Page) (*ads.List, error)
+	DeleteAd(ctx context.Context, adID int64) (*ads.Ad, error)
+	ListAdsCategory(ctx context.Context, categoryID int64, page ads.Page) (*ads.List, error)
+	ListAdsPrice(ctx context.Context, filter ads.PriceFilter, page ads.Page) (*ads.List, error)
+
+	// AddImage appends an image, size bytes read from r, to an ad.
+	AddImage(ctx context.Context, adID int64, r io.Reader, size int64) (*ads.Ad, error)
+	DeleteImage(ctx context.Context, adID int64, imageID int64) (*ads.Ad, error)
+	ReorderImages(ctx context.Context, adID int64, imageIDs []int64) (*ads.Ad, error)
+	// Close waits for the background work on ads, such as thumbnails, to
+	// finish and stops the sweeper.
+	Close() error
+
+	CreateCategory(ctx context.Context, parentID *int64, name string, slug string) (*ads.Category, error)
+	UpdateCategory(ctx context.Context, categoryID int64, parentID *int64, name string, slug string) (*ads.Category, error)
+	DeleteCategory(ctx context.Context, categoryID int64) error
+	GetCategory(ctx context.Context, categoryID int64) (*ads.Category, error)
+	GetCategoryBySlug(ctx context.Context, slug string) (*ads.Category, error)
+	ListCategories(ctx context.Context) ([]*ads.Category, error)
 }
 
 type adApp struct {
 	repository ads.RepositryAd
+	blobs      ads.BlobStore
+	thumbnails *thumbnailer
+	expiry     expiry
+	review     bool
+	screening  screening
+	reportThreshold int
 }
 
-func (a *adApp) CreateAd(ctx context.Context, title string, text string, authorID int64) (*ads.Ad, error) {
+func (a *adApp) CreateAd(ctx context.Context, title string, text string, categoryID int64, price int64, currency string, place *ads.Place) (*ads.Ad, error) {
+	actor, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
 	if err := validate.Validate(validateStruct{Text: text, Title: title}); err != nil {
 		return nil, ErrBadRequest
 	}
+
+	if currency == "" {
+		currency = ads.DefaultCurrency
+	}
+	if err := validatePrice(price, currency); err != nil {
+		return nil, err
+	}
+
+	if err := validatePlace(place); err != nil {
+		return nil, err
+	}
+
+	if _, err := a.repository.GetCategory(ctx, categoryID); err != nil {
+		return nil, fmt.Errorf("%w: no such category", ErrBadRequest)
+	}
 	
-	ad := ads.Ad{Title: title, Text: text, AuthorID: authorID, Published: false, CreateDate: time.Now().UTC()}
+	ad := ads.Ad{Title: title, Text: text, AuthorID: actor.UserID, CategoryID: categoryID, Price: price, Currency: currency, Status: ads.StatusDraft, CreateDate: time.Now().UTC(), Version: 1}
+	if place != nil {
+		ad.SetPlace(*place)
+	}
+	screening, err := a.screen(ctx, &ad)
+	if err != nil {
+		return nil, err
+	}
+	ad.Screening = screening
 	id, err := a.repository.Add(ctx, &ad)
 
 	if err != nil {
@@ -66,39 +167,115 @@ func (a *adApp) CreateAd(ctx context.Context, title string, text string, authorI
 	return &ad, nil
 }
 
-func (a *adApp) ChangeAdStatus(ctx context.Context, adID int64, published bool, authorID int64) (*ads.Ad, error) {
-	ad, err := a.repository.GetAd(ctx, adID)
+func (a *adApp) ChangeAdStatus(ctx context.Context, adID int64, published bool) (*ads.Ad, error) {
+	actor, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
 
+	ad, err := a.repository.GetAd(ctx, adID)
 	if err != nil {
 		return nil, err
-	} else if ad.AuthorID != authorID || ad.ID != adID {
-		return nil, ErrForbidden
 	}
-	
-	ad, err = a.repository.ChangeStatus(ctx, adID, published, authorID)
-	
-	if err != nil {
+
+	action := ActionUnpublishAd
+	if published {
+		action = ActionPublishAd
+	}
+	if err := authorize(ctx, action, ad.AuthorID); err != nil {
 		return nil, err
 	}
 
-	return ad, nil
+	if !published {
+		switch ad.Status {
+		case ads.StatusPublished:
+			// a moderator taking down the ad of someone else holds it
+			if actor.UserID != ad.AuthorID {
+				return a.hold(ctx, ad, ads.StatusArchived, "")
+			}
+			return a.changeStatus(ctx, ad, ads.StatusArchived, "", nil)
+		case ads.StatusPendingReview:
+			if ad.Held && !Can(actor, ActionModerateAd, ad.AuthorID) {
+				return nil, fmt.Errorf("%w: ad is held for review by a moderator", ErrForbidden)
+			}
+			return a.changeStatus(ctx, ad, ads.StatusDraft, "", nil)
+		}
+		return ad, nil
+	}
+
+	if ad.Status == ads.StatusPublished || ad.Status == ads.StatusPendingReview {
+		return ad, nil
+	}
+	ad, err = a.changeStatus(ctx, ad, ads.StatusPendingReview, "", nil)
+	if err != nil || a.review || ad.Flagged() || ad.Held {
+		return ad, err
+	}
+	return a.publish(ctx, ad)
 }
 
-func (a *adApp) UpdateAd(ctx context.Context, authorID int64, title string, text string, adID int64) (*ads.Ad, error) {
+func (a *adApp) UpdateAd(ctx context.Context, title string, text string, price int64, currency string, place *ads.Place, version *int64, adID int64) (*ads.Ad, error) {
+	actor, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
 	if err := validate.Validate(validateStruct{Text: text, Title: title}); err != nil {
 		return nil, ErrBadRequest
 	}
+	if err := validatePlace(place); err != nil {
+		return nil, err
+	}
 	
 	ad, err := a.repository.GetAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorize(ctx, ActionUpdateAd, ad.AuthorID); err != nil {
+		return nil, err
+	}
+	if version != nil && ad.Version != *version {
+		return nil, versionMismatch(ad.Version, *version)
+	}
 
+	if currency == "" {
+		price, currency = ad.Price, ad.Currency
+	} else if err := validatePrice(price, currency); err != nil {
+		return nil, err
+	}
+
+	if place == nil {
+		place = &ads.Place{Location: ad.Location(), City: ad.City}
+	}
+
+	edit := &ads.Revision{AdID: adID, EditorID: actor.UserID, Title: title, Text: text, Price: price, Currency: currency}
+	edit.SetPlace(*place)
+	return a.update(ctx, ad, edit, version)
+}
+
+// versionMismatch is the error of an update made to version of an ad that
+// is at current.
+func versionMismatch(current int64, version int64) error {
+	return fmt.Errorf("%w: ad is at version %d, not %d", ErrPreconditionFailed, current, version)
+}
+
+// update screens ad as edit leaves it and stores edit as its next revision,
+// if the ad is at version when it is set.
+func (a *adApp) update(ctx context.Context, ad *ads.Ad, edit *ads.Revision, version *int64) (*ads.Ad, error) {
+	updated := *ad
+	edit.Apply(&updated)
+	screening, err := a.screen(ctx, &updated)
 	if err != nil {
 		return nil, err
-	} else if ad.AuthorID != authorID || ad.ID != adID {
-		return nil, ErrForbidden
 	}
 
-	ad, err = a.repository.Update(ctx, authorID, title, text, adID)
+	// an edit of a published ad is reviewed again, as is one that no
+	// longer passes the screening, before anyone sees it
+	ad, err = a.repository.Update(ctx, edit, screening, version, a.review || screening.Flagged())
 	
+	if errors.Is(err, ads.ErrVersionMismatch) {
+		return nil, fmt.Errorf("%w: %s", ErrPreconditionFailed, err.Error())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -111,46 +288,96 @@ func (a *adApp) GetAd(ctx context.Context, adID int64) (*ads.Ad, error) {
 	if err != nil {
 		return nil, ErrBadRequest
 	}
+	if !ad.Published() && !MayListUnpublished(ctx, &ad.AuthorID) {
+		return nil, fmt.Errorf("%w: ad is not published", ErrForbidden)
+	}
 	return ad, nil
 }
 
-func (a *adApp) ListAds(ctx context.Context) ([]*ads.Ad, error) {
-	ads, err := a.repository.ListAds(ctx)
-	if err != nil {
-		return nil, ErrBadRequest
+func (a *adApp) ListAds(ctx context.Context, page ads.Page) (*ads.List, error) {
+	published := true
+	return a.FindAds(ctx, ads.Query{Published: &published, Page: page})
+}
+
+func (a *adApp) ListAdsAuthor(ctx context.Context, author int64, page ads.Page) (*ads.List, error) {
+	return a.FindAds(ctx, ads.Query{AuthorID: &author, Published: legacyPublished(ctx, &author), Page: page})
+}
+
+// ListAdsDate lists the ads created on the latest date, in UTC, that falls
+// on the given day of the month.
+func (a *adApp) ListAdsDate(ctx context.Context, day int64, page ads.Page) (*ads.List, error) {
+	if day < 1 || day > 31 {
+		return nil, fmt.Errorf("%w: day must be from 1 to 31", ErrBadRequest)
 	}
-	return ads, nil
+	from, to := ads.DayRange(int(day), time.Now().UTC())
+	return a.FindAds(ctx, ads.Query{CreatedFrom: &from, CreatedTo: &to, Published: legacyPublished(ctx, nil), Page: page})
 }
 
-func (a *adApp) SearchAdByName(ctx context.Context, title string) ([]*ads.Ad, error) {
-	ads, err := a.repository.Search(ctx, title)
-	if err != nil {
-		return nil, err
+func (a *adApp) ListAdsPrice(ctx context.Context, filter ads.PriceFilter, page ads.Page) (*ads.List, error) {
+	published := true
+	return a.FindAds(ctx, ads.Query{Published: &published, Price: filter, Page: page})
+}
+
+// validatePage checks the limit, the sort order and that the cursor was
+// made for that order.
+func validatePage(page ads.Page) error {
+	if page.Limit < 0 || page.Limit > ads.MaxLimit {
+		return fmt.Errorf("%w: limit must be from 1 to %d", ErrBadRequest, ads.MaxLimit)
+	}
+	if !page.Sort.Valid() {
+		return fmt.Errorf("%w: unknown sort %q", ErrBadRequest, page.Sort)
 	}
-	return ads, nil
+	if _, err := page.DecodeCursor(); err != nil {
+		return fmt.Errorf("%w: %s", ErrBadRequest, err.Error())
+	}
+	return nil
 }
 
-func (a *adApp) ListAdsAuthor(ctx context.Context, author int64) ([]*ads.Ad, error) {
-	ads, err := a.repository.ListAdsAuthor(ctx, author)
-	if err != nil {
-		return nil, ErrBadRequest
+// validatePrice checks a price in minor units and its ISO 4217 currency.
+func validatePrice(price int64, currency string) error {
+	if price < 0 {
+		return fmt.Errorf("%w: price must not be negative", ErrBadRequest)
+	}
+	if !ads.ValidCurrency(currency) {
+		return fmt.Errorf("%w: unknown currency %q", ErrBadRequest, currency)
 	}
-	return ads, nil
+	return nil
 }
 
-func (a *adApp) ListAdsDate(ctx context.Context, day int64) ([]*ads.Ad, error) {
-	ads, err := a.repository.ListAdsDate(ctx, day)
-	if err != nil {
-		return nil, ErrBadRequest
+func validatePlace(place *ads.Place) error {
+	if place == nil {
+		return nil
+	}
+	if place.Location != nil && !place.Location.Valid() {
+		return fmt.Errorf("%w: lat must be from -90 to 90 and lon from -180 to 180", ErrBadRequest)
 	}
-	return ads, nil
+	if utf8.RuneCountInString(place.City) > ads.MaxCityLength {
+		return fmt.Errorf("%w: city is longer than %d characters", ErrBadRequest, ads.MaxCityLength)
+	}
+	return nil
 }
 
-func (a *adApp) DeleteAd(ctx context.Context, authorID int64, adID int64) (*ads.Ad, error) {
-	ad, err := a.repository.DeleteAd(ctx, authorID, adID)
+func (a *adApp) DeleteAd(ctx context.Context, adID int64) (*ads.Ad, error) {
+	if _, ok := PrincipalFromContext(ctx); !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ad, err := a.repository.GetAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorize(ctx, ActionDeleteAd, ad.AuthorID); err != nil {
+		return nil, err
+	}
+
+	// the images go with the ad, only their blobs are left to delete
+	images := ad.Images
+	ad, err = a.repository.DeleteAd(ctx, ad.AuthorID, adID)
 	if err != nil {
 		return nil, err
 	}
+	a.deleteImageBlobs(ctx, images)
 	return ad, nil
 }
  
@@ -170,7 +397,7 @@ type userApp struct {
 	if nickname == "" || email == "" {
 		return nil, ErrBadRequest
 	}
-	user := user.User{NickName: nickname, Email: email}
+	user := user.User{NickName: nickname, Email: email, Role: user.RoleUser}
 
 	userID, err := a.repository.AddUser(ctx, &user)
 
@@ -184,6 +411,10 @@ type userApp struct {
  }
 
  func (a *userApp) UpdateUser(ctx context.Context, nickname string, email string, userID int64, activate bool) (*user.User, error) {
+	if err := authorize(ctx, ActionUpdateUser, userID); err != nil {
+		return nil, err
+	}
+
 	user, err := a.repository.UpdateUser(ctx, nickname, email, userID, activate)
 
 	if err != nil {
@@ -212,6 +443,10 @@ type userApp struct {
  }
 
 func (a *userApp) DeleteUser(ctx context.Context, user_id int64) (error) {
+	if err := authorize(ctx, ActionDeleteUser, user_id); err != nil {
+		return err
+	}
+
 	err := a.repository.DeleteUser(ctx, user_id)
 
 	if err != nil {
@@ -222,31 +457,89 @@ func (a *userApp) DeleteUser(ctx context.Context, user_id int64) (error) {
 }
 
 type UserDbApp interface {
-	CreateUserDb(user user.UserDb) (int, error)
+	CreateUserDb(user user.User) (int, error)
+	GenerateToken(ctx context.Context, username string, password string) (*Tokens, error)
+	ParseToken(ctx context.Context, accessToken string) (Principal, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*Tokens, error)
+	SignOut(ctx context.Context) error
+	RevokeUserSessions(ctx context.Context, userID int64) error
+	SetUserRole(ctx context.Context, userID int64, role user.Role) (*user.User, error)
 }
 
 type authApp struct {
 	repository user.RepositoryDbUser
+	signingKey      []byte
+	tokenTTL        time.Duration
+	refreshTokenTTL time.Duration
+	hasher          PasswordHasher
 }
 
-func (a *authApp) CreateUserDb(user user.UserDb) (int, error) {
-	user.Password = generatePasswordHash(user.Password)
-	return a.repository.CreateUserDb(user)
+func (a *authApp) CreateUserDb(u user.User) (int, error) {
+	hash, err := a.hasher.Hash(u.Password)
+	if err != nil {
+		return 0, err
+	}
+	u.Password = hash
+	u.Role = user.RoleUser
+	return a.repository.CreateUserDb(u)
 }
 
-const salt = "hjqrhjqw124617ajfhajs"
+// SetUserRole changes the role of a user and signs them out, so that no
+// access token keeps the previous role.
+func (a *authApp) SetUserRole(ctx context.Context, userID int64, role user.Role) (*user.User, error) {
+	if err := authorize(ctx, ActionSetUserRole, userID); err != nil {
+		return nil, err
+	}
+	if !role.Valid() {
+		return nil, ErrBadRequest
+	}
 
-func generatePasswordHash(password string) string {
-	hash := sha1.New()
-	hash.Write([]byte(password))
+	u, err := a.repository.SetUserRole(ctx, userID, role)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	if err := a.repository.RevokeUserSessions(ctx, userID); err != nil {
+		return nil, err
+	}
 
-	return fmt.Sprintf("%x", hash.Sum([]byte(salt)))
+	return u, nil
 }
 
-func NewApp(repo ads.RepositryAd, repoUser user.RepositoryUser, repoUserDb user.RepositoryDbUser) App {
-	return &appStruct{
-		adApp: adApp{repository: repo},
+// DeleteUser also signs the deleted user out of every session.
+func (a *appStruct) DeleteUser(ctx context.Context, user_id int64) error {
+	if err := a.userApp.DeleteUser(ctx, user_id); err != nil {
+		return err
+	}
+
+	return a.authApp.repository.RevokeUserSessions(ctx, user_id)
+}
+
+func NewApp(repo ads.RepositryAd, repoUser user.RepositoryUser, repoUserDb user.RepositoryDbUser, opts ...Option) App {
+	a := &appStruct{
+		adApp: adApp{
+			repository: repo,
+			expiry: expiry{
+				ttl:      defaultAdTTL,
+				notice:   defaultExpiryNotice,
+				notifier: logNotifier{},
+				now:      time.Now,
+			},
+			reportThreshold: defaultReportThreshold,
+		},
 		userApp: userApp{repository: repoUser},
-		authApp: authApp{repository: repoUserDb},
+		authApp: authApp{
+			repository: repoUserDb,
+			signingKey:      randomSigningKey(),
+			tokenTTL:        defaultTokenTTL,
+			refreshTokenTTL: defaultRefreshTokenTTL,
+			hasher:          NewArgon2idHasher(DefaultArgon2idParams),
+		},
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+	a.adApp.startThumbnails()
+	a.adApp.startSweeper()
+	return a
 }
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"ads/internal/user"
+)
+
+const (
+	defaultTokenTTL        = 15 * time.Minute
+	defaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// Option configures optional parts of the application.
+type Option func(*appStruct)
+
+// WithSigningKey sets the HMAC key for access tokens. Without it every
+// process signs with its own random key.
+func WithSigningKey(key []byte) Option {
+	return func(a *appStruct) {
+		a.authApp.signingKey = key
+	}
+}
+
+// WithPasswordHasher replaces the argon2id hasher used for new passwords.
+func WithPasswordHasher(h PasswordHasher) Option {
+	return func(a *appStruct) {
+		a.authApp.hasher = h
+	}
+}
+
+// WithRefreshTokenTTL sets how long a refresh token can be exchanged.
+func WithRefreshTokenTTL(ttl time.Duration) Option {
+	return func(a *appStruct) {
+		a.authApp.refreshTokenTTL = ttl
+	}
+}
+
+// WithTokenTTL sets how long an access token stays valid.
+func WithTokenTTL(ttl time.Duration) Option {
+	return func(a *appStruct) {
+		a.authApp.tokenTTL = ttl
+	}
+}
+
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	UserID int64 `json:"user_id"`
+	// SessionID is the family of the refresh token issued with the access token.
+	SessionID string    `json:"sid"`
+	Role      user.Role `json:"role"`
+}
+
+// Tokens is the result of a sign-in or a refresh.
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+func (a *authApp) GenerateToken(ctx context.Context, username string, password string) (*Tokens, error) {
+	u, err := a.repository.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	ok, rehash, err := a.verifyPassword(password, u.Password)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	if rehash {
+		a.upgradePasswordHash(ctx, u.UserID, password)
+	}
+
+	familyID, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return a.issueTokens(ctx, u, familyID)
+}
+
+func (a *authApp) ParseToken(ctx context.Context, accessToken string) (Principal, error) {
+	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return a.signingKey, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %s", ErrUnauthorized, err.Error())
+	}
+
+	claims, ok := token.Claims.(*tokenClaims)
+	if !ok || claims.SessionID == "" {
+		return Principal{}, ErrUnauthorized
+	}
+
+	active, err := a.repository.SessionFamilyActive(ctx, claims.SessionID)
+	if err != nil {
+		return Principal{}, err
+	} else if !active {
+		return Principal{}, errSessionRevoked
+	}
+
+	return Principal{UserID: claims.UserID, SessionID: claims.SessionID, Role: claims.Role}, nil
+}
+
+func (a *authApp) signAccessToken(u *user.User, sessionID string) (string, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.tokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+		UserID:    u.UserID,
+		SessionID: sessionID,
+		Role:      u.Role,
+	})
+
+	return token.SignedString(a.signingKey)
+}
+
+func randomSigningKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return key
+}
+
+// Principal is the authenticated caller a request acts on behalf of.
+type Principal struct {
+	UserID int64
+	// SessionID is empty for callers that did not come with an access token.
+	SessionID string
+	Role      user.Role
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx that carries the caller.
+func ContextWithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the caller put into ctx by the ports.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,122 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"ads/internal/ads"
+)
+
+var ErrCategoryNotFound = fmt.Errorf("not found category")
+
+func (a *adApp) CreateCategory(ctx context.Context, parentID *int64, name string, slug string) (*ads.Category, error) {
+	if err := authorize(ctx, ActionManageCategories, 0); err != nil {
+		return nil, err
+	}
+
+	category := ads.Category{ParentID: parentID, Name: name, Slug: slug}
+	if err := a.checkCategory(ctx, &category, false); err != nil {
+		return nil, err
+	}
+
+	if _, err := a.repository.AddCategory(ctx, &category); err != nil {
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (a *adApp) UpdateCategory(ctx context.Context, categoryID int64, parentID *int64, name string, slug string) (*ads.Category, error) {
+	if err := authorize(ctx, ActionManageCategories, 0); err != nil {
+		return nil, err
+	}
+
+	if _, err := a.repository.GetCategory(ctx, categoryID); err != nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	category := ads.Category{ID: categoryID, ParentID: parentID, Name: name, Slug: slug}
+	if err := a.checkCategory(ctx, &category, true); err != nil {
+		return nil, err
+	}
+
+	if parentID != nil {
+		categories, err := a.repository.ListCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ads.Subtree(categories, categoryID) {
+			if id == *parentID {
+				return nil, fmt.Errorf("%w: a category cannot be moved under itself", ErrBadRequest)
+			}
+		}
+	}
+
+	return a.repository.UpdateCategory(ctx, &category)
+}
+
+func (a *adApp) DeleteCategory(ctx context.Context, categoryID int64) error {
+	if err := authorize(ctx, ActionManageCategories, 0); err != nil {
+		return err
+	}
+
+	if _, err := a.repository.GetCategory(ctx, categoryID); err != nil {
+		return ErrCategoryNotFound
+	}
+
+	err := a.repository.DeleteCategory(ctx, categoryID)
+	if errors.Is(err, ads.ErrCategoryNotEmpty) {
+		return fmt.Errorf("%w: %s", ErrBadRequest, err.Error())
+	}
+	return err
+}
+
+func (a *adApp) GetCategory(ctx context.Context, categoryID int64) (*ads.Category, error) {
+	category, err := a.repository.GetCategory(ctx, categoryID)
+	if err != nil {
+		return nil, ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func (a *adApp) GetCategoryBySlug(ctx context.Context, slug string) (*ads.Category, error) {
+	category, err := a.repository.GetCategoryBySlug(ctx, slug)
+	if err != nil {
+		return nil, ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+func (a *adApp) ListCategories(ctx context.Context) ([]*ads.Category, error) {
+	return a.repository.ListCategories(ctx)
+}
+
+func (a *adApp) ListAdsCategory(ctx context.Context, categoryID int64, page ads.Page) (*ads.List, error) {
+	published := true
+	return a.FindAds(ctx, ads.Query{Published: &published, CategoryID: &categoryID, Page: page})
+}
+
+// checkCategory validates a new or an existing changed category: the slug is
+// unique and the parent exists.
+func (a *adApp) checkCategory(ctx context.Context, category *ads.Category, existing bool) error {
+	if category.Name == "" || len(category.Name) > 255 {
+		return fmt.Errorf("%w: category name must be 1 to 255 characters", ErrBadRequest)
+	}
+	if !ads.ValidSlug(category.Slug) {
+		return fmt.Errorf("%w: slug must be lowercase words joined by dashes", ErrBadRequest)
+	}
+
+	if other, err := a.repository.GetCategoryBySlug(ctx, category.Slug); err == nil && (!existing || other.ID != category.ID) {
+		return fmt.Errorf("%w: slug is already taken", ErrBadRequest)
+	}
+
+	if category.ParentID == nil {
+		return nil
+	}
+	if _, err := a.repository.GetCategory(ctx, *category.ParentID); err != nil {
+		return fmt.Errorf("%w: no such parent category", ErrBadRequest)
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"ads/internal/ads"
+)
+
+const (
+	defaultAdTTL        = 30 * 24 * time.Hour
+	defaultExpiryNotice = 3 * 24 * time.Hour
+)
+
+// Notifier tells users about their ads.
+type Notifier interface {
+	// AdExpiring warns the author of ad that it goes down at ad.ExpiresAt.
+	AdExpiring(ctx context.Context, ad *ads.Ad) error
+}
+
+// logNotifier writes the warnings to the log, for when there is no other
+// way to reach the users.
+type logNotifier struct{}
+
+func (logNotifier) AdExpiring(ctx context.Context, ad *ads.Ad) error {
+	log.Println("ad", ad.ID, "of user", ad.AuthorID, "expires at", ad.ExpiresAt.Format(time.RFC3339))
+	return nil
+}
+
+// expiry archives published ads after their term.
+type expiry struct {
+	ttl      time.Duration
+	notice   time.Duration
+	notifier Notifier
+	now      func() time.Time
+
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// WithAdExpiry sets how long an ad stays published and how long before its
+// expiry the author is warned.
+func WithAdExpiry(ttl time.Duration, notice time.Duration) Option {
+	return func(a *appStruct) {
+		a.adApp.expiry.ttl = ttl
+		a.adApp.expiry.notice = notice
+	}
+}
+
+// WithNotifier sends the warnings of expiring ads through n instead of the
+// log.
+func WithNotifier(n Notifier) Option {
+	return func(a *appStruct) {
+		a.adApp.expiry.notifier = n
+	}
+}
+
+// WithExpirySweeper runs SweepAds every interval in the background, and
+// makes the renditions the thumbnail workers did not, see retryRenditions.
+func WithExpirySweeper(interval time.Duration) Option {
+	return func(a *appStruct) {
+		a.adApp.expiry.interval = interval
+	}
+}
+
+// WithClock replaces the clock the expiry of ads is measured by.
+func WithClock(now func() time.Time) Option {
+	return func(a *appStruct) {
+		a.adApp.expiry.now = now
+	}
+}
+
+func (a *adApp) now() time.Time {
+	return a.expiry.now().UTC()
+}
+
+func (a *adApp) startSweeper() {
+	e := &a.expiry
+	if e.interval <= 0 {
+		return
+	}
+	e.stop, e.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(e.done)
+		ticker := time.NewTicker(e.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.stop:
+				return
+			case <-ticker.C:
+				if err := a.SweepAds(context.Background()); err != nil {
+					log.Println("error sweep ads", err)
+				}
+				a.retryRenditions(context.Background())
+			}
+		}
+	}()
+}
+
+func (a *adApp) stopSweeper() {
+	e := &a.expiry
+	if e.stop == nil {
+		return
+	}
+	select {
+	case <-e.stop:
+	default:
+		close(e.stop)
+	}
+	<-e.done
+}
+
+// SweepAds archives the expired ads and warns the authors of the ads
+// expiring within the notice. A failed warning is not sent again, and an ad
+// first seen past its expiry goes down with no warning.
+func (a *adApp) SweepAds(ctx context.Context) error {
+	now := a.now()
+	expired, err := a.repository.ExpireAds(ctx, now)
+	if err != nil {
+		return err
+	}
+	for _, ad := range expired {
+		log.Println("ad expired", ad.ID)
+	}
+
+	expiring, err := a.repository.ClaimExpiring(ctx, now.Add(a.expiry.notice))
+	if err != nil {
+		return err
+	}
+	for _, ad := range expiring {
+		if err := a.expiry.notifier.AdExpiring(ctx, ad); err != nil {
+			log.Println("error notify ad expiring", ad.ID, err)
+		}
+	}
+	return nil
+}
+
+// RenewAd extends the term of a published ad, or publishes an expired one
+// again, for the full term from now.
+func (a *adApp) RenewAd(ctx context.Context, adID int64) (*ads.Ad, error) {
+	if _, ok := PrincipalFromContext(ctx); !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ad, err := a.repository.GetAd(ctx, adID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no such ad", ErrBadRequest)
+	}
+	if err := authorize(ctx, ActionRenewAd, ad.AuthorID); err != nil {
+		return nil, err
+	}
+	// an ad archived by its author or a moderator has no expiry left to
+	// renew
+	expired := ad.Status == ads.StatusArchived && ad.ExpiresAt != nil
+	if !ad.Published() && !expired {
+		return nil, fmt.Errorf("%w: ad is not published", ErrBadRequest)
+	}
+
+	renewed, err := a.repository.Renew(ctx, adID, ad.Status, a.now().Add(a.expiry.ttl))
+	if errors.Is(err, ads.ErrStatusChanged) {
+		return nil, fmt.Errorf("%w: %s", ErrBadRequest, err.Error())
+	}
+	return renewed, err
+}
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"ads/internal/ads"
+	"ads/internal/thumbnail"
+)
+
+var ErrImageTooLarge = fmt.Errorf("%w: image is larger than %d bytes", ErrBadRequest, ads.MaxImageSize)
+var ErrUnsupportedImage = fmt.Errorf("%w: unsupported image type", ErrBadRequest)
+var ErrImageNotFound = fmt.Errorf("not found image")
+
+var errNoBlobStore = fmt.Errorf("no blob store for images")
+
+// sniffLen is how much of an upload http.DetectContentType looks at.
+const sniffLen = 512
+
+// WithBlobStore sets where the images of ads are kept. Without it images
+// can't be uploaded.
+func WithBlobStore(store ads.BlobStore) Option {
+	return func(a *appStruct) {
+		a.adApp.blobs = store
+	}
+}
+
+// AddImage stores size bytes of r as the last image of an ad. The type of
+// the image is sniffed from its content; what the client claims is ignored.
+// The renditions of the image are made after it returns.
+func (a *adApp) AddImage(ctx context.Context, adID int64, r io.Reader, size int64) (*ads.Ad, error) {
+	ad, err := a.imageAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if size <= 0 {
+		return nil, fmt.Errorf("%w: image is empty", ErrBadRequest)
+	}
+	if size > ads.MaxImageSize {
+		return nil, ErrImageTooLarge
+	}
+	if len(ad.Images) >= ads.MaxImages {
+		return nil, fmt.Errorf("%w: an ad can have at most %d images", ErrBadRequest, ads.MaxImages)
+	}
+
+	// the upload is small enough to keep for the thumbnail workers
+	data := make([]byte, size)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, fmt.Errorf("%w: image is shorter than %d bytes", ErrBadRequest, size)
+	}
+
+	head := data
+	if len(head) > sniffLen {
+		head = head[:sniffLen]
+	}
+	contentType := http.DetectContentType(head)
+	ext, ok := ads.ImageExtension(contentType)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImage, contentType)
+	}
+	// an image the workers can't decode would never get its renditions
+	if a.thumbnails != nil {
+		if err := thumbnail.Check(data); err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedImage, err.Error())
+		}
+	}
+
+	key, err := imageKey(adID, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.blobs.Put(ctx, key, contentType, bytes.NewReader(data), size); err != nil {
+		return nil, err
+	}
+
+	image := ads.Image{
+		AdID:        adID,
+		Key:         key,
+		URL:         a.blobs.URL(key),
+		ContentType: contentType,
+		Size:        size,
+		Position:    len(ad.Images),
+		CreateDate:  time.Now().UTC(),
+	}
+	if _, err := a.repository.AddImage(ctx, &image); err != nil {
+		a.blobs.Delete(ctx, key)
+		return nil, err
+	}
+	a.queueRenditions(adID, &image, data)
+
+	return a.repository.GetAd(ctx, adID)
+}
+
+// DeleteImage removes an image of an ad together with its blob.
+func (a *adApp) DeleteImage(ctx context.Context, adID int64, imageID int64) (*ads.Ad, error) {
+	ad, err := a.imageAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	image := findImage(ad, imageID)
+	if image == nil {
+		return nil, ErrImageNotFound
+	}
+
+	if _, err := a.repository.DeleteImage(ctx, adID, imageID); err != nil {
+		return nil, err
+	}
+	a.deleteImageBlobs(ctx, []*ads.Image{image})
+
+	return a.repository.GetAd(ctx, adID)
+}
+
+// ReorderImages puts the images of an ad in the order of imageIDs, which
+// must list every image of the ad once.
+func (a *adApp) ReorderImages(ctx context.Context, adID int64, imageIDs []int64) (*ads.Ad, error) {
+	ad, err := a.imageAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(imageIDs) != len(ad.Images) {
+		return nil, fmt.Errorf("%w: every image of the ad must be ordered", ErrBadRequest)
+	}
+	seen := make(map[int64]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		if seen[id] || findImage(ad, id) == nil {
+			return nil, fmt.Errorf("%w: every image of the ad must be ordered once", ErrBadRequest)
+		}
+		seen[id] = true
+	}
+
+	if err := a.repository.ReorderImages(ctx, adID, imageIDs); err != nil {
+		return nil, err
+	}
+
+	return a.repository.GetAd(ctx, adID)
+}
+
+// imageAd gets an ad whose images the caller may change.
+func (a *adApp) imageAd(ctx context.Context, adID int64) (*ads.Ad, error) {
+	if _, ok := PrincipalFromContext(ctx); !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ad, err := a.repository.GetAd(ctx, adID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no such ad", ErrBadRequest)
+	}
+
+	if err := authorize(ctx, ActionUpdateAd, ad.AuthorID); err != nil {
+		return nil, err
+	}
+
+	if a.blobs == nil {
+		return nil, errNoBlobStore
+	}
+
+	return ad, nil
+}
+
+// deleteImageBlobs removes the blobs of deleted images and of their
+// renditions. The images are gone for clients already, a blob left behind
+// is only wasted space.
+func (a *adApp) deleteImageBlobs(ctx context.Context, images []*ads.Image) {
+	if a.blobs == nil {
+		return
+	}
+	for _, image := range images {
+		a.blobs.Delete(ctx, image.Key)
+		for _, rendition := range image.Renditions {
+			a.blobs.Delete(ctx, rendition.Key)
+		}
+	}
+}
+
+func findImage(ad *ads.Ad, imageID int64) *ads.Image {
+	for _, image := range ad.Images {
+		if image.ID == imageID {
+			return image
+		}
+	}
+	return nil
+}
+
+// imageKey names the blob of a new image of an ad. The random part keeps
+// the URLs of deleted images from being reused.
+func imageKey(adID int64, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ads/%d/%s%s", adID, hex.EncodeToString(b), ext), nil
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"ads/internal/ads"
+)
+
+// WithReview holds the ads submitted by their authors for a moderator to
+// approve before they are published, and the edits of published ads until
+// approved again.
+func WithReview() Option {
+	return func(a *appStruct) {
+		a.adApp.review = true
+	}
+}
+
+func (a *adApp) ModerationQueue(ctx context.Context, page ads.Page) (*ads.List, error) {
+	if err := authorize(ctx, ActionModerateAd, 0); err != nil {
+		return nil, err
+	}
+
+	if page.Sort == "" {
+		page.Sort = ads.SortUpdatedAsc
+	}
+	pending := ads.StatusPendingReview
+	q := ads.Query{Status: &pending, Page: page}
+	if err := validateQuery(q); err != nil {
+		return nil, err
+	}
+	return a.repository.Find(ctx, q)
+}
+
+func (a *adApp) ApproveAd(ctx context.Context, adID int64) (*ads.Ad, error) {
+	ad, err := a.pendingAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	return a.publish(ctx, ad)
+}
+
+func (a *adApp) RejectAd(ctx context.Context, adID int64, reason string) (*ads.Ad, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is empty", ErrBadRequest)
+	}
+	if utf8.RuneCountInString(reason) > ads.MaxRejectionReason {
+		return nil, fmt.Errorf("%w: reason is longer than %d characters", ErrBadRequest, ads.MaxRejectionReason)
+	}
+
+	ad, err := a.pendingAd(ctx, adID)
+	if err != nil {
+		return nil, err
+	}
+	return a.hold(ctx, ad, ads.StatusRejected, reason)
+}
+
+// pendingAd reads an ad for the caller to review.
+func (a *adApp) pendingAd(ctx context.Context, adID int64) (*ads.Ad, error) {
+	if _, ok := PrincipalFromContext(ctx); !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ad, err := a.repository.GetAd(ctx, adID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no such ad", ErrBadRequest)
+	}
+	if err := authorize(ctx, ActionModerateAd, ad.AuthorID); err != nil {
+		return nil, err
+	}
+	if ad.Status != ads.StatusPendingReview {
+		return nil, fmt.Errorf("%w: ad is not pending review", ErrBadRequest)
+	}
+	return ad, nil
+}
+
+// publish publishes ad for the term of WithAdExpiry.
+func (a *adApp) publish(ctx context.Context, ad *ads.Ad) (*ads.Ad, error) {
+	expiresAt := a.now().Add(a.expiry.ttl)
+	return a.changeStatus(ctx, ad, ads.StatusPublished, "", &expiresAt)
+}
+
+// changeStatus moves ad to status to, if its lifecycle allows it, with
+// reason for rejecting it and its expiry at expiresAt. A held ad stays held
+// until it is published. It fails when the ad was changed since it was read.
+func (a *adApp) changeStatus(ctx context.Context, ad *ads.Ad, to ads.Status, reason string, expiresAt *time.Time) (*ads.Ad, error) {
+	return a.moveStatus(ctx, ad, to, reason, expiresAt, ad.Held && to != ads.StatusPublished)
+}
+
+// hold moves ad to status to on the decision of a moderator, so that it
+// needs approval to be published again, see ads.Ad.Held.
+func (a *adApp) hold(ctx context.Context, ad *ads.Ad, to ads.Status, reason string) (*ads.Ad, error) {
+	return a.moveStatus(ctx, ad, to, reason, nil, true)
+}
+
+func (a *adApp) moveStatus(ctx context.Context, ad *ads.Ad, to ads.Status, reason string, expiresAt *time.Time, held bool) (*ads.Ad, error) {
+	if !ad.Status.CanBecome(to) {
+		return nil, fmt.Errorf("%w: %s ad cannot become %s", ErrBadRequest, ad.Status, to)
+	}
+
+	changed, err := a.repository.ChangeStatus(ctx, ad.ID, ad.Status, to, reason, expiresAt, held)
+	if errors.Is(err, ads.ErrStatusChanged) {
+		return nil, fmt.Errorf("%w: %s", ErrBadRequest, err.Error())
+	}
+	return changed, err
+}
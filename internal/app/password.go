@@ -0,0 +1,187 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordHasher turns passwords into self-describing encoded hashes, so
+// that the cost parameters are stored next to every hash.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether password matches encoded, and whether encoded
+	// was made with other parameters and should be replaced by a new Hash.
+	Verify(password string, encoded string) (ok bool, rehash bool, err error)
+}
+
+var errMalformedHash = fmt.Errorf("malformed password hash")
+
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams follow the second recommended option of RFC 9106.
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 4,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher returns a hasher producing
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>.
+func NewArgon2idHasher(params Argon2idParams) PasswordHasher {
+	return &argon2idHasher{params: params}
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	p := h.params
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.Memory, p.Iterations, p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *argon2idHasher) Verify(password string, encoded string) (bool, bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, false, errMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false, errMalformedHash
+	}
+
+	var p Argon2idParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return false, false, errMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false, errMalformedHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, false, errMalformedHash
+	}
+	p.SaltLength, p.KeyLength = uint32(len(salt)), uint32(len(key))
+
+	other := argon2.IDKey([]byte(password), salt, p.Iterations, p.Memory, p.Parallelism, p.KeyLength)
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return false, false, nil
+	}
+
+	return true, p != h.params, nil
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher returns a hasher producing $2a$<cost>$... hashes.
+func NewBcryptHasher(cost int) PasswordHasher {
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	return string(hash), err
+}
+
+func (h *bcryptHasher) Verify(password string, encoded string) (bool, bool, error) {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return false, false, errMalformedHash
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, false, nil
+	} else if err != nil {
+		return false, false, err
+	}
+
+	return true, cost != h.cost, nil
+}
+
+// verifyPassword checks password against a stored hash of any supported
+// format. Hashes in the legacy SHA-1 format always need a rehash.
+func (a *authApp) verifyPassword(password string, encoded string) (bool, bool, error) {
+	if isLegacyPasswordHash(encoded) {
+		legacy := legacyPasswordHash(password)
+		return subtle.ConstantTimeCompare([]byte(legacy), []byte(encoded)) == 1, true, nil
+	}
+
+	ok, rehash, err := a.hasher.Verify(password, encoded)
+	if !errors.Is(err, errMalformedHash) {
+		return ok, rehash, err
+	}
+
+	// the hash was made by the other supported algorithm before the
+	// configured hasher was switched
+	other := NewArgon2idHasher(DefaultArgon2idParams)
+	if strings.HasPrefix(encoded, "$2") {
+		other = NewBcryptHasher(bcrypt.DefaultCost)
+	}
+	ok, _, err = other.Verify(password, encoded)
+
+	return ok, true, err
+}
+
+// upgradePasswordHash stores a hash made by the current hasher. A failure
+// only delays the upgrade until the next sign-in.
+func (a *authApp) upgradePasswordHash(ctx context.Context, userID int64, password string) {
+	hash, err := a.hasher.Hash(password)
+	if err != nil {
+		log.Println("error rehash password", err)
+		return
+	}
+
+	if err := a.repository.UpdatePasswordHash(ctx, userID, hash); err != nil {
+		log.Println("error rehash password", err)
+		return
+	}
+	log.Println("password hash upgraded for user", userID)
+}
+
+// legacySalt was appended to unsalted SHA-1 digests before the hasher was
+// pluggable. It is kept only to verify hashes stored back then.
+const legacySalt = "hjqrhjqw124617ajfhajs"
+
+func legacyPasswordHash(password string) string {
+	hash := sha1.New()
+	hash.Write([]byte(password))
+
+	return fmt.Sprintf("%x", hash.Sum([]byte(legacySalt)))
+}
+
+func isLegacyPasswordHash(encoded string) bool {
+	return len(encoded) == 2*(len(legacySalt)+sha1.Size) && strings.HasPrefix(encoded, hex.EncodeToString([]byte(legacySalt)))
+}
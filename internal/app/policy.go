@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"ads/internal/user"
+)
+
+// Action is an operation on an ad or a user that the policy decides on.
+type Action string
+
+const (
+	ActionUpdateAd      Action = "update ad"
+	ActionPublishAd     Action = "publish ad"
+	ActionUnpublishAd   Action = "unpublish ad"
+	ActionDeleteAd      Action = "delete ad"
+	ActionRenewAd       Action = "renew ad"
+	ActionModerateAd    Action = "moderate ad"
+	ActionViewAdHistory Action = "view ad history"
+
+	ActionUpdateUser         Action = "update user"
+	ActionDeleteUser         Action = "delete user"
+	ActionSetUserRole        Action = "set user role"
+	ActionRevokeUserSessions Action = "revoke user sessions"
+
+	ActionManageCategories Action = "manage categories"
+)
+
+// Can reports whether p may perform action on an ad or a user owned by
+// ownerID. For users the owner is the user itself.
+//
+// Authors manage their own ads and users their own profile. Moderators can
+// also review, take down and read the history of any ad, and admins can also
+// manage any user.
+func Can(p Principal, action Action, ownerID int64) bool {
+	owner := p.UserID == ownerID
+
+	switch action {
+	case ActionUpdateAd, ActionPublishAd, ActionRenewAd:
+		return owner
+	case ActionUnpublishAd, ActionDeleteAd, ActionViewAdHistory:
+		return owner || p.Role == user.RoleModerator || p.Role == user.RoleAdmin
+	case ActionModerateAd:
+		return p.Role == user.RoleModerator || p.Role == user.RoleAdmin
+	case ActionUpdateUser, ActionDeleteUser, ActionRevokeUserSessions:
+		return owner || p.Role == user.RoleAdmin
+	case ActionSetUserRole, ActionManageCategories:
+		return p.Role == user.RoleAdmin
+	}
+
+	return false
+}
+
+// authorize checks the caller in ctx against the policy.
+func authorize(ctx context.Context, action Action, ownerID int64) error {
+	actor, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return ErrUnauthorized
+	}
+
+	if !Can(actor, action, ownerID) {
+		return fmt.Errorf("%w: %s", ErrForbidden, action)
+	}
+
+	return nil
+}
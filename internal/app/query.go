@@ -0,0 +1,174 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"ads/internal/ads"
+)
+
+// maxSuggestQuery bounds the queries to suggest for, which are typed into a
+// search box.
+const maxSuggestQuery = 100
+
+// errNotFoundAd keeps the list endpoints reporting an empty result as a bad
+// request, as they always have.
+var errNotFoundAd = fmt.Errorf("%w: not found ad", ErrBadRequest)
+
+// FindAds returns a page of ads matching q. An empty first page is
+// errNotFoundAd; a later page comes back empty when the ads after its cursor
+// were deleted meanwhile. Only moderators list the ads that are not
+// published, except the authors listing their own.
+func (a *adApp) FindAds(ctx context.Context, q ads.Query) (*ads.List, error) {
+	if err := validateQuery(q); err != nil {
+		return nil, err
+	}
+	if err := authorizeQuery(ctx, q); err != nil {
+		return nil, err
+	}
+
+	// the published ads the sweeper has not archived yet are gone too
+	published := q.Published != nil && *q.Published || q.Status != nil && *q.Status == ads.StatusPublished
+	if published && q.ActiveAt == nil {
+		now := a.now()
+		q.ActiveAt = &now
+	}
+
+	if q.CategoryID != nil {
+		if _, err := a.repository.GetCategory(ctx, *q.CategoryID); err != nil {
+			return nil, ErrCategoryNotFound
+		}
+	}
+
+	list, err := a.repository.Find(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Ads) == 0 && q.Page.Cursor == "" {
+		return nil, errNotFoundAd
+	}
+
+	return list, nil
+}
+
+func (a *adApp) SearchAds(ctx context.Context, q ads.Query) (*ads.List, error) {
+	if len(ads.ParseSearch(q.Search)) == 0 {
+		return nil, fmt.Errorf("%w: search query has no words", ErrBadRequest)
+	}
+	if q.Page.Sort == "" {
+		q.Page.Sort = ads.SortRelevance
+	}
+	return a.FindAds(ctx, q)
+}
+
+func (a *adApp) SuggestAds(ctx context.Context, query string, limit int) (*ads.Suggestions, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: query is empty", ErrBadRequest)
+	}
+	if utf8.RuneCountInString(query) > maxSuggestQuery {
+		return nil, fmt.Errorf("%w: query is longer than %d characters", ErrBadRequest, maxSuggestQuery)
+	}
+	if limit < 0 || limit > ads.MaxSuggestLimit {
+		return nil, fmt.Errorf("%w: limit must be from 1 to %d", ErrBadRequest, ads.MaxSuggestLimit)
+	}
+	if limit == 0 {
+		limit = ads.DefaultSuggestLimit
+	}
+	return a.repository.Suggest(ctx, query, limit)
+}
+
+// MayListUnpublished reports whether the caller in ctx may list the ads
+// that are not published: moderators may, and so may the author of the ads
+// when authorID is set.
+func MayListUnpublished(ctx context.Context, authorID *int64) bool {
+	actor, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return false
+	}
+	if authorID != nil && *authorID == actor.UserID {
+		return true
+	}
+	return Can(actor, ActionModerateAd, 0)
+}
+
+// authorizeQuery checks the caller in ctx may see every ad q can match.
+func authorizeQuery(ctx context.Context, q ads.Query) error {
+	publishedOnly := q.Status != nil && *q.Status == ads.StatusPublished ||
+		q.Status == nil && q.Published != nil && *q.Published
+	if publishedOnly || MayListUnpublished(ctx, q.AuthorID) {
+		return nil
+	}
+	return fmt.Errorf("%w: only moderators list the ads that are not published", ErrForbidden)
+}
+
+// legacyPublished narrows the old author and date filters, which listed
+// drafts too, to the published ads for the callers who may not see the
+// others.
+func legacyPublished(ctx context.Context, authorID *int64) *bool {
+	if MayListUnpublished(ctx, authorID) {
+		return nil
+	}
+	published := true
+	return &published
+}
+
+func validateQuery(q ads.Query) error {
+	if err := validatePage(q.Page); err != nil {
+		return err
+	}
+	if q.Page.Sort == ads.SortRelevance && q.Search == "" {
+		return fmt.Errorf("%w: sort by relevance needs a search", ErrBadRequest)
+	}
+	if q.Page.Sort == ads.SortDistance && !q.Geo.Active() {
+		return fmt.Errorf("%w: sort by distance needs a point or a bbox", ErrBadRequest)
+	}
+	if err := validateGeo(q.Geo); err != nil {
+		return err
+	}
+
+	if q.CreatedFrom != nil && q.CreatedTo != nil && q.CreatedFrom.After(*q.CreatedTo) {
+		return fmt.Errorf("%w: created_from is after created_to", ErrBadRequest)
+	}
+	if q.UpdatedFrom != nil && q.UpdatedTo != nil && q.UpdatedFrom.After(*q.UpdatedTo) {
+		return fmt.Errorf("%w: updated_from is after updated_to", ErrBadRequest)
+	}
+
+	if q.Status != nil && !q.Status.Valid() {
+		return fmt.Errorf("%w: unknown status %q", ErrBadRequest, *q.Status)
+	}
+	if utf8.RuneCountInString(q.City) > ads.MaxCityLength {
+		return fmt.Errorf("%w: city is longer than %d characters", ErrBadRequest, ads.MaxCityLength)
+	}
+
+	price := q.Price
+	if price.MinPrice != nil && price.MaxPrice != nil && *price.MinPrice > *price.MaxPrice {
+		return fmt.Errorf("%w: min price is greater than max price", ErrBadRequest)
+	}
+	if price.Currency != "" && !ads.ValidCurrency(price.Currency) {
+		return fmt.Errorf("%w: unknown currency %q", ErrBadRequest, price.Currency)
+	}
+
+	return nil
+}
+
+func validateGeo(geo ads.GeoFilter) error {
+	if geo.Near != nil && !geo.Near.Valid() {
+		return fmt.Errorf("%w: lat must be from -90 to 90 and lon from -180 to 180", ErrBadRequest)
+	}
+	if geo.RadiusKm < 0 || math.IsNaN(geo.RadiusKm) {
+		return fmt.Errorf("%w: radius must be positive", ErrBadRequest)
+	}
+	if geo.RadiusKm > 0 && geo.Near == nil {
+		return fmt.Errorf("%w: radius needs a point", ErrBadRequest)
+	}
+	if geo.Box != nil {
+		if err := geo.Box.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrBadRequest, err)
+		}
+	}
+	return nil
+}
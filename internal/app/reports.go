@@ -0,0 +1,199 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"ads/internal/ads"
+)
+
+const defaultReportThreshold = 3
+
+// WithReportThreshold hides a published ad and sends it to review once it
+// has n open reports, never when n is 0.
+func WithReportThreshold(n int) Option {
+	return func(a *appStruct) {
+		a.adApp.reportThreshold = n
+	}
+}
+
+func (a *adApp) ReportAd(ctx context.Context, adID int64, reason ads.ReportReason, comment string) (*ads.Report, error) {
+	actor, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	if !reason.Valid() {
+		return nil, fmt.Errorf("%w: unknown reason %q", ErrBadRequest, reason)
+	}
+	comment = strings.TrimSpace(comment)
+	if reason == ads.ReportOther && comment == "" {
+		return nil, fmt.Errorf("%w: reason other needs a comment", ErrBadRequest)
+	}
+	if utf8.RuneCountInString(comment) > ads.MaxReportComment {
+		return nil, fmt.Errorf("%w: comment is longer than %d characters", ErrBadRequest, ads.MaxReportComment)
+	}
+
+	ad, err := a.repository.GetAd(ctx, adID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no such ad", ErrBadRequest)
+	}
+	if ad.AuthorID == actor.UserID {
+		return nil, fmt.Errorf("%w: authors cannot report their own ads", ErrBadRequest)
+	}
+	if !ad.Published() {
+		return nil, fmt.Errorf("%w: ad is not published", ErrBadRequest)
+	}
+
+	report := ads.Report{AdID: adID, ReporterID: actor.UserID, Reason: reason, Comment: comment, Status: ads.ReportOpen, CreateDate: a.now()}
+	if _, err := a.repository.AddReport(ctx, &report); err != nil {
+		if errors.Is(err, ads.ErrAlreadyReported) {
+			return nil, fmt.Errorf("%w: %s", ErrBadRequest, err.Error())
+		}
+		return nil, err
+	}
+
+	if err := a.hideReported(ctx, ad); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// hideReported sends ad back to review when it has reportThreshold open
+// reports, held there until a moderator publishes it again.
+func (a *adApp) hideReported(ctx context.Context, ad *ads.Ad) error {
+	if a.reportThreshold <= 0 {
+		return nil
+	}
+	open := ads.ReportOpen
+	reports, err := a.repository.ListReports(ctx, ads.ReportQuery{AdID: &ad.ID, Status: &open, Limit: a.reportThreshold})
+	if err != nil {
+		return err
+	}
+	if len(reports) < a.reportThreshold {
+		return nil
+	}
+
+	_, err = a.hold(ctx, ad, ads.StatusPendingReview, "")
+	if errors.Is(err, ErrBadRequest) {
+		// another report has hidden it meanwhile
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Println("ad hidden by reports", ad.ID, "open reports", len(reports))
+	return nil
+}
+
+// ListReports lists the reports for moderators, ads.DefaultLimit at a time
+// unless q.Limit says otherwise.
+func (a *adApp) ListReports(ctx context.Context, q ads.ReportQuery) (*ads.ReportList, error) {
+	if err := authorize(ctx, ActionModerateAd, 0); err != nil {
+		return nil, err
+	}
+
+	if q.Limit < 0 || q.Limit > ads.MaxLimit {
+		return nil, fmt.Errorf("%w: limit must be from 1 to %d", ErrBadRequest, ads.MaxLimit)
+	}
+	if q.Limit == 0 {
+		q.Limit = ads.DefaultLimit
+	}
+	if q.Status != nil && !q.Status.Valid() {
+		return nil, fmt.Errorf("%w: unknown report status %q", ErrBadRequest, *q.Status)
+	}
+	if q.Cursor != "" {
+		afterID, err := strconv.ParseInt(q.Cursor, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrBadRequest, ads.ErrInvalidCursor.Error())
+		}
+		q.AfterID = &afterID
+	}
+
+	limit := q.Limit
+	q.Limit++
+	reports, err := a.repository.ListReports(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	list := &ads.ReportList{Reports: reports}
+	if len(reports) > limit {
+		list.Reports = reports[:limit]
+		list.NextCursor = strconv.FormatInt(reports[limit-1].ID, 10)
+	}
+	return list, nil
+}
+
+// ResolveReport upholds or dismisses an open report. Upholding takes the ad
+// down, rejecting it when it is under review, and upholds the other open
+// reports of the ad too. Dismissing leaves the ad as it is, so that an ad
+// hidden by reports is published again by ApproveAd.
+func (a *adApp) ResolveReport(ctx context.Context, reportID int64, status ads.ReportStatus) (*ads.Report, error) {
+	actor, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+	if err := authorize(ctx, ActionModerateAd, 0); err != nil {
+		return nil, err
+	}
+	if status != ads.ReportUpheld && status != ads.ReportDismissed {
+		return nil, fmt.Errorf("%w: resolution must be %s or %s", ErrBadRequest, ads.ReportUpheld, ads.ReportDismissed)
+	}
+
+	report, err := a.repository.GetReport(ctx, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no such report", ErrBadRequest)
+	}
+	if report.Status != ads.ReportOpen {
+		return nil, fmt.Errorf("%w: %s", ErrBadRequest, ads.ErrReportResolved.Error())
+	}
+
+	only := &reportID
+	if status == ads.ReportUpheld {
+		only = nil
+	}
+	resolved, err := a.repository.ResolveReports(ctx, report.AdID, only, status, actor.UserID)
+	if errors.Is(err, ads.ErrReportResolved) {
+		return nil, fmt.Errorf("%w: %s", ErrBadRequest, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	var result *ads.Report
+	for _, r := range resolved {
+		if r.ID == reportID {
+			result = r
+		}
+	}
+	if result == nil {
+		return nil, fmt.Errorf("%w: %s", ErrBadRequest, ads.ErrReportResolved.Error())
+	}
+
+	if status == ads.ReportUpheld {
+		if err := a.takeDownReported(ctx, report); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// takeDownReported archives the ad of an upheld report, or rejects it with
+// the reason of the report when it is under review.
+func (a *adApp) takeDownReported(ctx context.Context, report *ads.Report) error {
+	ad, err := a.repository.GetAd(ctx, report.AdID)
+	if err != nil {
+		return err
+	}
+	switch ad.Status {
+	case ads.StatusPublished:
+		_, err = a.hold(ctx, ad, ads.StatusArchived, "")
+	case ads.StatusPendingReview:
+		_, err = a.hold(ctx, ad, ads.StatusRejected, fmt.Sprintf("reported as %s", report.Reason))
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"ads/internal/ads"
+)
+
+// ListRevisions lists the revisions of an ad, the first one first, to its
+// author and to moderators.
+func (a *adApp) ListRevisions(ctx context.Context, adID int64) ([]*ads.Revision, error) {
+	if err := a.authorizeHistory(ctx, adID); err != nil {
+		return nil, err
+	}
+	return a.repository.ListRevisions(ctx, adID)
+}
+
+func (a *adApp) GetRevision(ctx context.Context, adID int64, number int) (*ads.Revision, error) {
+	if err := a.authorizeHistory(ctx, adID); err != nil {
+		return nil, err
+	}
+	rev, err := a.repository.GetRevision(ctx, adID, number)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no such revision", ErrBadRequest)
+	}
+	return rev, nil
+}
+
+// RestoreRevision puts the content of an older revision back into the ad as
+// its next revision. The content is screened again and the version checked
+// as in any update.
+func (a *adApp) RestoreRevision(ctx context.Context, adID int64, number int, version *int64) (*ads.Ad, error) {
+	actor, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ad, err := a.repository.GetAd(ctx, adID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no such ad", ErrBadRequest)
+	}
+	if err := authorize(ctx, ActionUpdateAd, ad.AuthorID); err != nil {
+		return nil, err
+	}
+	rev, err := a.repository.GetRevision(ctx, adID, number)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no such revision", ErrBadRequest)
+	}
+
+	edit := *rev
+	edit.EditorID = actor.UserID
+	edit.RestoredFrom = &rev.Number
+	return a.update(ctx, ad, &edit, version)
+}
+
+// authorizeHistory checks that the caller may read the revisions of an ad.
+func (a *adApp) authorizeHistory(ctx context.Context, adID int64) error {
+	if _, ok := PrincipalFromContext(ctx); !ok {
+		return ErrUnauthorized
+	}
+	ad, err := a.repository.GetAd(ctx, adID)
+	if err != nil {
+		return fmt.Errorf("%w: no such ad", ErrBadRequest)
+	}
+	return authorize(ctx, ActionViewAdHistory, ad.AuthorID)
+}
@@ -0,0 +1,243 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+
+	"ads/internal/ads"
+)
+
+// defaultDuplicateMinLength is the shortest text the duplicate rule compares
+// unless it says otherwise, so that texts like "for sale" are no duplicates.
+const defaultDuplicateMinLength = 30
+
+// linkPattern finds the links in the text of an ad.
+var linkPattern = regexp.MustCompile(`(?i)(?:https?://|www\.)\S+`)
+
+// RuleKind is what a screening rule looks for in an ad.
+type RuleKind string
+
+const (
+	RuleTerms     RuleKind = "terms"     // banned words and phrases, ignoring case
+	RuleRegexp    RuleKind = "regexp"    // matches of Pattern
+	RuleLinks     RuleKind = "links"     // http, https and www links
+	RuleDuplicate RuleKind = "duplicate" // published ads of others containing the whole text
+)
+
+// RuleAction is what a screening rule does to an ad it matches.
+type RuleAction string
+
+const (
+	RuleReject RuleAction = "reject" // the ad is not saved
+	RuleFlag   RuleAction = "flag"   // the ad waits for a moderator before it is published
+	RuleScore  RuleAction = "score"  // the score is only added up and logged
+)
+
+// ScreeningRule is a rule the ads are screened by when they are created or
+// updated. It matches an ad when it finds more than Max of what it looks
+// for in the title and the text, and then adds Score to the screening of
+// the ad whatever its action.
+type ScreeningRule struct {
+	Name    string     `json:"name"`
+	Kind    RuleKind   `json:"kind"`
+	Action  RuleAction `json:"action"`
+	Score   float64    `json:"score,omitempty"`
+	Max     int        `json:"max,omitempty"`
+	Terms   []string   `json:"terms,omitempty"`
+	Pattern string     `json:"pattern,omitempty"`
+	// MinLength is the shortest text the duplicate rule compares.
+	MinLength int `json:"min_length,omitempty"`
+}
+
+// ParseScreeningRules reads rules written as a JSON array of ScreeningRule.
+func ParseScreeningRules(data []byte) ([]ScreeningRule, error) {
+	var rules []ScreeningRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("screening rules: %w", err)
+	}
+	return rules, nil
+}
+
+// screeningRule is a ScreeningRule ready to count its matches in an ad.
+type screeningRule struct {
+	ScreeningRule
+	re    *regexp.Regexp
+	terms [][]string // the terms as sequences of words, see words
+}
+
+// screening holds the rules in use, swapped as a whole on reload.
+type screening struct {
+	rules atomic.Pointer[[]screeningRule]
+}
+
+// SetScreeningRules replaces the screening rules, none when rules is empty.
+// On an invalid rule the rules in use are kept.
+func (a *adApp) SetScreeningRules(rules []ScreeningRule) error {
+	compiled := make([]screeningRule, 0, len(rules))
+	names := map[string]bool{}
+	for _, rule := range rules {
+		if rule.Name == "" || strings.Contains(rule.Name, ",") {
+			return fmt.Errorf("screening rule %q: name must be set and have no commas", rule.Name)
+		}
+		if names[rule.Name] {
+			return fmt.Errorf("screening rule %q: name is taken", rule.Name)
+		}
+		names[rule.Name] = true
+
+		r, err := compileRule(rule)
+		if err != nil {
+			return fmt.Errorf("screening rule %q: %w", rule.Name, err)
+		}
+		compiled = append(compiled, r)
+	}
+
+	a.screening.rules.Store(&compiled)
+	log.Println("screening rules set:", len(compiled))
+	return nil
+}
+
+func compileRule(rule ScreeningRule) (screeningRule, error) {
+	r := screeningRule{ScreeningRule: rule}
+	switch rule.Action {
+	case RuleReject, RuleFlag, RuleScore:
+	default:
+		return r, fmt.Errorf("unknown action %q", rule.Action)
+	}
+	if rule.Max < 0 || rule.Score < 0 || rule.MinLength < 0 {
+		return r, fmt.Errorf("max, score and min_length must not be negative")
+	}
+
+	switch rule.Kind {
+	case RuleTerms:
+		for _, term := range rule.Terms {
+			if words := words(term); len(words) > 0 {
+				r.terms = append(r.terms, words)
+			}
+		}
+		if len(r.terms) == 0 {
+			return r, fmt.Errorf("terms are empty")
+		}
+	case RuleRegexp:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return r, err
+		}
+		r.re = re
+	case RuleLinks:
+		r.re = linkPattern
+	case RuleDuplicate:
+		if r.MinLength == 0 {
+			r.MinLength = defaultDuplicateMinLength
+		}
+	default:
+		return r, fmt.Errorf("unknown kind %q", rule.Kind)
+	}
+	return r, nil
+}
+
+// words splits s into lowercase words, so that a term is found in a text
+// only as whole words.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// countTerm counts the places in text where the words of term follow one
+// another.
+func countTerm(text []string, term []string) int {
+	n := 0
+	for i := 0; i+len(term) <= len(text); i++ {
+		if equalWords(text[i:i+len(term)], term) {
+			n++
+		}
+	}
+	return n
+}
+
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// screen runs the screening rules on ad, new or as an edit leaves it. A
+// rejecting rule fails it with ErrBadRequest.
+func (a *adApp) screen(ctx context.Context, ad *ads.Ad) (ads.Screening, error) {
+	var result ads.Screening
+	rules := a.screening.rules.Load()
+	if rules == nil {
+		return result, nil
+	}
+
+	var flags []string
+	for _, rule := range *rules {
+		n, err := a.countMatches(ctx, rule, ad)
+		if err != nil {
+			return result, err
+		}
+		if n <= rule.Max {
+			continue
+		}
+		log.Println("screening rule", rule.Name, "matched ad", ad.ID, "of user", ad.AuthorID, "times", n, "score", rule.Score)
+
+		switch rule.Action {
+		case RuleReject:
+			return result, fmt.Errorf("%w: ad breaks the rule %q", ErrBadRequest, rule.Name)
+		case RuleFlag:
+			flags = append(flags, rule.Name)
+		}
+		result.Score += rule.Score
+	}
+	result.Flags = strings.Join(flags, ",")
+	return result, nil
+}
+
+func (a *adApp) countMatches(ctx context.Context, rule screeningRule, ad *ads.Ad) (int, error) {
+	text := ad.Title + "\n" + ad.Text
+	switch rule.Kind {
+	case RuleTerms:
+		text := words(text)
+		n := 0
+		for _, term := range rule.terms {
+			n += countTerm(text, term)
+		}
+		return n, nil
+	case RuleRegexp, RuleLinks:
+		return len(rule.re.FindAllStringIndex(text, -1)), nil
+	case RuleDuplicate:
+		return a.countDuplicates(ctx, rule, ad)
+	}
+	return 0, nil
+}
+
+// countDuplicates counts the published ads of other authors whose title or
+// text contains the text of ad, ignoring case, up to one more than the rule
+// allows. The author's own ads, the ad itself included, are no copies.
+func (a *adApp) countDuplicates(ctx context.Context, rule screeningRule, ad *ads.Ad) (int, error) {
+	text := strings.TrimSpace(ad.Text)
+	if utf8.RuneCountInString(text) < rule.MinLength {
+		return 0, nil
+	}
+
+	limit := rule.Max + 1
+	if limit > ads.MaxLimit {
+		limit = ads.MaxLimit
+	}
+	published := true
+	list, err := a.repository.Find(ctx, ads.Query{Text: text, Published: &published, OtherAuthorID: &ad.AuthorID, Page: ads.Page{Limit: limit}})
+	if err != nil {
+		return 0, err
+	}
+	return len(list.Ads), nil
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"ads/internal/user"
+)
+
+var errSessionRevoked = fmt.Errorf("%w: session is revoked", ErrUnauthorized)
+var errRefreshTokenReused = fmt.Errorf("%w: refresh token was already used", ErrUnauthorized)
+
+// RefreshToken exchanges a refresh token for a new pair. Every refresh token
+// can be used once; presenting a used one again revokes its whole family,
+// since either the client or a thief holds a stolen copy.
+func (a *authApp) RefreshToken(ctx context.Context, refreshToken string) (*Tokens, error) {
+	s, err := a.repository.GetSessionByTokenHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown refresh token", ErrUnauthorized)
+	}
+
+	if s.RevokedAt != nil {
+		return nil, a.revokeReusedFamily(ctx, s)
+	}
+	if !s.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: refresh token expired", ErrUnauthorized)
+	}
+
+	ok, err := a.repository.RevokeSession(ctx, s.ID)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		// a concurrent refresh with the same token got there first
+		return nil, a.revokeReusedFamily(ctx, s)
+	}
+
+	// the role may have changed since the previous token
+	u, err := a.repository.GetUser(ctx, s.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnauthorized, err.Error())
+	}
+
+	return a.issueTokens(ctx, u, s.FamilyID)
+}
+
+// SignOut revokes the session of the caller's access token.
+func (a *authApp) SignOut(ctx context.Context) error {
+	actor, ok := PrincipalFromContext(ctx)
+	if !ok || actor.SessionID == "" {
+		return ErrUnauthorized
+	}
+
+	return a.repository.RevokeSessionFamily(ctx, actor.SessionID)
+}
+
+// RevokeUserSessions signs the user out everywhere.
+func (a *authApp) RevokeUserSessions(ctx context.Context, userID int64) error {
+	if err := authorize(ctx, ActionRevokeUserSessions, userID); err != nil {
+		return err
+	}
+
+	return a.repository.RevokeUserSessions(ctx, userID)
+}
+
+func (a *authApp) revokeReusedFamily(ctx context.Context, s *user.Session) error {
+	log.Println("refresh token reuse, revoking session family of user", s.UserID)
+	if err := a.repository.RevokeSessionFamily(ctx, s.FamilyID); err != nil {
+		return err
+	}
+	return errRefreshTokenReused
+}
+
+// issueTokens stores a new refresh token in the family and signs an access token for it.
+func (a *authApp) issueTokens(ctx context.Context, u *user.User, familyID string) (*Tokens, error) {
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	_, err = a.repository.CreateSession(ctx, &user.Session{
+		UserID:    u.UserID,
+		FamilyID:  familyID,
+		TokenHash: hashRefreshToken(refreshToken),
+		CreatedAt: now,
+		ExpiresAt: now.Add(a.refreshTokenTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := a.signAccessToken(u, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Tokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// hashRefreshToken keeps refresh tokens out of the database. The tokens are
+// random, so a fast unsalted hash is enough.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
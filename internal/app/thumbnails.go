@@ -0,0 +1,180 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"ads/internal/ads"
+	"ads/internal/thumbnail"
+)
+
+// thumbnailQueue is how many uploads can wait for their renditions. The
+// uploads past it get theirs from retryRenditions.
+const thumbnailQueue = 64
+
+// renditionRetryDelay is how old an image must be for retryRenditions to
+// make its missing renditions, so that it does not race the workers still
+// making them.
+const renditionRetryDelay = time.Minute
+
+type thumbnailJob struct {
+	adID  int64
+	image ads.Image
+	data  []byte
+}
+
+// thumbnailer makes the renditions of uploaded images in the background.
+type thumbnailer struct {
+	specs   []ads.RenditionSpec
+	workers int
+	jobs    chan thumbnailJob
+	wg      sync.WaitGroup
+
+	// mu guards closed, set once jobs is closed
+	mu     sync.Mutex
+	closed bool
+}
+
+// WithThumbnails makes the renditions of specs for every uploaded image,
+// by so many workers in the background.
+func WithThumbnails(specs []ads.RenditionSpec, workers int) Option {
+	return func(a *appStruct) {
+		if workers < 1 {
+			workers = 1
+		}
+		a.adApp.thumbnails = &thumbnailer{specs: specs, workers: workers}
+	}
+}
+
+func (a *adApp) startThumbnails() {
+	t := a.thumbnails
+	if t == nil {
+		return
+	}
+	t.jobs = make(chan thumbnailJob, thumbnailQueue)
+	for i := 0; i < t.workers; i++ {
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			for job := range t.jobs {
+				a.makeRenditions(job)
+			}
+		}()
+	}
+
+	// the renditions of the uploads the last run did not get to
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		a.retryRenditions(context.Background())
+	}()
+}
+
+// Close stops the sweeper, waits for the renditions being made, the
+// retried ones too, and stops the workers.
+func (a *adApp) Close() error {
+	t := a.thumbnails
+	if t != nil {
+		t.mu.Lock()
+		if !t.closed {
+			t.closed = true
+			close(t.jobs)
+		}
+		t.mu.Unlock()
+	}
+	a.stopSweeper()
+	if t != nil {
+		t.wg.Wait()
+	}
+	return nil
+}
+
+// queueRenditions hands an uploaded image to the workers without waiting
+// for them. An image they can't take now gets its renditions from
+// retryRenditions.
+func (a *adApp) queueRenditions(adID int64, image *ads.Image, data []byte) {
+	t := a.thumbnails
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		log.Println("thumbnail workers are stopped, renditions of image", image.ID, "are retried later")
+		return
+	}
+	select {
+	case t.jobs <- thumbnailJob{adID: adID, image: *image, data: data}:
+	default:
+		log.Println("thumbnail queue is full, renditions of image", image.ID, "are retried later")
+	}
+}
+
+// retryRenditions makes the renditions missing from the images uploaded
+// renditionRetryDelay ago or earlier, reading the images back from the
+// blob store.
+func (a *adApp) retryRenditions(ctx context.Context) {
+	t := a.thumbnails
+	if t == nil || a.blobs == nil {
+		return
+	}
+	names := make([]string, 0, len(t.specs))
+	for _, spec := range t.specs {
+		names = append(names, spec.Name)
+	}
+
+	images, err := a.repository.ImagesLackingRenditions(ctx, names, time.Now().UTC().Add(-renditionRetryDelay))
+	if err != nil {
+		log.Println("error find images lacking renditions", err)
+		return
+	}
+	for _, image := range images {
+		data, err := a.blobs.Get(ctx, image.Key)
+		if err != nil {
+			log.Println("error read image", image.ID, err)
+			continue
+		}
+		a.makeRenditions(thumbnailJob{adID: image.AdID, image: *image, data: data})
+	}
+}
+
+// makeRenditions stores the renditions the image lacks one by one,
+// stopping when the image turns out to be deleted.
+func (a *adApp) makeRenditions(job thumbnailJob) {
+	ctx := context.Background()
+	for _, spec := range a.thumbnails.specs {
+		if job.image.Rendition(spec.Name) != nil {
+			continue
+		}
+		result, err := thumbnail.Render(job.data, spec)
+		if err != nil {
+			log.Println("error render image", job.image.ID, spec.Name, err)
+			return
+		}
+
+		key := ads.RenditionKey(job.image.Key, spec)
+		if err := a.blobs.Put(ctx, key, spec.ContentType(), bytes.NewReader(result.Data), int64(len(result.Data))); err != nil {
+			log.Println("error store rendition", job.image.ID, spec.Name, err)
+			continue
+		}
+
+		rendition := ads.Rendition{
+			ImageID:     job.image.ID,
+			Name:        spec.Name,
+			Key:         key,
+			URL:         a.blobs.URL(key),
+			ContentType: spec.ContentType(),
+			Width:       result.Width,
+			Height:      result.Height,
+			Size:        int64(len(result.Data)),
+		}
+		if _, err := a.repository.AddRendition(ctx, job.adID, &rendition); err != nil {
+			a.blobs.Delete(ctx, key)
+			log.Println("error add rendition", job.image.ID, spec.Name, err)
+			return
+		}
+	}
+}
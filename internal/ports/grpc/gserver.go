@@ -2,13 +2,19 @@ package grpc
 
 import (
 	"context"
+	"ads/internal/ads"
 	"ads/internal/app"
+	"errors"
 	"log"
+	"strings"
+	"time"
 
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	status "google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type gRPCServer interface {
@@ -21,58 +27,452 @@ type gRPCServerStruct struct {
 }
 
 func (g *gRPCServerStruct) CreateAd(ctx context.Context, req *CreateAdRequest) (*AdResponse, error) {
-	if err := g.A.CheckUser(ctx, req.GetUserId()); err != nil {
+	if err := checkDeprecatedUserID(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+	principal, _ := app.PrincipalFromContext(ctx)
+	if err := g.A.CheckUser(ctx, principal.UserID); err != nil {
 		log.Println("not found user in db for create ad ", err)
 		return nil, status.Error(codes.NotFound, "User not found")
 	}
-	ad, err := g.A.CreateAd(ctx, req.GetTitle(), req.GetText(), req.GetUserId())
+	city := req.GetCity()
+	place, err := placeFromRequest(req.Lat, req.Lon, &city)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := g.A.CreateAd(ctx, req.GetTitle(), req.GetText(), req.GetCategoryId(), req.GetPrice(), req.GetCurrency(), place)
 	if err != nil {
 		log.Println("error in create ad ", err)
 		return nil, status.Error(codes.InvalidArgument, "error create ad")
 	}
 	log.Printf("user %v && create ad %v \n", ad.AuthorID, ad.ID)
-	return &AdResponse{AuthorId: ad.AuthorID, Id: ad.ID, Published: ad.Published, Title: ad.Title, Text: ad.Text}, nil
+	return newAdResponse(ad), nil
 }
 
 func (g *gRPCServerStruct) ChangeAdStatus(ctx context.Context, req *ChangeAdStatusRequest) (*AdResponse, error) {
-	ad, err := g.A.ChangeAdStatus(ctx, req.GetAdId(), req.GetPublished(), req.GetUserId())
+	if err := checkDeprecatedUserID(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+	ad, err := g.A.ChangeAdStatus(ctx, req.GetAdId(), req.GetPublished())
 	if err != nil { 
 		log.Println("error in change status: ", err)
-		return nil, status.Error(codes.InvalidArgument, "error change status")
+		return nil, statusError(err, codes.InvalidArgument, "error change status")
 	}
-	log.Println("change ad status: adID ", ad.ID, " published: ", ad.Published)
-	return &AdResponse{AuthorId: ad.AuthorID, Id: ad.ID, Published: ad.Published, Title: ad.Title, Text: ad.Text}, nil
+	log.Println("change ad status: adID ", ad.ID, " status: ", ad.Status)
+	return newAdResponse(ad), nil
 }
 
 func (g *gRPCServerStruct) UpdateAd(ctx context.Context, req *UpdateAdRequest) (*AdResponse, error) {
-	ad, err := g.A.UpdateAd(ctx, req.GetUserId(), req.GetTitle(), req.GetText(), req.GetAdId())
+	if err := checkDeprecatedUserID(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+	place, err := placeFromRequest(req.Lat, req.Lon, req.City)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := g.A.UpdateAd(ctx, req.GetTitle(), req.GetText(), req.GetPrice(), req.GetCurrency(), place, req.ExpectedVersion, req.GetAdId())
 	if err != nil {
 		log.Println("error in update ad ", err) 
-		return nil, status.Error(codes.InvalidArgument, "error update ad")
+		return nil, statusError(err, codes.InvalidArgument, "error update ad")
 	}
 	log.Println("update ad ", ad)
-	return &AdResponse{AuthorId: ad.AuthorID, Id: ad.ID, Published: ad.Published, Title: ad.Title, Text: ad.Text}, nil
+	return newAdResponse(ad), nil
+}
+
+func (g *gRPCServerStruct) RenewAd(ctx context.Context, req *RenewAdRequest) (*AdResponse, error) {
+	ad, err := g.A.RenewAd(ctx, req.GetAdId())
+	if err != nil {
+		log.Println("error in renew ad ", err)
+		return nil, statusError(err, codes.InvalidArgument, "error renew ad")
+	}
+	log.Println("renew ad ", ad.ID)
+	return newAdResponse(ad), nil
 }
 
-func (g *gRPCServerStruct) ListAds(ctx context.Context, req *emptypb.Empty) (*ListAdResponse, error) {
-	ads, err := g.A.ListAds(ctx)
+func (g *gRPCServerStruct) ModerationQueue(ctx context.Context, req *ModerationQueueRequest) (*ModerationQueueResponse, error) {
+	page := ads.Page{Sort: ads.Sort(req.GetSort()), Limit: int(req.GetLimit()), Cursor: req.GetCursor()}
+	list, err := g.A.ModerationQueue(ctx, page)
+	if err != nil {
+		log.Println("error in moderation queue ", err)
+		return nil, statusError(err, codes.InvalidArgument, "error moderation queue")
+	}
+	var queue []*ModerationAd
+	for _, ad := range list.Ads {
+		queue = append(queue, &ModerationAd{
+			Ad:             newAdResponse(ad),
+			ScreeningScore: ad.Screening.Score,
+			ScreeningFlags: ad.Screening.Flags,
+		})
+	}
+	return &ModerationQueueResponse{List: queue, NextCursor: list.NextCursor}, nil
+}
+
+func (g *gRPCServerStruct) ApproveAd(ctx context.Context, req *ApproveAdRequest) (*AdResponse, error) {
+	ad, err := g.A.ApproveAd(ctx, req.GetAdId())
+	if err != nil {
+		log.Println("error in approve ad ", err)
+		return nil, statusError(err, codes.InvalidArgument, "error approve ad")
+	}
+	log.Println("approve ad ", ad.ID)
+	return newAdResponse(ad), nil
+}
+
+func (g *gRPCServerStruct) RejectAd(ctx context.Context, req *RejectAdRequest) (*AdResponse, error) {
+	ad, err := g.A.RejectAd(ctx, req.GetAdId(), req.GetReason())
+	if err != nil {
+		log.Println("error in reject ad ", err)
+		return nil, statusError(err, codes.InvalidArgument, "error reject ad")
+	}
+	log.Println("reject ad ", ad.ID)
+	return newAdResponse(ad), nil
+}
+
+func (g *gRPCServerStruct) ReportAd(ctx context.Context, req *ReportAdRequest) (*ReportResponse, error) {
+	report, err := g.A.ReportAd(ctx, req.GetAdId(), ads.ReportReason(req.GetReason()), req.GetComment())
+	if err != nil {
+		log.Println("error in report ad ", err)
+		return nil, statusError(err, codes.InvalidArgument, "error report ad")
+	}
+	log.Println("report ad ", report.AdID, " report ", report.ID)
+	return newReportResponse(report), nil
+}
+
+func (g *gRPCServerStruct) ListReports(ctx context.Context, req *ListReportsRequest) (*ListReportsResponse, error) {
+	q := ads.ReportQuery{AdID: req.AdId, Limit: int(req.GetLimit()), Cursor: req.GetCursor()}
+	switch req.GetStatus() {
+	case "":
+		open := ads.ReportOpen
+		q.Status = &open
+	case "any":
+	default:
+		status := ads.ReportStatus(req.GetStatus())
+		q.Status = &status
+	}
+
+	list, err := g.A.ListReports(ctx, q)
+	if err != nil {
+		log.Println("error in list reports ", err)
+		return nil, statusError(err, codes.InvalidArgument, "error list reports")
+	}
+	response := &ListReportsResponse{NextCursor: list.NextCursor}
+	for _, report := range list.Reports {
+		response.Reports = append(response.Reports, newReportResponse(report))
+	}
+	return response, nil
+}
+
+func (g *gRPCServerStruct) ResolveReport(ctx context.Context, req *ResolveReportRequest) (*ReportResponse, error) {
+	report, err := g.A.ResolveReport(ctx, req.GetReportId(), ads.ReportStatus(req.GetResolution()))
+	if err != nil {
+		log.Println("error in resolve report ", err)
+		return nil, statusError(err, codes.InvalidArgument, "error resolve report")
+	}
+	log.Println("resolve report ", report.ID, " ", report.Status)
+	return newReportResponse(report), nil
+}
+
+func (g *gRPCServerStruct) ListRevisions(ctx context.Context, req *ListRevisionsRequest) (*ListRevisionsResponse, error) {
+	revisions, err := g.A.ListRevisions(ctx, req.GetAdId())
+	if err != nil {
+		log.Println("error in list revisions ", err)
+		return nil, statusError(err, codes.InvalidArgument, "error list revisions")
+	}
+	response := &ListRevisionsResponse{}
+	for _, revision := range revisions {
+		response.Revisions = append(response.Revisions, newRevisionResponse(revision))
+	}
+	return response, nil
+}
+
+func (g *gRPCServerStruct) GetRevision(ctx context.Context, req *GetRevisionRequest) (*RevisionResponse, error) {
+	revision, err := g.A.GetRevision(ctx, req.GetAdId(), int(req.GetNumber()))
+	if err != nil {
+		log.Println("error in get revision ", err)
+		return nil, statusError(err, codes.InvalidArgument, "error get revision")
+	}
+	return newRevisionResponse(revision), nil
+}
+
+func (g *gRPCServerStruct) RestoreRevision(ctx context.Context, req *RestoreRevisionRequest) (*AdResponse, error) {
+	ad, err := g.A.RestoreRevision(ctx, req.GetAdId(), int(req.GetNumber()), req.ExpectedVersion)
+	if err != nil {
+		log.Println("error in restore revision ", err)
+		return nil, statusError(err, codes.InvalidArgument, "error restore revision")
+	}
+	log.Println("restore revision ", req.GetNumber(), " of ad ", ad.ID)
+	return newAdResponse(ad), nil
+}
+
+func newRevisionResponse(revision *ads.Revision) *RevisionResponse {
+	response := &RevisionResponse{
+		Id:         revision.ID,
+		AdId:       revision.AdID,
+		Number:     int32(revision.Number),
+		EditorId:   revision.EditorID,
+		Title:      revision.Title,
+		Text:       revision.Text,
+		Price:      revision.Price,
+		Currency:   revision.Currency,
+		Lat:        revision.Lat,
+		Lon:        revision.Lon,
+		City:       revision.City,
+		CreateDate: timestamppb.New(revision.CreateDate),
+	}
+	if revision.RestoredFrom != nil {
+		restoredFrom := int32(*revision.RestoredFrom)
+		response.RestoredFrom = &restoredFrom
+	}
+	for _, change := range revision.Diff {
+		response.Diff = append(response.Diff, &Change{Field: change.Field, Old: change.Old, New: change.New})
+	}
+	return response
+}
+
+func newReportResponse(report *ads.Report) *ReportResponse {
+	response := &ReportResponse{
+		Id:         report.ID,
+		AdId:       report.AdID,
+		ReporterId: report.ReporterID,
+		Reason:     string(report.Reason),
+		Comment:    report.Comment,
+		Status:     string(report.Status),
+		CreateDate: timestamppb.New(report.CreateDate),
+		ResolvedBy: report.ResolvedBy,
+	}
+	if report.ResolveDate != nil {
+		response.ResolveDate = timestamppb.New(*report.ResolveDate)
+	}
+	return response
+}
+
+func (g *gRPCServerStruct) ListAds(ctx context.Context, req *ListAdsRequest) (*ListAdResponse, error) {
+	q, err := queryFromRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	list, err := g.A.FindAds(ctx, q)
 	if err != nil {
 		log.Println("error in list ads ", err) 
-		return nil, status.Error(codes.InvalidArgument, "error list ads")
+		if errors.Is(err, app.ErrCategoryNotFound) {
+			return nil, status.Error(codes.NotFound, "category not found")
+		}
+		return nil, statusError(err, codes.InvalidArgument, "error list ads")
 	}
 	var adsResponse []*AdResponse
-	for _, ad := range ads {
-		adResponse := &AdResponse{
-			AuthorId: ad.AuthorID,
-			Id: ad.ID,
-			Published: ad.Published,
-			Title: ad.Title,
-			Text: ad.Text,
-		}
-		adsResponse = append(adsResponse, adResponse)
+	for _, ad := range list.Ads {
+		adsResponse = append(adsResponse, newAdResponse(ad))
 	}
 	log.Println("got ads list")
-	return &ListAdResponse{List: adsResponse}, nil
+	return &ListAdResponse{List: adsResponse, NextCursor: list.NextCursor, Facets: facetsResponse(list.Facets)}, nil
+}
+
+func (g *gRPCServerStruct) SearchAds(ctx context.Context, req *SearchAdsRequest) (*ListAdResponse, error) {
+	filter := req.GetFilter()
+	if filter == nil {
+		filter = &ListAdsRequest{}
+	}
+	q, err := queryFromRequest(filter)
+	if err != nil {
+		return nil, err
+	}
+	q.Search = req.GetQuery()
+	list, err := g.A.SearchAds(ctx, q)
+	if err != nil {
+		log.Println("error in search ads ", err)
+		if errors.Is(err, app.ErrCategoryNotFound) {
+			return nil, status.Error(codes.NotFound, "category not found")
+		}
+		return nil, statusError(err, codes.InvalidArgument, "error search ads")
+	}
+	var adsResponse []*AdResponse
+	for _, ad := range list.Ads {
+		adsResponse = append(adsResponse, newAdResponse(ad))
+	}
+	log.Println("searched ads", q.Search)
+	return &ListAdResponse{List: adsResponse, NextCursor: list.NextCursor, Facets: facetsResponse(list.Facets)}, nil
+}
+
+// newAdResponse presents ad; rank and distance_km are set in the results of
+// a search and of a geo filter only.
+func newAdResponse(ad *ads.Ad) *AdResponse {
+	response := &AdResponse{
+		AuthorId:   ad.AuthorID,
+		Id:         ad.ID,
+		Published:  ad.Published(),
+		Status:     string(ad.Status),
+		RejectionReason: ad.RejectionReason,
+		Version:    ad.Version,
+		Title:      ad.Title,
+		Text:       ad.Text,
+		CategoryId: ad.CategoryID,
+		Price:      ad.Price,
+		Currency:   ad.Currency,
+		Lat:        ad.Lat,
+		Lon:        ad.Lon,
+		City:       ad.City,
+		Rank:       ad.Rank,
+		DistanceKm: ad.Distance,
+		Images:     imagesResponse(ad.Images),
+	}
+	if ad.ExpiresAt != nil {
+		response.ExpiresAt = timestamppb.New(*ad.ExpiresAt)
+	}
+	return response
+}
+
+func imagesResponse(images []*ads.Image) []*Image {
+	var result []*Image
+	for _, img := range images {
+		el := &Image{
+			Id:          img.ID,
+			Url:         img.URL,
+			ContentType: img.ContentType,
+			Size:        img.Size,
+			Position:    int32(img.Position),
+		}
+		for _, r := range img.Renditions {
+			el.Renditions = append(el.Renditions, &Rendition{
+				Name:        r.Name,
+				Url:         r.URL,
+				ContentType: r.ContentType,
+				Width:       int32(r.Width),
+				Height:      int32(r.Height),
+				Size:        r.Size,
+			})
+		}
+		result = append(result, el)
+	}
+	return result
+}
+
+func facetsResponse(f *ads.Facets) *Facets {
+	if f == nil {
+		return nil
+	}
+	result := &Facets{}
+	for _, c := range f.Categories {
+		result.Categories = append(result.Categories, &IdCount{Id: c.ID, Count: c.Count})
+	}
+	for _, c := range f.Authors {
+		result.Authors = append(result.Authors, &IdCount{Id: c.ID, Count: c.Count})
+	}
+	for _, b := range f.Prices {
+		result.Prices = append(result.Prices, &PriceBucket{Min: b.Min, Max: b.Max, Count: b.Count})
+	}
+	for _, m := range f.Months {
+		result.Months = append(result.Months, &MonthCount{Month: m.Month, Count: m.Count})
+	}
+	return result
+}
+
+func (g *gRPCServerStruct) SuggestAds(ctx context.Context, req *SuggestAdsRequest) (*SuggestAdsResponse, error) {
+	suggestions, err := g.A.SuggestAds(ctx, req.GetQuery(), int(req.GetLimit()))
+	if err != nil {
+		log.Println("error in suggest ads ", err)
+		return nil, status.Error(codes.InvalidArgument, "error suggest ads")
+	}
+	response := &SuggestAdsResponse{}
+	for _, s := range suggestions.Completions {
+		response.Completions = append(response.Completions, &Suggestion{Title: s.Title, Score: s.Score})
+	}
+	for _, s := range suggestions.Corrections {
+		response.Corrections = append(response.Corrections, &Suggestion{Title: s.Title, Score: s.Score})
+	}
+	log.Println("suggested for", req.GetQuery())
+	return response, nil
+}
+
+// placeFromRequest reads the place of an ad, nil when none of its fields is
+// set.
+func placeFromRequest(lat, lon *float64, city *string) (*ads.Place, error) {
+	if lat == nil && lon == nil && city == nil {
+		return nil, nil
+	}
+	if (lat == nil) != (lon == nil) {
+		return nil, status.Error(codes.InvalidArgument, "lat and lon go together")
+	}
+	place := &ads.Place{}
+	if lat != nil {
+		place.Location = &ads.Location{Lat: *lat, Lon: *lon}
+	}
+	if city != nil {
+		place.City = strings.TrimSpace(*city)
+	}
+	return place, nil
+}
+
+func queryFromRequest(req *ListAdsRequest) (ads.Query, error) {
+	q := ads.Query{
+		AuthorID:   req.AuthorId,
+		Text:       req.GetText(),
+		CategoryID: req.CategoryId,
+		Price:      ads.PriceFilter{MinPrice: req.MinPrice, MaxPrice: req.MaxPrice, Currency: req.GetCurrency()},
+		Page:       ads.Page{Limit: int(req.GetLimit()), Cursor: req.GetCursor(), Sort: ads.Sort(req.GetSort())},
+		Facets:     req.GetFacets(),
+		City:       req.GetCity(),
+	}
+
+	if (req.Lat == nil) != (req.Lon == nil) {
+		return q, status.Error(codes.InvalidArgument, "lat and lon go together")
+	}
+	if req.Lat != nil {
+		q.Geo.Near = &ads.Location{Lat: req.GetLat(), Lon: req.GetLon()}
+	}
+	q.Geo.RadiusKm = req.GetRadiusKm()
+	if b := req.GetBbox(); b != nil {
+		q.Geo.Box = &ads.Box{MinLat: b.GetMinLat(), MinLon: b.GetMinLon(), MaxLat: b.GetMaxLat(), MaxLon: b.GetMaxLon()}
+	}
+
+	published := req.GetPublished()
+	if req.GetStatus() != "" {
+		status := ads.Status(req.GetStatus())
+		q.Status = &status
+		if published == "" {
+			published = "any"
+		}
+	}
+	switch published {
+	case "", "true", "false":
+		isPublished := published != "false"
+		q.Published = &isPublished
+	case "any":
+	default:
+		return q, status.Error(codes.InvalidArgument, "published must be true, false or any")
+	}
+
+	if req.CreatedFrom != nil {
+		from := req.CreatedFrom.AsTime()
+		q.CreatedFrom = &from
+	}
+	if req.CreatedTo != nil {
+		to := req.CreatedTo.AsTime()
+		q.CreatedTo = &to
+	}
+	if req.UpdatedFrom != nil {
+		from := req.UpdatedFrom.AsTime()
+		q.UpdatedFrom = &from
+	}
+	if req.UpdatedTo != nil {
+		to := req.UpdatedTo.AsTime()
+		q.UpdatedTo = &to
+	}
+
+	if day := int(req.GetDay()); day != 0 {
+		loc, err := time.LoadLocation(req.GetTimeZone())
+		if err != nil {
+			return q, status.Error(codes.InvalidArgument, "time_zone must be an IANA time zone")
+		}
+		if day < 1 || day > 31 {
+			return q, status.Error(codes.InvalidArgument, "day must be from 1 to 31")
+		}
+		if q.CreatedFrom != nil || q.CreatedTo != nil {
+			return q, status.Error(codes.InvalidArgument, "day can't be combined with created_from or created_to")
+		}
+		from, to := ads.DayRange(day, time.Now().In(loc))
+		q.CreatedFrom, q.CreatedTo = &from, &to
+	}
+
+	return q, nil
 }
 
 func (g *gRPCServerStruct) CreateUser(ctx context.Context, req *CreateUserRequest) (*UserResponse, error) {
@@ -98,22 +498,141 @@ func (g *gRPCServerStruct) GetUser(ctx context.Context, req *GetUserRequest) (*U
 func (g *gRPCServerStruct) DeleteUser(ctx context.Context, req *DeleteUserRequest) (*emptypb.Empty, error) {
 	if err := g.A.DeleteUser(ctx, req.GetId()); err != nil {
 		log.Println("error :: ", err)
-		return nil, status.Error(codes.NotFound, "User not found")
+		return nil, statusError(err, codes.NotFound, "User not found")
 	}
 	log.Println("deleted user", req.GetId())
 	return &emptypb.Empty{}, nil
 }
 
 func (g *gRPCServerStruct) DeleteAd(ctx context.Context, req *DeleteAdRequest) (*emptypb.Empty, error) {
-	ad, err := g.A.DeleteAd(ctx, req.GetAuthorId(), req.GetAdId())
+	if err := checkDeprecatedUserID(ctx, req.GetAuthorId()); err != nil {
+		return nil, err
+	}
+	ad, err := g.A.DeleteAd(ctx, req.GetAdId())
 	if err != nil {
-		log.Println("err in deleteAd :: ", ad.ID)
-		return nil, status.Error(codes.NotFound, "ad not found")
+		log.Println("err in deleteAd :: ", err)
+		return nil, statusError(err, codes.NotFound, "ad not found")
 	}
 	log.Println("deleted ad : ", ad)
 	return &emptypb.Empty{}, nil
 }
 
+func (g *gRPCServerStruct) ListCategories(ctx context.Context, req *emptypb.Empty) (*ListCategoryResponse, error) {
+	categories, err := g.A.ListCategories(ctx)
+	if err != nil {
+		log.Println("error in list categories ", err)
+		return nil, status.Error(codes.Internal, "error list categories")
+	}
+	var list []*CategoryResponse
+	for _, c := range categories {
+		list = append(list, &CategoryResponse{Id: c.ID, ParentId: c.ParentID, Name: c.Name, Slug: c.Slug})
+	}
+	return &ListCategoryResponse{List: list}, nil
+}
+
+func (g *gRPCServerStruct) ListAdsByCategory(ctx context.Context, req *ListAdsByCategoryRequest) (*ListAdResponse, error) {
+	categoryID := req.GetCategoryId()
+	if req.GetSlug() != "" {
+		category, err := g.A.GetCategoryBySlug(ctx, req.GetSlug())
+		if err != nil {
+			return nil, status.Error(codes.NotFound, "category not found")
+		}
+		categoryID = category.ID
+	}
+
+	page := ads.Page{Limit: int(req.GetLimit()), Cursor: req.GetCursor(), Sort: ads.Sort(req.GetSort())}
+	list, err := g.A.ListAdsCategory(ctx, categoryID, page)
+	if err != nil {
+		log.Println("error in list ads by category ", err)
+		if errors.Is(err, app.ErrCategoryNotFound) {
+			return nil, status.Error(codes.NotFound, "category not found")
+		}
+		return nil, statusError(err, codes.InvalidArgument, "error list ads")
+	}
+	var adsResponse []*AdResponse
+	for _, ad := range list.Ads {
+		adsResponse = append(adsResponse, newAdResponse(ad))
+	}
+	log.Println("got ads list by category", categoryID)
+	return &ListAdResponse{List: adsResponse, NextCursor: list.NextCursor}, nil
+}
+
+// statusError reports a denied operation as PermissionDenied and any other
+// error with the given code and message.
+func statusError(err error, code codes.Code, msg string) error {
+	if errors.Is(err, app.ErrForbidden) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	if errors.Is(err, app.ErrPreconditionFailed) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(code, msg)
+}
+
+// checkDeprecatedUserID rejects a deprecated user id field that names
+// someone other than the authenticated caller.
+func checkDeprecatedUserID(ctx context.Context, userID int64) error {
+	p, _ := app.PrincipalFromContext(ctx)
+	if userID != 0 && userID != p.UserID {
+		log.Println("deprecated user id does not match the caller", userID, p.UserID)
+		return status.Error(codes.PermissionDenied, "user id does not match the caller")
+	}
+	return nil
+}
+
+// authMethods act on behalf of the caller and cannot be called anonymously.
+var authMethods = map[string]bool{
+	"/ad.AdService/CreateAd":       true,
+	"/ad.AdService/ChangeAdStatus": true,
+	"/ad.AdService/UpdateAd":       true,
+	"/ad.AdService/RenewAd":        true,
+	"/ad.AdService/ModerationQueue": true,
+	"/ad.AdService/ApproveAd":      true,
+	"/ad.AdService/RejectAd":       true,
+	"/ad.AdService/ReportAd":       true,
+	"/ad.AdService/ListReports":    true,
+	"/ad.AdService/ResolveReport":  true,
+	"/ad.AdService/ListRevisions":  true,
+	"/ad.AdService/GetRevision":    true,
+	"/ad.AdService/RestoreRevision": true,
+	"/ad.AdService/DeleteAd":       true,
+	"/ad.AdService/DeleteUser":     true,
+}
+
+// UnaryServerInterceptorAuth authenticates the "authorization: Bearer <token>"
+// metadata and puts the caller into the context.
+func UnaryServerInterceptorAuth(a app.App) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		token, ok := bearerToken(ctx)
+		if !ok {
+			if authMethods[info.FullMethod] {
+				return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+			}
+			return handler(ctx, req)
+		}
+
+		principal, err := a.ParseToken(ctx, token)
+		if err != nil {
+			log.Println("error authenticate", err)
+			return nil, status.Error(codes.Unauthenticated, "invalid token")
+		}
+
+		return handler(app.ContextWithPrincipal(ctx, principal), req)
+	}
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], "Bearer ") {
+		return "", false
+	}
+	return strings.TrimPrefix(values[0], "Bearer "), true
+}
+
 func UnaryServerInterceptorLogMethod(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
 	log.Println("<<<--- interceptor SERVER --->>> NAME METHOD: ", info.FullMethod)
 	h, err := handler(ctx, req)
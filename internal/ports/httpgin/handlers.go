@@ -1,13 +1,18 @@
 package httpgin
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"ads/internal/ads"
 	"ads/internal/app"
 	"ads/internal/user"
 )
@@ -22,13 +27,25 @@ func createAd(a app.App) gin.HandlerFunc {
 			return
 		}
 
-		if err := a.CheckUser(c, reqBody.UserID); err != nil {
+		if !checkDeprecatedUserID(c, reqBody.UserID) {
+			c.JSON(403, AdErrorResponse(app.ErrForbidden))
+			return
+		}
+
+		principal, _ := app.PrincipalFromContext(c.Request.Context())
+		if err := a.CheckUser(c, principal.UserID); err != nil {
 			log.Println("not found user in db. Need create/register user")
 			c.JSON(400, AdErrorResponse(err))
 			return
 		}
 
-		ad, err := a.CreateAd(c.Request.Context(), reqBody.Title, reqBody.Text, reqBody.UserID)
+		place, err := placeFromRequest(reqBody.Lat, reqBody.Lon, &reqBody.City)
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		ad, err := a.CreateAd(c.Request.Context(), reqBody.Title, reqBody.Text, *reqBody.CategoryID, reqBody.Price, reqBody.Currency, place)
 		if err != nil {
 			if errors.Is(err, app.ErrForbidden) {
 				c.JSON(403, AdErrorResponse(err))
@@ -43,6 +60,7 @@ func createAd(a app.App) gin.HandlerFunc {
 		}
 		log.Println("Success create ad", http.StatusOK, "id ad", ad.ID)
 		c.Status(http.StatusOK)
+		c.Header("ETag", etag(ad))
 		c.JSON(200, AdSuccessResponse(ad))
 		log.Default()
 	}
@@ -62,7 +80,12 @@ func changeAdStatus(a app.App) gin.HandlerFunc {
 			return
 		}
 
-		ad, err := a.ChangeAdStatus(c.Request.Context(), int64(adID), reqBody.Published, reqBody.UserID)
+		if !checkDeprecatedUserID(c, reqBody.UserID) {
+			c.JSON(403, AdErrorResponse(app.ErrForbidden))
+			return
+		}
+
+		ad, err := a.ChangeAdStatus(c.Request.Context(), int64(adID), reqBody.Published)
 		if err != nil {
 			if errors.Is(err, app.ErrForbidden) {
 				c.JSON(403, AdErrorResponse(err))
@@ -76,6 +99,7 @@ func changeAdStatus(a app.App) gin.HandlerFunc {
 			return
 		}
 		log.Println("Success change status ad", http.StatusOK, "id ad", ad.ID)
+		c.Header("ETag", etag(ad))
 		c.JSON(200, AdSuccessResponse(ad))
 	}
 }
@@ -96,10 +120,33 @@ func updateAd(a app.App) gin.HandlerFunc {
 			return
 		}
 
-		ad, err := a.UpdateAd(c.Request.Context(), reqBody.UserID, reqBody.Title, reqBody.Text, int64(adID))
+		if !checkDeprecatedUserID(c, reqBody.UserID) {
+			c.JSON(403, AdErrorResponse(app.ErrForbidden))
+			return
+		}
+
+		place, err := placeFromRequest(reqBody.Lat, reqBody.Lon, reqBody.City)
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		version, err := expectedVersion(c, reqBody.ExpectedVersion)
+		if err != nil {
+			if errors.Is(err, app.ErrPreconditionFailed) {
+				c.JSON(412, AdErrorResponse(err))
+			} else {
+				c.JSON(400, AdErrorResponse(err))
+			}
+			return
+		}
+
+		ad, err := a.UpdateAd(c.Request.Context(), reqBody.Title, reqBody.Text, reqBody.Price, reqBody.Currency, place, version, int64(adID))
 		if err != nil {
 			if errors.Is(err, app.ErrForbidden) {
 				c.JSON(403, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrPreconditionFailed) {
+				c.JSON(412, AdErrorResponse(err))
 			} else if errors.Is(err, app.ErrBadRequest) {
 				c.JSON(400, AdErrorResponse(err))
 			} else {
@@ -110,10 +157,45 @@ func updateAd(a app.App) gin.HandlerFunc {
 			return
 		}
 		log.Println("Success update ad", http.StatusOK, "id ad", ad.ID)
+		c.Header("ETag", etag(ad))
 		c.JSON(200, AdSuccessResponse(ad))
 	}
 }
 
+// etag is the entity tag of ad, its version.
+func etag(ad *ads.Ad) string {
+	return strconv.Quote(strconv.FormatInt(ad.Version, 10))
+}
+
+// expectedVersion reads the version of the ad an update is made to from the
+// If-Match header, one entity tag or "*" for any version, or else from the
+// expected_version field. It is nil when neither is given, and a weak tag
+// fails with ErrPreconditionFailed. The repository checks a single version,
+// so a list of entity tags, valid in HTTP, is refused with ErrBadRequest.
+func expectedVersion(c *gin.Context, field *int64) (*int64, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return field, nil
+	}
+	// If-Match compares strongly, and a weak tag never matches
+	if strings.HasPrefix(header, "W/") {
+		return nil, fmt.Errorf("%w: If-Match has a weak entity tag", app.ErrPreconditionFailed)
+	}
+
+	tag, err := strconv.Unquote(header)
+	if err != nil || !strings.HasPrefix(header, `"`) {
+		return nil, fmt.Errorf("%w: If-Match must be one entity tag", app.ErrBadRequest)
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: If-Match is no version of an ad", app.ErrBadRequest)
+	}
+	if field != nil && *field != version {
+		return nil, fmt.Errorf("%w: If-Match and expected_version differ", app.ErrBadRequest)
+	}
+	return &version, nil
+}
+
 func getAd(a app.App, c *gin.Context, ad_id string) {
 		var reqBody getAdRequest
 		if err := c.Bind(&reqBody); err != nil {
@@ -131,6 +213,10 @@ func getAd(a app.App, c *gin.Context, ad_id string) {
 
 		ad, err := a.GetAd(c.Request.Context(), int64(adID))
 		if err != nil {
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+				return
+			}
 			if errors.Is(err, app.ErrBadRequest) {
 				c.JSON(400, AdErrorResponse(err))
 			} 
@@ -140,84 +226,314 @@ func getAd(a app.App, c *gin.Context, ad_id string) {
 			return
 		}
 		log.Println("Success get ad", http.StatusOK, "id ad", ad.ID)
+		c.Header("ETag", etag(ad))
 		c.JSON(200, AdSuccessResponse(ad))
 }
 
 
-func listAds(a app.App, c *gin.Context) {
-		var reqBody adsRequest
-		if err := c.Bind(&reqBody); err != nil {
-			c.JSON(400, AdErrorResponse(err))
-			log.Println("error get ads", err)
-			return
-		}
-		ads, err := a.ListAds(c)
-		if err != nil {
-			if errors.Is(err, app.ErrBadRequest) {
-				c.JSON(400, AdErrorResponse(err))
+// searchAds runs a full-text search in q, or title as the old API named it,
+// with the filters of findAds. The best results go first unless a sort is
+// given.
+func searchAds(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		runQuery(a, c, func(ctx context.Context, q ads.Query) (*ads.List, error) {
+			if q.Search == "" {
+				q.Search = c.Query("title")
 			}
-			c.JSON(200, AdErrorResponse(err))
-			log.Println("error get ads", err)
-			return
-		}
-		log.Println("Success get ads", http.StatusOK)
-		c.JSON(200, AdsSuccessResponse(ads))
+			return a.SearchAds(ctx, q)
+		})
+	}
 }
 
-func searchAdByName(a app.App) gin.HandlerFunc {
+// suggestAds offers completions and corrections of the search query q from
+// the titles of published ads, at most limit of each.
+func suggestAds(a app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		title := c.Param("title")
+		limit := 0
+		if value := c.Query("limit"); value != "" {
+			var err error
+			if limit, err = strconv.Atoi(value); err != nil {
+				c.JSON(400, AdErrorResponse(fmt.Errorf("%w: limit must be an integer", app.ErrBadRequest)))
+				log.Println("error suggest ads", err)
+				return
+			}
+		}
 
-		ads, err := a.SearchAdByName(c, title)
+		suggestions, err := a.SuggestAds(c, c.Query("q"), limit)
 		if err != nil {
-			c.JSON(200, AdErrorResponse(err))
-			log.Println("error get ads", err)
+			if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error suggest ads", err)
 			return
 		}
-		log.Println("Success search ad", http.StatusOK, "id ad", ads[0].ID)
-		c.JSON(200, AdsSuccessResponse(ads))
+		log.Println("Success suggest ads", http.StatusOK, "for", c.Query("q"))
+		c.JSON(200, SuggestionsSuccessResponse(suggestions))
 	}
 }
 
-func listAdsAuthor(a app.App, c *gin.Context) {
-	authorID, err := strconv.Atoi(c.Query("author_id"))
+// findAds lists the ads matching the query string, see queryFromRequest.
+func findAds(a app.App, c *gin.Context) {
+	runQuery(a, c, a.FindAds)
+}
+
+func runQuery(a app.App, c *gin.Context, find func(ctx context.Context, q ads.Query) (*ads.List, error)) {
+	q, err := queryFromRequest(a, c)
 	if err != nil {
-		c.JSON(400, AdErrorResponse(err))
+		if errors.Is(err, app.ErrCategoryNotFound) {
+			c.JSON(404, AdErrorResponse(err))
+		} else {
+			c.JSON(400, AdErrorResponse(err))
+		}
 		log.Println("error get ads", err)
 		return
 	}
-	ads, err := a.ListAdsAuthor(c, int64(authorID))
+	ads, err := find(c.Request.Context(), q)
 	if err != nil {
-		if errors.Is(err, app.ErrBadRequest) {
+		if errors.Is(err, app.ErrCategoryNotFound) {
+			c.JSON(404, AdErrorResponse(err))
+		} else if errors.Is(err, app.ErrForbidden) {
+			c.JSON(403, AdErrorResponse(err))
+		} else if errors.Is(err, app.ErrBadRequest) {
 			c.JSON(400, AdErrorResponse(err))
+		} else {
+			c.JSON(500, AdErrorResponse(err))
 		}
-		c.JSON(200, AdErrorResponse(err))
 		log.Println("error get ads", err)
 		return
 	}
-	log.Println("Success get ads filter: author", http.StatusOK, "author_id", ads[0].AuthorID)
+	log.Println("Success get ads", http.StatusOK, "found", len(ads.Ads))
 	c.JSON(200, AdsSuccessResponse(ads))
 }
 
-func listAdsDate(a app.App, c *gin.Context) {
-	d := c.Query("day")
-	day, err := strconv.Atoi(d)
-	if err != nil {
-		c.JSON(400, AdErrorResponse(err))
-		log.Println("error get ads", err)
-		return
+// queryFromRequest reads author_id, published ("true" by default, "false" or
+// "any"), the created and updated ranges, day, text, the full-text search q,
+// category (an id or a slug), the price filter, the geo filter, city, the
+// page and facets, which adds the facet counts of all the matching ads to
+// the response. sort=distance orders the ads by their distance_km from the
+// point, or from the center of the bbox.
+//
+// Range bounds are RFC 3339 times or dates; a date is taken at midnight in the
+// time zone tz (UTC by default), and a date in created_to or updated_to
+// includes the whole day. day is the old filter by the day of the month: it
+// stands for the latest such date in tz.
+func queryFromRequest(a app.App, c *gin.Context) (ads.Query, error) {
+	var q ads.Query
+	var err error
+	if q.Page, err = pageFromQuery(c); err != nil {
+		return q, err
+	}
+	if q.Price, err = priceFilterFromQuery(c); err != nil {
+		return q, err
+	}
+	if q.Geo, err = geoFilterFromQuery(c); err != nil {
+		return q, err
+	}
+	q.City = c.Query("city")
+
+	if value := c.Query("author_id"); value != "" {
+		authorID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return q, fmt.Errorf("%w: author_id must be an integer", app.ErrBadRequest)
+		}
+		q.AuthorID = &authorID
+	}
+
+	// filter=author and filter=date of the old API listed drafts too, which
+	// only moderators and the author see now
+	published := "true"
+	if filter := c.Query("filter"); (filter == "author" || filter == "date") && app.MayListUnpublished(c.Request.Context(), q.AuthorID) {
+		published = "any"
+	}
+	if value := c.Query("status"); value != "" {
+		status := ads.Status(value)
+		q.Status = &status
+		published = "any"
+	}
+	switch value := c.DefaultQuery("published", published); value {
+	case "true", "false":
+		isPublished := value == "true"
+		q.Published = &isPublished
+	case "any":
+	default:
+		return q, fmt.Errorf("%w: published must be true, false or any", app.ErrBadRequest)
+	}
+
+	loc := time.UTC
+	if value := c.Query("tz"); value != "" {
+		if loc, err = time.LoadLocation(value); err != nil {
+			return q, fmt.Errorf("%w: tz must be an IANA time zone", app.ErrBadRequest)
+		}
+	}
+
+	bounds := []struct {
+		name  string
+		bound **time.Time
+		end   bool
+	}{
+		{"created_from", &q.CreatedFrom, false},
+		{"created_to", &q.CreatedTo, true},
+		{"updated_from", &q.UpdatedFrom, false},
+		{"updated_to", &q.UpdatedTo, true},
+	}
+	for _, b := range bounds {
+		value := c.Query(b.name)
+		if value == "" {
+			continue
+		}
+		t, err := timeFromQuery(value, loc, b.end)
+		if err != nil {
+			return q, fmt.Errorf("%w: %s must be an RFC 3339 time or a date", app.ErrBadRequest, b.name)
+		}
+		*b.bound = &t
+	}
+
+	if value := c.Query("day"); value != "" {
+		day, err := strconv.Atoi(value)
+		if err != nil || day < 1 || day > 31 {
+			return q, fmt.Errorf("%w: day must be from 1 to 31", app.ErrBadRequest)
+		}
+		if q.CreatedFrom != nil || q.CreatedTo != nil {
+			return q, fmt.Errorf("%w: day can't be combined with created_from or created_to", app.ErrBadRequest)
+		}
+		from, to := ads.DayRange(day, time.Now().In(loc))
+		q.CreatedFrom, q.CreatedTo = &from, &to
+	}
+
+	q.Text = c.Query("text")
+	q.Search = c.Query("q")
+
+	if value := c.Query("facets"); value != "" {
+		if q.Facets, err = strconv.ParseBool(value); err != nil {
+			return q, fmt.Errorf("%w: facets must be true or false", app.ErrBadRequest)
+		}
+	}
+
+	if c.Query("category") != "" {
+		category, err := categoryFromQuery(a, c)
+		if err != nil {
+			return q, err
+		}
+		q.CategoryID = &category.ID
+	}
+
+	return q, nil
+}
+
+// timeFromQuery parses an RFC 3339 time, or a date at its midnight in loc. A
+// date ends at the next midnight when end is set.
+func timeFromQuery(value string, loc *time.Location, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
 	}
-	ads, err := a.ListAdsDate(c, int64(day))
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
 	if err != nil {
-		if errors.Is(err, app.ErrBadRequest) {
-			c.JSON(400, AdErrorResponse(err))
+		return t, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+func categoryFromQuery(a app.App, c *gin.Context) (*ads.Category, error) {
+	value := c.Query("category")
+	if value == "" {
+		return nil, fmt.Errorf("%w: category is required", app.ErrBadRequest)
+	}
+	if id, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return a.GetCategory(c, id)
+	}
+	return a.GetCategoryBySlug(c, value)
+}
+
+// priceFilterFromQuery reads min_price, max_price and currency.
+func priceFilterFromQuery(c *gin.Context) (ads.PriceFilter, error) {
+	filter := ads.PriceFilter{Currency: c.Query("currency")}
+	for name, bound := range map[string]**int64{"min_price": &filter.MinPrice, "max_price": &filter.MaxPrice} {
+		value := c.Query(name)
+		if value == "" {
+			continue
 		}
-		c.JSON(200, AdErrorResponse(err))
-		log.Println("error get ads", err)
-		return
+		price, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return filter, fmt.Errorf("%w: %s must be an integer", app.ErrBadRequest, name)
+		}
+		*bound = &price
 	}
-	log.Println("Success get ads filter: day", http.StatusOK, "day", ads[0].CreateDate.Day())
-	c.JSON(200, AdsSuccessResponse(ads))
+	return filter, nil
+}
+
+// placeFromRequest reads the place of an ad from a request, nil when none
+// of its fields is given.
+func placeFromRequest(lat, lon *float64, city *string) (*ads.Place, error) {
+	if lat == nil && lon == nil && city == nil {
+		return nil, nil
+	}
+	if (lat == nil) != (lon == nil) {
+		return nil, fmt.Errorf("%w: lat and lon go together", app.ErrBadRequest)
+	}
+	place := &ads.Place{}
+	if lat != nil {
+		place.Location = &ads.Location{Lat: *lat, Lon: *lon}
+	}
+	if city != nil {
+		place.City = strings.TrimSpace(*city)
+	}
+	return place, nil
+}
+
+// geoFilterFromQuery reads lat, lon and radius_km, the point to measure
+// from and how far from it to look, and bbox, see ads.ParseBox.
+func geoFilterFromQuery(c *gin.Context) (ads.GeoFilter, error) {
+	var filter ads.GeoFilter
+	coords := map[string]float64{}
+	for _, name := range []string{"lat", "lon", "radius_km"} {
+		value := c.Query(name)
+		if value == "" {
+			continue
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return filter, fmt.Errorf("%w: %s must be a number", app.ErrBadRequest, name)
+		}
+		coords[name] = number
+	}
+
+	lat, hasLat := coords["lat"]
+	lon, hasLon := coords["lon"]
+	if hasLat != hasLon {
+		return filter, fmt.Errorf("%w: lat and lon go together", app.ErrBadRequest)
+	}
+	if hasLat {
+		filter.Near = &ads.Location{Lat: lat, Lon: lon}
+	}
+	filter.RadiusKm = coords["radius_km"]
+
+	if value := c.Query("bbox"); value != "" {
+		box, err := ads.ParseBox(value)
+		if err != nil {
+			return filter, fmt.Errorf("%w: %v", app.ErrBadRequest, err)
+		}
+		filter.Box = &box
+	}
+	return filter, nil
+}
+
+// pageFromQuery reads limit, cursor and sort. The cursor comes from
+// next_cursor of the previous page and only fits the same sort.
+func pageFromQuery(c *gin.Context) (ads.Page, error) {
+	page := ads.Page{Cursor: c.Query("cursor"), Sort: ads.Sort(c.Query("sort"))}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil {
+			return page, fmt.Errorf("%w: limit must be an integer", app.ErrBadRequest)
+		}
+		page.Limit = limit
+	}
+	return page, nil
 }
 
 func getAds(a app.App) gin.HandlerFunc {
@@ -227,17 +543,7 @@ func getAds(a app.App) gin.HandlerFunc {
 			getAd(a, c, ad_id)
 			return
 		}
-		filter := c.Query("filter")
-		if filter == "author" {
-			listAdsAuthor(a, c)
-			return
-		}
-		if filter == "date" {
-			listAdsDate(a, c)
-			return
-		}
-		// default output ads
-		listAds(a, c)
+		findAds(a, c)
 	}
 }
 
@@ -258,137 +564,626 @@ func deleteAd(a app.App) gin.HandlerFunc {
 			return
 		}
 
-		ad, err := a.DeleteAd(c.Request.Context(), reqBody.UserID, int64(adID))
+		if !checkDeprecatedUserID(c, reqBody.UserID) {
+			c.JSON(403, AdErrorResponse(app.ErrForbidden))
+			return
+		}
+
+		ad, err := a.DeleteAd(c.Request.Context(), int64(adID))
 		if err != nil {
-			c.Status(500)
-			c.JSON(200, AdErrorResponse(err))
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
 			log.Println("error delete ad", err)
 			return
 		}
 		c.Status(http.StatusOK)
+		c.Header("ETag", etag(ad))
 		c.JSON(200, AdSuccessResponse(ad))
 		log.Default()
 		log.Println("Success delete ad", http.StatusOK, "ad id", ad.ID, "user id", ad.AuthorID)
 	}
 }
 
-func createUser(a app.App) gin.HandlerFunc {
+// renewAd publishes the ad for another term, whether it is live or has
+// expired.
+func renewAd(a app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var reqBody createUserRequest
-		err := c.Bind(&reqBody)
+		adID, err := strconv.Atoi(c.Param("ad_id"))
 		if err != nil {
 			c.JSON(400, AdErrorResponse(err))
-			log.Println("error create user", err)
 			return
 		}
 
-		u, err := a.CreateUser(c.Request.Context(), reqBody.NickName, reqBody.Email)
+		ad, err := a.RenewAd(c.Request.Context(), int64(adID))
 		if err != nil {
-			if errors.Is(err, app.ErrBadRequest) {
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrBadRequest) {
 				c.JSON(400, AdErrorResponse(err))
 			} else {
 				c.JSON(500, AdErrorResponse(err))
 			}
-			c.JSON(200, AdErrorResponse(err))
-			log.Println("error create user", err)
+			log.Println("error renew ad", err)
 			return
 		}
-		c.Status(http.StatusOK)
-		c.JSON(200, UserSuccessResponse(u))
-		log.Default()
-		log.Println("Success create user", http.StatusOK, "user id", u.UserID)
+		log.Println("Success renew ad", ad.ID, "until", ad.ExpiresAt)
+		c.Header("ETag", etag(ad))
+		c.JSON(200, AdSuccessResponse(ad))
 	}
 }
 
-func updateUser(a app.App) gin.HandlerFunc {
+func moderationQueue(a app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var reqBody updateUserRequest
-		if err := c.Bind(&reqBody); err != nil {
+		page, err := pageFromQuery(c)
+		if err != nil {
 			c.JSON(400, AdErrorResponse(err))
-			log.Println("error user update", err)
+			log.Println("error moderation queue", err)
 			return
 		}
 
-		user_id, err := strconv.Atoi(c.Param("user_id"))
+		list, err := a.ModerationQueue(c.Request.Context(), page)
+		if err != nil {
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error moderation queue", err)
+			return
+		}
+		log.Println("Success moderation queue", "found", len(list.Ads))
+		c.JSON(200, ModerationQueueSuccessResponse(list))
+	}
+}
+
+func approveAd(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, err := strconv.Atoi(c.Param("ad_id"))
 		if err != nil {
 			c.JSON(400, AdErrorResponse(err))
-			log.Println("error user update", err)
 			return
 		}
 
-		u, err := a.UpdateUser(c.Request.Context(), reqBody.NickName, reqBody.Email, int64(user_id), reqBody.Activate)
+		ad, err := a.ApproveAd(c.Request.Context(), int64(adID))
 		if err != nil {
-			if errors.Is(err, app.ErrNotFound) {
-				c.JSON(404, AdErrorResponse(err))
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
 			}
-			c.Status(500)
-			c.JSON(200, AdErrorResponse(err))
-			log.Println("error user update", err)
+			log.Println("error approve ad", err)
 			return
 		}
-		log.Println("Success update user", http.StatusOK, "user id", u.UserID)
-		c.JSON(200, UserSuccessResponse(u))
+		log.Println("Success approve ad", ad.ID)
+		c.Header("ETag", etag(ad))
+		c.JSON(200, AdSuccessResponse(ad))
 	}
 }
 
-func deleteUser(a app.App) gin.HandlerFunc {
+func rejectAd(a app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var reqBody deleteUserRequest
+		var reqBody rejectAdRequest
 		if err := c.Bind(&reqBody); err != nil {
-			c.JSON(400, ErrUser(err))
-			log.Println("error user delete", err)
+			c.JSON(400, AdErrorResponse(err))
+			log.Println("error reject ad", err)
 			return
 		}
 
-		user_id, err := strconv.Atoi(c.Param("user_id"))
+		adID, err := strconv.Atoi(c.Param("ad_id"))
 		if err != nil {
-			c.JSON(400, ErrUser(err))
-			log.Println("error user delete", err)
-			return
-		}
-
-		if err := a.CheckUser(c, int64(user_id)); err != nil {
-			log.Println("not found user in db. Need create/register user")
 			c.JSON(400, AdErrorResponse(err))
 			return
 		}
 
-		err = a.DeleteUser(c.Request.Context(), int64(user_id))
+		ad, err := a.RejectAd(c.Request.Context(), int64(adID), reqBody.Reason)
 		if err != nil {
-			c.Status(500)
-			c.JSON(200, ErrUser(err))
-			log.Println("error user delete", err)
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error reject ad", err)
 			return
 		}
-		log.Println("Success delete user", http.StatusOK, "user id", user_id)
-		c.JSON(200, DeleteUserSuccess(int64(user_id)))
+		log.Println("Success reject ad", ad.ID)
+		c.Header("ETag", etag(ad))
+		c.JSON(200, AdSuccessResponse(ad))
 	}
 }
 
-func getUser(a app.App) gin.HandlerFunc {
+func reportAd(a app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var reqBody getUserRequest
+		var reqBody reportAdRequest
 		if err := c.Bind(&reqBody); err != nil {
-			c.JSON(400, ErrUser(err))
-			log.Println("error user get", err)
+			c.JSON(400, AdErrorResponse(err))
+			log.Println("error report ad", err)
 			return
 		}
 
-		user_id, err := strconv.Atoi(c.Param("user_id"))
+		adID, err := strconv.Atoi(c.Param("ad_id"))
 		if err != nil {
-			c.JSON(400, ErrUser(err))
-			log.Println("error user get", err)
+			c.JSON(400, AdErrorResponse(err))
 			return
 		}
 
-		u, err := a.GetUser(c.Request.Context(), int64(user_id))
+		report, err := a.ReportAd(c.Request.Context(), int64(adID), reqBody.Reason, reqBody.Comment)
 		if err != nil {
-			if errors.Is(err, app.ErrNotFound) {
-				c.JSON(404, AdErrorResponse(err))
-			} 
-			c.Status(500)
-			c.JSON(200, ErrUser(err))
-			log.Println("error user get", err)
+			if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error report ad", err)
+			return
+		}
+		log.Println("Success report ad", report.AdID, "report", report.ID)
+		c.JSON(200, ReportSuccessResponse(report))
+	}
+}
+
+// listReports lists the open reports unless the status query says
+// otherwise, "any" for all of them.
+func listReports(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		q := ads.ReportQuery{Cursor: c.Query("cursor")}
+		if value := c.Query("limit"); value != "" {
+			limit, err := strconv.Atoi(value)
+			if err != nil {
+				c.JSON(400, AdErrorResponse(fmt.Errorf("%w: limit must be an integer", app.ErrBadRequest)))
+				return
+			}
+			q.Limit = limit
+		}
+		if value := c.Query("ad_id"); value != "" {
+			adID, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				c.JSON(400, AdErrorResponse(fmt.Errorf("%w: ad_id must be an integer", app.ErrBadRequest)))
+				return
+			}
+			q.AdID = &adID
+		}
+		if value := c.DefaultQuery("status", string(ads.ReportOpen)); value != "any" {
+			status := ads.ReportStatus(value)
+			q.Status = &status
+		}
+
+		list, err := a.ListReports(c.Request.Context(), q)
+		if err != nil {
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error list reports", err)
+			return
+		}
+		log.Println("Success list reports", "found", len(list.Reports))
+		c.JSON(200, ReportsSuccessResponse(list))
+	}
+}
+
+func resolveReport(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody resolveReportRequest
+		if err := c.Bind(&reqBody); err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			log.Println("error resolve report", err)
+			return
+		}
+
+		reportID, err := strconv.Atoi(c.Param("report_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		report, err := a.ResolveReport(c.Request.Context(), int64(reportID), reqBody.Resolution)
+		if err != nil {
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error resolve report", err)
+			return
+		}
+		log.Println("Success resolve report", report.ID, report.Status)
+		c.JSON(200, ReportSuccessResponse(report))
+	}
+}
+
+func listRevisions(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, err := strconv.Atoi(c.Param("ad_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		revisions, err := a.ListRevisions(c.Request.Context(), int64(adID))
+		if err != nil {
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error list revisions", err)
+			return
+		}
+		c.JSON(200, RevisionsSuccessResponse(revisions))
+	}
+}
+
+func getRevision(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, err := strconv.Atoi(c.Param("ad_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		revision, err := a.GetRevision(c.Request.Context(), int64(adID), number)
+		if err != nil {
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error get revision", err)
+			return
+		}
+		c.JSON(200, RevisionSuccessResponse(revision))
+	}
+}
+
+func restoreRevision(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, err := strconv.Atoi(c.Param("ad_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		// the body is optional, as restoring needs nothing else
+		var reqBody restoreRevisionRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.Bind(&reqBody); err != nil {
+				c.JSON(400, AdErrorResponse(err))
+				log.Println("error restore revision", err)
+				return
+			}
+		}
+		version, err := expectedVersion(c, reqBody.ExpectedVersion)
+		if err != nil {
+			if errors.Is(err, app.ErrPreconditionFailed) {
+				c.JSON(412, AdErrorResponse(err))
+			} else {
+				c.JSON(400, AdErrorResponse(err))
+			}
+			return
+		}
+
+		ad, err := a.RestoreRevision(c.Request.Context(), int64(adID), number, version)
+		if err != nil {
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrPreconditionFailed) {
+				c.JSON(412, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error restore revision", err)
+			return
+		}
+		log.Println("Success restore revision", number, "of ad", ad.ID)
+		c.Header("ETag", etag(ad))
+		c.JSON(200, AdSuccessResponse(ad))
+	}
+}
+
+// maxImagesBody bounds an upload request: as many images as an ad can have
+// and room for the multipart framing.
+const maxImagesBody = ads.MaxImages*ads.MaxImageSize + 1<<20
+
+// listImages lists the images of an ad to whoever may see the ad, see
+// app.App.GetAd.
+func listImages(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, err := strconv.Atoi(c.Param("ad_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		ad, err := a.GetAd(c.Request.Context(), int64(adID))
+		if err != nil {
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else {
+				c.JSON(404, AdErrorResponse(err))
+			}
+			log.Println("error list images", err)
+			return
+		}
+		c.JSON(200, ImagesSuccessResponse(ad.Images))
+	}
+}
+
+// uploadImages adds the files of the multipart field "image", one or more,
+// to an ad in the order they come. The files stored before a failing one
+// stay with the ad.
+func uploadImages(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, err := strconv.Atoi(c.Param("ad_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImagesBody)
+		form, err := c.MultipartForm()
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.JSON(413, AdErrorResponse(app.ErrImageTooLarge))
+			} else {
+				c.JSON(400, AdErrorResponse(err))
+			}
+			log.Println("error upload image", err)
+			return
+		}
+		files := form.File["image"]
+		if len(files) == 0 {
+			c.JSON(400, AdErrorResponse(fmt.Errorf("%w: no image in the form", app.ErrBadRequest)))
+			return
+		}
+
+		var ad *ads.Ad
+		for _, header := range files {
+			if header.Size > ads.MaxImageSize {
+				imageError(c, app.ErrImageTooLarge)
+				return
+			}
+			file, err := header.Open()
+			if err != nil {
+				c.JSON(400, AdErrorResponse(err))
+				return
+			}
+			ad, err = a.AddImage(c.Request.Context(), int64(adID), file, header.Size)
+			file.Close()
+			if err != nil {
+				imageError(c, err)
+				log.Println("error upload image", err)
+				return
+			}
+		}
+		log.Println("Success upload images", http.StatusOK, "id ad", ad.ID)
+		c.Header("ETag", etag(ad))
+		c.JSON(200, AdSuccessResponse(ad))
+	}
+}
+
+func reorderImages(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody reorderImagesRequest
+		if err := c.Bind(&reqBody); err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		adID, err := strconv.Atoi(c.Param("ad_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		ad, err := a.ReorderImages(c.Request.Context(), int64(adID), reqBody.ImageIDs)
+		if err != nil {
+			imageError(c, err)
+			log.Println("error reorder images", err)
+			return
+		}
+		c.Header("ETag", etag(ad))
+		c.JSON(200, AdSuccessResponse(ad))
+	}
+}
+
+func deleteImage(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		adID, err := strconv.Atoi(c.Param("ad_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+		imageID, err := strconv.Atoi(c.Param("image_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		ad, err := a.DeleteImage(c.Request.Context(), int64(adID), int64(imageID))
+		if err != nil {
+			imageError(c, err)
+			log.Println("error delete image", err)
+			return
+		}
+		log.Println("Success delete image", http.StatusOK, "id ad", ad.ID, "id image", imageID)
+		c.Header("ETag", etag(ad))
+		c.JSON(200, AdSuccessResponse(ad))
+	}
+}
+
+func imageError(c *gin.Context, err error) {
+	if errors.Is(err, app.ErrForbidden) {
+		c.JSON(403, AdErrorResponse(err))
+	} else if errors.Is(err, app.ErrImageTooLarge) {
+		c.JSON(413, AdErrorResponse(err))
+	} else if errors.Is(err, app.ErrUnsupportedImage) {
+		c.JSON(415, AdErrorResponse(err))
+	} else if errors.Is(err, app.ErrImageNotFound) {
+		c.JSON(404, AdErrorResponse(err))
+	} else if errors.Is(err, app.ErrBadRequest) {
+		c.JSON(400, AdErrorResponse(err))
+	} else {
+		c.JSON(500, AdErrorResponse(err))
+	}
+}
+
+func createUser(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody createUserRequest
+		err := c.Bind(&reqBody)
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			log.Println("error create user", err)
+			return
+		}
+
+		u, err := a.CreateUser(c.Request.Context(), reqBody.NickName, reqBody.Email)
+		if err != nil {
+			if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			c.JSON(200, AdErrorResponse(err))
+			log.Println("error create user", err)
+			return
+		}
+		c.Status(http.StatusOK)
+		c.JSON(200, UserSuccessResponse(u))
+		log.Default()
+		log.Println("Success create user", http.StatusOK, "user id", u.UserID)
+	}
+}
+
+func updateUser(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody updateUserRequest
+		if err := c.Bind(&reqBody); err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			log.Println("error user update", err)
+			return
+		}
+
+		user_id, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			log.Println("error user update", err)
+			return
+		}
+
+		u, err := a.UpdateUser(c.Request.Context(), reqBody.NickName, reqBody.Email, int64(user_id), reqBody.Activate)
+		if err != nil {
+			log.Println("error user update", err)
+			if errors.Is(err, app.ErrNotFound) {
+				c.JSON(404, AdErrorResponse(err))
+				return
+			}
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+				return
+			}
+			c.Status(500)
+			c.JSON(200, AdErrorResponse(err))
+			return
+		}
+		log.Println("Success update user", http.StatusOK, "user id", u.UserID)
+		c.JSON(200, UserSuccessResponse(u))
+	}
+}
+
+func deleteUser(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody deleteUserRequest
+		if err := c.Bind(&reqBody); err != nil {
+			c.JSON(400, ErrUser(err))
+			log.Println("error user delete", err)
+			return
+		}
+
+		user_id, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			c.JSON(400, ErrUser(err))
+			log.Println("error user delete", err)
+			return
+		}
+
+		if err := a.CheckUser(c, int64(user_id)); err != nil {
+			log.Println("not found user in db. Need create/register user")
+			c.JSON(400, AdErrorResponse(err))
+			return
+		}
+
+		err = a.DeleteUser(c.Request.Context(), int64(user_id))
+		if err != nil {
+			log.Println("error user delete", err)
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+				return
+			}
+			c.Status(500)
+			c.JSON(200, ErrUser(err))
+			return
+		}
+		log.Println("Success delete user", http.StatusOK, "user id", user_id)
+		c.JSON(200, DeleteUserSuccess(int64(user_id)))
+	}
+}
+
+func getUser(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody getUserRequest
+		if err := c.Bind(&reqBody); err != nil {
+			c.JSON(400, ErrUser(err))
+			log.Println("error user get", err)
+			return
+		}
+
+		user_id, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			c.JSON(400, ErrUser(err))
+			log.Println("error user get", err)
+			return
+		}
+
+		u, err := a.GetUser(c.Request.Context(), int64(user_id))
+		if err != nil {
+			if errors.Is(err, app.ErrNotFound) {
+				c.JSON(404, AdErrorResponse(err))
+			} 
+			c.Status(500)
+			c.JSON(200, ErrUser(err))
+			log.Println("error user get", err)
 			return
 		}
 		log.Println("Success get user", http.StatusOK, "user id", u.UserID)
@@ -406,8 +1201,8 @@ func signUp(a app.App) gin.HandlerFunc {
 			return
 		}
 
-		id, err := a.CreateUserDb(user.UserDb{
-			Name: requestCreateUserDB.Name,
+		id, err := a.CreateUserDb(user.User{
+			NickName: requestCreateUserDB.Name,
 			Username: requestCreateUserDB.Username,
 			Password: requestCreateUserDB.Password,
 		})
@@ -427,3 +1222,234 @@ func signUp(a app.App) gin.HandlerFunc {
 		log.Println("Success create user", http.StatusOK, "user id", id)
 	}
 }
+
+func signIn(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody signInRequest
+		if err := c.Bind(&reqBody); err != nil {
+			c.JSON(400, ErrUser(err))
+			log.Println("error sign in", err)
+			return
+		}
+
+		tokens, err := a.GenerateToken(c.Request.Context(), reqBody.Username, reqBody.Password)
+		if err != nil {
+			if errors.Is(err, app.ErrUnauthorized) {
+				c.JSON(401, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error sign in", err)
+			return
+		}
+		log.Println("Success sign in", http.StatusOK, "username", reqBody.Username)
+		c.JSON(200, SignInSuccess(tokens))
+	}
+}
+
+func refresh(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody refreshRequest
+		if err := c.Bind(&reqBody); err != nil {
+			c.JSON(400, ErrUser(err))
+			log.Println("error refresh", err)
+			return
+		}
+
+		tokens, err := a.RefreshToken(c.Request.Context(), reqBody.RefreshToken)
+		if err != nil {
+			if errors.Is(err, app.ErrUnauthorized) {
+				c.JSON(401, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error refresh", err)
+			return
+		}
+		log.Println("Success refresh", http.StatusOK)
+		c.JSON(200, SignInSuccess(tokens))
+	}
+}
+
+func signOut(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := a.SignOut(c.Request.Context()); err != nil {
+			if errors.Is(err, app.ErrUnauthorized) {
+				c.JSON(401, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error sign out", err)
+			return
+		}
+		log.Println("Success sign out", http.StatusOK)
+		c.JSON(200, EmptySuccess())
+	}
+}
+
+func setUserRole(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody setUserRoleRequest
+		if err := c.Bind(&reqBody); err != nil {
+			c.JSON(400, ErrUser(err))
+			log.Println("error set user role", err)
+			return
+		}
+
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			c.JSON(400, ErrUser(err))
+			log.Println("error set user role", err)
+			return
+		}
+
+		u, err := a.SetUserRole(c.Request.Context(), int64(userID), user.Role(reqBody.Role))
+		if err != nil {
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrBadRequest) {
+				c.JSON(400, AdErrorResponse(err))
+			} else if errors.Is(err, app.ErrNotFound) {
+				c.JSON(404, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error set user role", err)
+			return
+		}
+		log.Println("Success set user role", http.StatusOK, "user id", u.UserID, "role", u.Role)
+		c.JSON(200, UserSuccessResponse(u))
+	}
+}
+
+func revokeUserSessions(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.Atoi(c.Param("user_id"))
+		if err != nil {
+			c.JSON(400, ErrUser(err))
+			log.Println("error revoke sessions", err)
+			return
+		}
+
+		if err := a.RevokeUserSessions(c.Request.Context(), int64(userID)); err != nil {
+			if errors.Is(err, app.ErrForbidden) {
+				c.JSON(403, AdErrorResponse(err))
+			} else {
+				c.JSON(500, AdErrorResponse(err))
+			}
+			log.Println("error revoke sessions", err)
+			return
+		}
+		log.Println("Success revoke sessions", http.StatusOK, "user id", userID)
+		c.JSON(200, EmptySuccess())
+	}
+}
+
+func listCategories(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		categories, err := a.ListCategories(c.Request.Context())
+		if err != nil {
+			c.JSON(500, AdErrorResponse(err))
+			log.Println("error list categories", err)
+			return
+		}
+		c.JSON(200, CategoriesSuccessResponse(categories))
+	}
+}
+
+func getCategory(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		categoryID, err := strconv.Atoi(c.Param("category_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			log.Println("error get category", err)
+			return
+		}
+
+		category, err := a.GetCategory(c.Request.Context(), int64(categoryID))
+		if err != nil {
+			c.JSON(404, AdErrorResponse(err))
+			log.Println("error get category", err)
+			return
+		}
+		c.JSON(200, CategorySuccessResponse(category))
+	}
+}
+
+func createCategory(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody categoryRequest
+		if err := c.Bind(&reqBody); err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			log.Println("error create category", err)
+			return
+		}
+
+		category, err := a.CreateCategory(c.Request.Context(), reqBody.ParentID, reqBody.Name, reqBody.Slug)
+		if err != nil {
+			categoryError(c, err)
+			log.Println("error create category", err)
+			return
+		}
+		log.Println("Success create category", http.StatusOK, "id category", category.ID)
+		c.JSON(200, CategorySuccessResponse(category))
+	}
+}
+
+func updateCategory(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var reqBody categoryRequest
+		if err := c.Bind(&reqBody); err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			log.Println("error update category", err)
+			return
+		}
+
+		categoryID, err := strconv.Atoi(c.Param("category_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			log.Println("error update category", err)
+			return
+		}
+
+		category, err := a.UpdateCategory(c.Request.Context(), int64(categoryID), reqBody.ParentID, reqBody.Name, reqBody.Slug)
+		if err != nil {
+			categoryError(c, err)
+			log.Println("error update category", err)
+			return
+		}
+		log.Println("Success update category", http.StatusOK, "id category", category.ID)
+		c.JSON(200, CategorySuccessResponse(category))
+	}
+}
+
+func deleteCategory(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		categoryID, err := strconv.Atoi(c.Param("category_id"))
+		if err != nil {
+			c.JSON(400, AdErrorResponse(err))
+			log.Println("error delete category", err)
+			return
+		}
+
+		if err := a.DeleteCategory(c.Request.Context(), int64(categoryID)); err != nil {
+			categoryError(c, err)
+			log.Println("error delete category", err)
+			return
+		}
+		log.Println("Success delete category", http.StatusOK, "id category", categoryID)
+		c.JSON(200, EmptySuccess())
+	}
+}
+
+func categoryError(c *gin.Context, err error) {
+	if errors.Is(err, app.ErrForbidden) {
+		c.JSON(403, AdErrorResponse(err))
+	} else if errors.Is(err, app.ErrBadRequest) {
+		c.JSON(400, AdErrorResponse(err))
+	} else if errors.Is(err, app.ErrCategoryNotFound) {
+		c.JSON(404, AdErrorResponse(err))
+	} else {
+		c.JSON(500, AdErrorResponse(err))
+	}
+}
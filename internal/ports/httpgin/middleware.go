@@ -0,0 +1,59 @@
+package httpgin
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"ads/internal/app"
+)
+
+var errNoToken = fmt.Errorf("%w: missing bearer token", app.ErrUnauthorized)
+
+// authMiddleware authenticates the bearer token and puts the caller into the request context.
+func authMiddleware(a app.App) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			c.AbortWithStatusJSON(401, AdErrorResponse(errNoToken))
+			return
+		}
+
+		principal, err := a.ParseToken(c.Request.Context(), strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			log.Println("error authenticate", err)
+			c.AbortWithStatusJSON(401, AdErrorResponse(err))
+			return
+		}
+
+		ctx := app.ContextWithPrincipal(c.Request.Context(), principal)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// optionalAuthMiddleware authenticates the bearer token when there is one,
+// and lets the anonymous callers through.
+func optionalAuthMiddleware(a app.App) gin.HandlerFunc {
+	auth := authMiddleware(a)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
+}
+
+// checkDeprecatedUserID reports whether the deprecated user_id of a request
+// body is either absent or names the authenticated caller.
+func checkDeprecatedUserID(c *gin.Context, userID *int64) bool {
+	if userID == nil {
+		return true
+	}
+	log.Println("deprecated user_id in request body", c.FullPath())
+	p, ok := app.PrincipalFromContext(c.Request.Context())
+	return ok && p.UserID == *userID
+}
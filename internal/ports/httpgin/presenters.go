@@ -6,13 +6,23 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"ads/internal/ads"
+	"ads/internal/app"
 	"ads/internal/user"
 )
 
 type createAdRequest struct {
-	Title  string `json:"title"`
-	Text   string `json:"text"`
-	UserID int64  `json:"user_id"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+	CategoryID *int64 `json:"category_id" binding:"required"`
+	// Price is in minor units of Currency, which defaults to RUB.
+	Price    int64  `json:"price"`
+	Currency string `json:"currency"`
+	// Lat and Lon are given together or not at all.
+	Lat  *float64 `json:"lat"`
+	Lon  *float64 `json:"lon"`
+	City string   `json:"city"`
+	// Deprecated: the author is the authenticated caller.
+	UserID *int64 `json:"user_id"`
 }
 
 type adResponse struct {
@@ -20,9 +30,52 @@ type adResponse struct {
 	Title     string `json:"title"`
 	Text      string `json:"text"`
 	AuthorID  int64  `json:"author_id"`
+	CategoryID int64 `json:"category_id"`
+	Price     int64  `json:"price"`
+	Currency  string `json:"currency"`
 	Published bool   `json:"published"`
+	Status    ads.Status `json:"status"`
+	RejectionReason string `json:"rejection_reason,omitempty"`
 	CreateDate time.Time `json:"create_date"`
 	UpdateDate time.Time `json:"update_date"`
+	Version    int64     `json:"version"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	Lat        *float64  `json:"lat"`
+	Lon        *float64  `json:"lon"`
+	City       string    `json:"city"`
+	Rank       float64   `json:"rank,omitempty"`
+	DistanceKm *float64  `json:"distance_km,omitempty"`
+	Images     []imageResponse `json:"images"`
+}
+
+// moderationAdResponse shows moderators how the screening rules scored an
+// ad; the other callers never see it.
+type moderationAdResponse struct {
+	adResponse
+	ScreeningScore float64 `json:"screening_score"`
+	ScreeningFlags string  `json:"screening_flags"`
+}
+
+type imageResponse struct {
+	ID          int64  `json:"id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Position    int    `json:"position"`
+	Renditions  []renditionResponse `json:"renditions"`
+}
+
+type renditionResponse struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int64  `json:"size"`
+}
+
+type reorderImagesRequest struct {
+	ImageIDs []int64 `json:"image_ids" binding:"required"`
 }
 
 type userDeleteResponse struct {
@@ -38,7 +91,8 @@ type getUserRequest struct {
 }
 
 type adDeleteRequest struct {
-	UserID int64 `json:"user_id"`
+	// Deprecated: the author is the authenticated caller.
+	UserID *int64 `json:"user_id"`
 }
 
 type adsRequest struct {
@@ -46,14 +100,75 @@ type adsRequest struct {
 }
 
 type changeAdStatusRequest struct {
-	Published bool  `json:"published"`
-	UserID    int64 `json:"user_id"`
+	Published bool `json:"published"`
+	// Deprecated: the author is the authenticated caller.
+	UserID *int64 `json:"user_id"`
+}
+
+type reportAdRequest struct {
+	Reason  ads.ReportReason `json:"reason" binding:"required"`
+	Comment string           `json:"comment"`
+}
+
+type resolveReportRequest struct {
+	Resolution ads.ReportStatus `json:"resolution" binding:"required"`
+}
+
+type reportResponse struct {
+	ID          int64            `json:"id"`
+	AdID        int64            `json:"ad_id"`
+	ReporterID  int64            `json:"reporter_id"`
+	Reason      ads.ReportReason `json:"reason"`
+	Comment     string           `json:"comment"`
+	Status      ads.ReportStatus `json:"status"`
+	CreateDate  time.Time        `json:"create_date"`
+	ResolvedBy  *int64           `json:"resolved_by"`
+	ResolveDate *time.Time       `json:"resolve_date"`
+}
+
+type revisionResponse struct {
+	ID           int64     `json:"id"`
+	AdID         int64     `json:"ad_id"`
+	Number       int       `json:"number"`
+	EditorID     int64     `json:"editor_id"`
+	Title        string    `json:"title"`
+	Text         string    `json:"text"`
+	Price        int64     `json:"price"`
+	Currency     string    `json:"currency"`
+	Lat          *float64  `json:"lat"`
+	Lon          *float64  `json:"lon"`
+	City         string    `json:"city"`
+	RestoredFrom *int      `json:"restored_from"`
+	Diff         ads.Diff  `json:"diff"`
+	CreateDate   time.Time `json:"create_date"`
+}
+
+type restoreRevisionRequest struct {
+	// ExpectedVersion fails the restore unless the ad is at it, as If-Match
+	// does.
+	ExpectedVersion *int64 `json:"expected_version"`
+}
+
+type rejectAdRequest struct {
+	Reason string `json:"reason" binding:"required"`
 }
 
 type updateAdRequest struct {
-	Title  string `json:"title"`
-	Text   string `json:"text"`
-	UserID int64  `json:"user_id"`
+	Title string `json:"title"`
+	Text  string `json:"text"`
+	// The price is left as is when Currency is empty.
+	Price    int64  `json:"price"`
+	Currency string `json:"currency"`
+	// The place is left as is when none of Lat, Lon and City is given, and
+	// replaced as a whole otherwise.
+	Lat  *float64 `json:"lat"`
+	Lon  *float64 `json:"lon"`
+	City *string  `json:"city"`
+	// ExpectedVersion fails the update unless the ad is at it, as If-Match
+	// does.
+	ExpectedVersion *int64 `json:"expected_version"`
+	// Deprecated: the author is the authenticated caller.
+	UserID *int64 `json:"user_id"`
 }
 
 type getAdRequest struct {
@@ -77,6 +192,11 @@ type createUserResponse struct {
 	NickName string `json:"nickname"`
 	Email   string `json:"email"`
 	Activate bool	`json:"activate"`
+	Role    string `json:"role"`
+}
+
+type setUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
 }
 
 type createUserDB struct {
@@ -86,6 +206,61 @@ type createUserDB struct {
 	Password string `json:"password" binding:"required"`
 }
 
+type signInRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type categoryRequest struct {
+	ParentID *int64 `json:"parent_id"`
+	Name     string `json:"name" binding:"required"`
+	Slug     string `json:"slug" binding:"required"`
+}
+
+type categoryResponse struct {
+	ID       int64  `json:"id"`
+	ParentID *int64 `json:"parent_id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+}
+
+type idCountResponse struct {
+	ID    int64 `json:"id"`
+	Count int64 `json:"count"`
+}
+
+type priceBucketResponse struct {
+	Min   int64  `json:"min"`
+	Max   *int64 `json:"max"`
+	Count int64  `json:"count"`
+}
+
+type monthCountResponse struct {
+	Month string `json:"month"`
+	Count int64  `json:"count"`
+}
+
+type facetsResponse struct {
+	Categories []idCountResponse     `json:"categories"`
+	Authors    []idCountResponse     `json:"authors"`
+	Prices     []priceBucketResponse `json:"prices"`
+	Months     []monthCountResponse  `json:"months"`
+}
+
+type suggestionResponse struct {
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+type suggestionsResponse struct {
+	Completions []suggestionResponse `json:"completions"`
+	Corrections []suggestionResponse `json:"corrections"`
+}
+
 func AdSuccessResponse(ad *ads.Ad) *gin.H {
 	return &gin.H{
 		"data": adResponse{
@@ -93,9 +268,20 @@ func AdSuccessResponse(ad *ads.Ad) *gin.H {
 			Title:     ad.Title,
 			Text:      ad.Text,
 			AuthorID:  ad.AuthorID,
-			Published: ad.Published,
+			CategoryID: ad.CategoryID,
+			Price:     ad.Price,
+			Currency:  ad.Currency,
+			Published: ad.Published(),
+			Status:    ad.Status,
+			RejectionReason: ad.RejectionReason,
 			CreateDate: ad.CreateDate,
 			UpdateDate: ad.UpdateDate,
+			Version:    ad.Version,
+			ExpiresAt:  ad.ExpiresAt,
+			Lat:        ad.Lat,
+			Lon:        ad.Lon,
+			City:       ad.City,
+			Images:     newImagesResponse(ad.Images),
 		},
 		"error": nil,
 	}
@@ -108,25 +294,116 @@ func AdErrorResponse(err error) *gin.H {
 	}
 }
 
-func AdsSuccessResponse(ads []*ads.Ad) *gin.H {
+func AdsSuccessResponse(list *ads.List) *gin.H {
 	result := []adResponse{}
-	for _, ad := range ads {
+	for _, ad := range list.Ads {
 		el := adResponse{
 			ID:        ad.ID,
 			Title:     ad.Title,
 			Text:      ad.Text,
 			AuthorID:  ad.AuthorID,
-			Published: ad.Published,
+			CategoryID: ad.CategoryID,
+			Price:     ad.Price,
+			Currency:  ad.Currency,
+			Published: ad.Published(),
+			Status:    ad.Status,
+			RejectionReason: ad.RejectionReason,
 			CreateDate: ad.CreateDate,
 			UpdateDate: ad.UpdateDate,
+			Version:    ad.Version,
+			ExpiresAt:  ad.ExpiresAt,
+			Lat:        ad.Lat,
+			Lon:        ad.Lon,
+			City:       ad.City,
+			Rank:       ad.Rank,
+			DistanceKm: ad.Distance,
+			Images:     newImagesResponse(ad.Images),
 		}
 		result = append(result, el)
 	}
+	response := gin.H{
+		"data":        result,
+		"next_cursor": list.NextCursor,
+	}
+	if list.Facets != nil {
+		response["facets"] = newFacetsResponse(list.Facets)
+	}
+	return &response
+}
+
+// ModerationQueueSuccessResponse is AdsSuccessResponse with the screening
+// of every ad.
+func ModerationQueueSuccessResponse(list *ads.List) *gin.H {
+	public := (*AdsSuccessResponse(list))["data"].([]adResponse)
+	result := []moderationAdResponse{}
+	for i, ad := range list.Ads {
+		result = append(result, moderationAdResponse{
+			adResponse:     public[i],
+			ScreeningScore: ad.Screening.Score,
+			ScreeningFlags: ad.Screening.Flags,
+		})
+	}
 	return &gin.H{
-		"data": result,
+		"data":        result,
+		"next_cursor": list.NextCursor,
+	}
+}
+
+func newImagesResponse(images []*ads.Image) []imageResponse {
+	result := []imageResponse{}
+	for _, img := range images {
+		el := imageResponse{
+			ID:          img.ID,
+			URL:         img.URL,
+			ContentType: img.ContentType,
+			Size:        img.Size,
+			Position:    img.Position,
+			Renditions:  []renditionResponse{},
+		}
+		for _, r := range img.Renditions {
+			el.Renditions = append(el.Renditions, renditionResponse{
+				Name:        r.Name,
+				URL:         r.URL,
+				ContentType: r.ContentType,
+				Width:       r.Width,
+				Height:      r.Height,
+				Size:        r.Size,
+			})
+		}
+		result = append(result, el)
+	}
+	return result
+}
+
+func ImagesSuccessResponse(images []*ads.Image) *gin.H {
+	return &gin.H{
+		"data":  newImagesResponse(images),
+		"error": nil,
 	}
 }
 
+func newFacetsResponse(f *ads.Facets) facetsResponse {
+	result := facetsResponse{
+		Categories: []idCountResponse{},
+		Authors:    []idCountResponse{},
+		Prices:     []priceBucketResponse{},
+		Months:     []monthCountResponse{},
+	}
+	for _, c := range f.Categories {
+		result.Categories = append(result.Categories, idCountResponse{ID: c.ID, Count: c.Count})
+	}
+	for _, c := range f.Authors {
+		result.Authors = append(result.Authors, idCountResponse{ID: c.ID, Count: c.Count})
+	}
+	for _, b := range f.Prices {
+		result.Prices = append(result.Prices, priceBucketResponse{Min: b.Min, Max: b.Max, Count: b.Count})
+	}
+	for _, m := range f.Months {
+		result.Months = append(result.Months, monthCountResponse{Month: m.Month, Count: m.Count})
+	}
+	return result
+}
+
 func UserSuccessResponse(u *user.User) *gin.H {
 	return &gin.H{
 		"data": createUserResponse{
@@ -134,6 +411,7 @@ func UserSuccessResponse(u *user.User) *gin.H {
 			NickName: u.NickName,
 			Email: u.Email,
 			Activate: u.Activate,
+			Role: string(u.Role),
 		},
 		"error": nil,
 	}
@@ -160,3 +438,119 @@ func CreateUserDbSuccess(id int) *gin.H {
 		"error": nil,
 	}
 }
+
+func SignInSuccess(tokens *app.Tokens) *gin.H {
+	return &gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"error":         nil,
+	}
+}
+
+func EmptySuccess() *gin.H {
+	return &gin.H{
+		"error": nil,
+	}
+}
+
+func CategorySuccessResponse(c *ads.Category) *gin.H {
+	return &gin.H{
+		"data":  categoryResponse{ID: c.ID, ParentID: c.ParentID, Name: c.Name, Slug: c.Slug},
+		"error": nil,
+	}
+}
+
+func CategoriesSuccessResponse(categories []*ads.Category) *gin.H {
+	result := []categoryResponse{}
+	for _, c := range categories {
+		result = append(result, categoryResponse{ID: c.ID, ParentID: c.ParentID, Name: c.Name, Slug: c.Slug})
+	}
+	return &gin.H{
+		"data":  result,
+		"error": nil,
+	}
+}
+
+func SuggestionsSuccessResponse(s *ads.Suggestions) *gin.H {
+	result := suggestionsResponse{Completions: []suggestionResponse{}, Corrections: []suggestionResponse{}}
+	for _, c := range s.Completions {
+		result.Completions = append(result.Completions, suggestionResponse{Title: c.Title, Score: c.Score})
+	}
+	for _, c := range s.Corrections {
+		result.Corrections = append(result.Corrections, suggestionResponse{Title: c.Title, Score: c.Score})
+	}
+	return &gin.H{
+		"data":  result,
+		"error": nil,
+	}
+}
+
+func newReportResponse(r *ads.Report) reportResponse {
+	return reportResponse{
+		ID:          r.ID,
+		AdID:        r.AdID,
+		ReporterID:  r.ReporterID,
+		Reason:      r.Reason,
+		Comment:     r.Comment,
+		Status:      r.Status,
+		CreateDate:  r.CreateDate,
+		ResolvedBy:  r.ResolvedBy,
+		ResolveDate: r.ResolveDate,
+	}
+}
+
+func ReportSuccessResponse(r *ads.Report) *gin.H {
+	return &gin.H{
+		"data":  newReportResponse(r),
+		"error": nil,
+	}
+}
+
+func ReportsSuccessResponse(list *ads.ReportList) *gin.H {
+	result := []reportResponse{}
+	for _, r := range list.Reports {
+		result = append(result, newReportResponse(r))
+	}
+	return &gin.H{
+		"data":        result,
+		"next_cursor": list.NextCursor,
+		"error":       nil,
+	}
+}
+
+func newRevisionResponse(r *ads.Revision) revisionResponse {
+	return revisionResponse{
+		ID:           r.ID,
+		AdID:         r.AdID,
+		Number:       r.Number,
+		EditorID:     r.EditorID,
+		Title:        r.Title,
+		Text:         r.Text,
+		Price:        r.Price,
+		Currency:     r.Currency,
+		Lat:          r.Lat,
+		Lon:          r.Lon,
+		City:         r.City,
+		RestoredFrom: r.RestoredFrom,
+		Diff:         r.Diff,
+		CreateDate:   r.CreateDate,
+	}
+}
+
+func RevisionSuccessResponse(r *ads.Revision) *gin.H {
+	return &gin.H{
+		"data":  newRevisionResponse(r),
+		"error": nil,
+	}
+}
+
+func RevisionsSuccessResponse(revisions []*ads.Revision) *gin.H {
+	result := []revisionResponse{}
+	for _, r := range revisions {
+		result = append(result, newRevisionResponse(r))
+	}
+	return &gin.H{
+		"data":  result,
+		"error": nil,
+	}
+}
@@ -7,17 +7,44 @@ import (
 )
 
 func AppRouter(r *gin.RouterGroup, a app.App) {
-	r.GET("/ads", getAds(a))
-	r.GET("/ads/search", searchAdByName(a))
-	r.PUT("/ads/:ad_id/status", changeAdStatus(a))
-	r.PUT("/ads/:ad_id", updateAd(a))
-	r.POST("/ads", createAd(a))
-	r.DELETE("/ads/delete/:ad_id", deleteAd(a))
+	r.GET("/ads", optionalAuthMiddleware(a), getAds(a))
+	r.GET("/ads/search", optionalAuthMiddleware(a), searchAds(a))
+	r.GET("/ads/suggest", suggestAds(a))
+	r.PUT("/ads/:ad_id/status", authMiddleware(a), changeAdStatus(a))
+	r.PUT("/ads/:ad_id", authMiddleware(a), updateAd(a))
+	r.POST("/ads/:ad_id/renew", authMiddleware(a), renewAd(a))
+	r.POST("/ads/:ad_id/reports", authMiddleware(a), reportAd(a))
+	r.GET("/ads/:ad_id/revisions", authMiddleware(a), listRevisions(a))
+	r.GET("/ads/:ad_id/revisions/:number", authMiddleware(a), getRevision(a))
+	r.POST("/ads/:ad_id/revisions/:number/restore", authMiddleware(a), restoreRevision(a))
+	r.POST("/ads", authMiddleware(a), createAd(a))
+	r.DELETE("/ads/delete/:ad_id", authMiddleware(a), deleteAd(a))
+	r.GET("/ads/:ad_id/images", optionalAuthMiddleware(a), listImages(a))
+	r.POST("/ads/:ad_id/images", authMiddleware(a), uploadImages(a))
+	r.PUT("/ads/:ad_id/images/order", authMiddleware(a), reorderImages(a))
+	r.DELETE("/ads/:ad_id/images/:image_id", authMiddleware(a), deleteImage(a))
+
+	r.GET("/moderation/ads", authMiddleware(a), moderationQueue(a))
+	r.POST("/moderation/ads/:ad_id/approve", authMiddleware(a), approveAd(a))
+	r.POST("/moderation/ads/:ad_id/reject", authMiddleware(a), rejectAd(a))
+	r.GET("/moderation/reports", authMiddleware(a), listReports(a))
+	r.POST("/moderation/reports/:report_id/resolve", authMiddleware(a), resolveReport(a))
+
+	r.GET("/categories", listCategories(a))
+	r.GET("/categories/:category_id", getCategory(a))
+	r.POST("/categories", authMiddleware(a), createCategory(a))
+	r.PUT("/categories/:category_id", authMiddleware(a), updateCategory(a))
+	r.DELETE("/categories/:category_id", authMiddleware(a), deleteCategory(a))
 
 	r.POST("/user", createUser(a))
-	r.PUT("/user/update/:user_id", updateUser(a))
-	r.DELETE("/user/delete/:user_id", deleteUser(a))
+	r.PUT("/user/update/:user_id", authMiddleware(a), updateUser(a))
+	r.DELETE("/user/delete/:user_id", authMiddleware(a), deleteUser(a))
+	r.PUT("/user/role/:user_id", authMiddleware(a), setUserRole(a))
+	r.POST("/user/revoke-sessions/:user_id", authMiddleware(a), revokeUserSessions(a))
 	r.GET("/user/:user_id", getUser(a))
 
 	r.POST("/sign-up", signUp(a))
+	r.POST("/sign-in", signIn(a))
+	r.POST("/refresh", refresh(a))
+	r.POST("/sign-out", authMiddleware(a), signOut(a))
 }
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ads/internal/adapters/adrepo"
+	"ads/internal/adapters/userrepo"
+	"ads/internal/app"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignIn(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.signUp("Alex", "alex", "qwerty")
+	assert.NoError(t, err)
+
+	response, err := client.signIn("alex", "qwerty")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.Token)
+}
+
+func TestSignInWrongPassword(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.signUp("Alex", "alex", "qwerty")
+	assert.NoError(t, err)
+
+	_, err = client.signIn("alex", "qwerty1")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = client.signIn("gopher", "qwerty")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestParseToken(t *testing.T) {
+	ctx := context.Background()
+	users := userrepo.New()
+	a := app.NewApp(adrepo.New(), users, users, app.WithSigningKey([]byte("secret")))
+
+	id, err := a.CreateUserDb(user.User{NickName: "Alex", Username: "alex", Password: "qwerty"})
+	assert.NoError(t, err)
+
+	tokens, err := a.GenerateToken(ctx, "alex", "qwerty")
+	assert.NoError(t, err)
+
+	principal, err := a.ParseToken(ctx, tokens.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(id), principal.UserID)
+
+	other := app.NewApp(adrepo.New(), users, users, app.WithSigningKey([]byte("another secret")))
+	_, err = other.ParseToken(ctx, tokens.AccessToken)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+
+	_, err = a.ParseToken(ctx, tokens.AccessToken+"x")
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
+
+func TestParseTokenExpired(t *testing.T) {
+	ctx := context.Background()
+	users := userrepo.New()
+	a := app.NewApp(adrepo.New(), users, users, app.WithTokenTTL(-time.Minute))
+
+	_, err := a.CreateUserDb(user.User{NickName: "Alex", Username: "alex", Password: "qwerty"})
+	assert.NoError(t, err)
+
+	tokens, err := a.GenerateToken(ctx, "alex", "qwerty")
+	assert.NoError(t, err)
+
+	_, err = a.ParseToken(ctx, tokens.AccessToken)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
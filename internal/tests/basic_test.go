@@ -8,10 +8,10 @@ import (
 
 func TestCreateAd(t *testing.T) {
 	client := getTestClient()
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com")
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com")
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com")
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com")
 	assert.NoError(t, err)
 
 	response, err := client.createAd(1, "hello", "world")
@@ -25,10 +25,10 @@ func TestCreateAd(t *testing.T) {
 
 func TestChangeAdStatus(t *testing.T) {
 	client := getTestClient()
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com")
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com")
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com")
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com")
 	assert.NoError(t, err)
 
 	response, err := client.createAd(1, "hello", "world")
@@ -50,10 +50,10 @@ func TestChangeAdStatus(t *testing.T) {
 func TestUpdateAd(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com")
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com")
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com")
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com")
 	assert.NoError(t, err)
 
 	response, err := client.createAd(1, "hello", "world")
@@ -68,10 +68,10 @@ func TestUpdateAd(t *testing.T) {
 func TestListAds(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com")
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com")
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com")
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com")
 	assert.NoError(t, err)
 
 	response, err := client.createAd(1, "hello", "world")
@@ -0,0 +1,230 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"ads/internal/app"
+	grpcPort "ads/internal/ports/grpc"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestAdminManagesCategories(t *testing.T) {
+	client := getTestClient()
+
+	adminID, err := client.createStaff(user.RoleAdmin)
+	assert.NoError(t, err)
+
+	cars, err := client.createCategory(adminID, nil, "Cars", "cars")
+	assert.NoError(t, err)
+	assert.Equal(t, "cars", cars.Data.Slug)
+	assert.Nil(t, cars.Data.ParentID)
+
+	parts, err := client.createCategory(adminID, &cars.Data.ID, "Parts", "car-parts")
+	assert.NoError(t, err)
+	assert.Equal(t, cars.Data.ID, *parts.Data.ParentID)
+
+	parts, err = client.updateCategory(adminID, parts.Data.ID, nil, "Spare parts", "spare-parts")
+	assert.NoError(t, err)
+	assert.Equal(t, "spare-parts", parts.Data.Slug)
+	assert.Nil(t, parts.Data.ParentID)
+
+	list, err := client.listCategories()
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 3)
+
+	err = client.deleteCategory(adminID, parts.Data.ID)
+	assert.NoError(t, err)
+
+	list, err = client.listCategories()
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 2)
+}
+
+func TestUserCannotManageCategories(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	_, err = client.createCategory(u.Data.UserID, nil, "Cars", "cars")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = client.updateCategory(moderatorID, client.categoryID, nil, "Misc", "misc")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	err = client.deleteCategory(u.Data.UserID, client.categoryID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = client.createCategory(-1, nil, "Cars", "cars")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestCategoryValidation(t *testing.T) {
+	client := getTestClient()
+
+	adminID, err := client.createStaff(user.RoleAdmin)
+	assert.NoError(t, err)
+
+	type Test struct {
+		Name     string
+		ParentID *int64
+		Slug     string
+	}
+
+	missing := int64(100)
+	tests := [...]Test{
+		{"uppercase slug", nil, "Cars"},
+		{"slug with spaces", nil, "used cars"},
+		{"trailing dash", nil, "cars-"},
+		{"taken slug", nil, "other"},
+		{"unknown parent", &missing, "cars"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			_, err := client.createCategory(adminID, test.ParentID, "Cars", test.Slug)
+			assert.ErrorIs(t, err, ErrBadRequest)
+		})
+	}
+}
+
+func TestCategoryCannotMoveUnderItself(t *testing.T) {
+	client := getTestClient()
+
+	adminID, err := client.createStaff(user.RoleAdmin)
+	assert.NoError(t, err)
+
+	cars, err := client.createCategory(adminID, nil, "Cars", "cars")
+	assert.NoError(t, err)
+	parts, err := client.createCategory(adminID, &cars.Data.ID, "Parts", "parts")
+	assert.NoError(t, err)
+
+	_, err = client.updateCategory(adminID, cars.Data.ID, &parts.Data.ID, "Cars", "cars")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	_, err = client.updateCategory(adminID, cars.Data.ID, &cars.Data.ID, "Cars", "cars")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	_, err = client.updateCategory(adminID, 100, nil, "Cars", "cars")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDeleteCategoryInUse(t *testing.T) {
+	client := getTestClient()
+
+	adminID, err := client.createStaff(user.RoleAdmin)
+	assert.NoError(t, err)
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	cars, err := client.createCategory(adminID, nil, "Cars", "cars")
+	assert.NoError(t, err)
+	_, err = client.createCategory(adminID, &cars.Data.ID, "Parts", "parts")
+	assert.NoError(t, err)
+
+	// a category with children
+	err = client.deleteCategory(adminID, cars.Data.ID)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	// a category with ads
+	_, err = client.createAd(u.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	err = client.deleteCategory(adminID, client.categoryID)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	err = client.deleteCategory(adminID, 100)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCreateAdInUnknownCategory(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	client.categoryID = 100
+	_, err = client.createAd(u.Data.UserID, "hello", "world")
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestListAdsByCategory(t *testing.T) {
+	client := getTestClient()
+
+	adminID, err := client.createStaff(user.RoleAdmin)
+	assert.NoError(t, err)
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	cars, err := client.createCategory(adminID, nil, "Cars", "cars")
+	assert.NoError(t, err)
+	parts, err := client.createCategory(adminID, &cars.Data.ID, "Parts", "parts")
+	assert.NoError(t, err)
+
+	client.categoryID = cars.Data.ID
+	car, err := client.createAd(u.Data.UserID, "car", "for sale")
+	assert.NoError(t, err)
+	client.categoryID = parts.Data.ID
+	wheel, err := client.createAd(u.Data.UserID, "wheel", "for sale")
+	assert.NoError(t, err)
+	_, err = client.createAd(u.Data.UserID, "draft", "not published")
+	assert.NoError(t, err)
+
+	_, err = client.changeAdStatus(u.Data.UserID, car.Data.ID, true)
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(u.Data.UserID, wheel.Data.ID, true)
+	assert.NoError(t, err)
+
+	byID, err := client.listAdsCategory(fmt.Sprint(cars.Data.ID))
+	assert.NoError(t, err)
+	assert.Len(t, byID.Data, 2)
+
+	bySlug, err := client.listAdsCategory("parts")
+	assert.NoError(t, err)
+	assert.Len(t, bySlug.Data, 1)
+	assert.Equal(t, wheel.Data.ID, bySlug.Data[0].ID)
+	assert.Equal(t, parts.Data.ID, bySlug.Data[0].CategoryID)
+
+	_, err = client.listAdsCategory("boats")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestGRPCListAdsByCategory(t *testing.T) {
+	client, ctx, a := newClient(t)
+	authorCtx, _ := signedIn(t, a, ctx, "alex")
+
+	parent := int64(0)
+	parts, err := a.CreateCategory(operatorContext(), &parent, "Parts", "parts")
+	assert.NoError(t, err, "a.CreateCategory")
+
+	ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "wheel", Text: "for sale", CategoryId: parts.ID})
+	assert.NoError(t, err, "client.CreateAd")
+	assert.Equal(t, parts.ID, ad.CategoryId)
+	_, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+	assert.NoError(t, err, "client.ChangeAdStatus")
+
+	categories, err := client.ListCategories(ctx, &emptypb.Empty{})
+	assert.NoError(t, err, "client.ListCategories")
+	assert.Len(t, categories.List, 2)
+
+	list, err := client.ListAdsByCategory(ctx, &grpcPort.ListAdsByCategoryRequest{Slug: "other"})
+	assert.NoError(t, err, "client.ListAdsByCategory")
+	assert.Len(t, list.List, 1)
+
+	list, err = client.ListAdsByCategory(ctx, &grpcPort.ListAdsByCategoryRequest{CategoryId: parts.ID})
+	assert.NoError(t, err, "client.ListAdsByCategory")
+	assert.Len(t, list.List, 1)
+
+	_, err = client.ListAdsByCategory(ctx, &grpcPort.ListAdsByCategoryRequest{CategoryId: 100})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = a.CreateCategory(app.ContextWithPrincipal(ctx, app.Principal{Role: user.RoleUser}), nil, "Boats", "boats")
+	assert.ErrorIs(t, err, app.ErrForbidden)
+}
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"ads/internal/ads"
+	grpcPort "ads/internal/ports/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestDayRange(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	type Test struct {
+		Name string
+		Day  int
+		Now  time.Time
+		From time.Time
+	}
+
+	tests := [...]Test{
+		{"this month", 5, time.Date(2024, 10, 18, 12, 0, 0, 0, time.UTC), time.Date(2024, 10, 5, 0, 0, 0, 0, time.UTC)},
+		{"today", 18, time.Date(2024, 10, 18, 0, 0, 0, 0, time.UTC), time.Date(2024, 10, 18, 0, 0, 0, 0, time.UTC)},
+		{"last month", 25, time.Date(2024, 10, 18, 12, 0, 0, 0, time.UTC), time.Date(2024, 9, 25, 0, 0, 0, 0, time.UTC)},
+		{"last year", 25, time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC), time.Date(2023, 12, 25, 0, 0, 0, 0, time.UTC)},
+		{"short months", 31, time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC), time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)},
+		{"leap day", 29, time.Date(2023, 3, 10, 12, 0, 0, 0, time.UTC), time.Date(2023, 1, 29, 0, 0, 0, 0, time.UTC)},
+		{"time zone", 18, time.Date(2024, 10, 18, 1, 0, 0, 0, moscow), time.Date(2024, 10, 18, 0, 0, 0, 0, moscow)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			from, to := ads.DayRange(test.Day, test.Now)
+			assert.True(t, test.From.Equal(from), "from %v", from)
+			assert.True(t, test.From.AddDate(0, 0, 1).Equal(to), "to %v", to)
+		})
+	}
+}
+
+func TestFindAdsUpdatedRange(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	created := publishAds(t, client, u.Data.UserID, "old", "fresh")
+
+	time.Sleep(10 * time.Millisecond)
+	since := time.Now().UTC()
+	_, err = client.updateAd(u.Data.UserID, created[1].ID, "fresh", "edited")
+	assert.NoError(t, err)
+
+	list, err := client.listAdsQuery("updated_from=" + url.QueryEscape(since.Format(time.RFC3339Nano)))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"fresh"}, titles(list.Data))
+
+	list, err = client.listAdsQuery("updated_to=" + url.QueryEscape(since.Format(time.RFC3339Nano)))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"old"}, titles(list.Data))
+}
+
+func TestFindAdsDateBounds(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	publishAds(t, client, u.Data.UserID, "hello")
+
+	// the day of the ad in a zone far from UTC, where it may be another date
+	loc, err := time.LoadLocation("Pacific/Kiritimati")
+	assert.NoError(t, err)
+	today := time.Now().In(loc).Format("2006-01-02")
+
+	list, err := client.listAdsQuery(fmt.Sprintf("created_from=%s&created_to=%s&tz=Pacific/Kiritimati", today, today))
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 1)
+
+	list, err = client.listAdsQuery(fmt.Sprintf("day=%d&tz=Pacific/Kiritimati", time.Now().In(loc).Day()))
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 1)
+
+	// an offset in the value wins over tz
+	from := url.QueryEscape(time.Now().Add(time.Hour).In(loc).Format(time.RFC3339))
+	_, err = client.listAdsQuery("created_from=" + from + "&tz=UTC")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	queries := [...]string{
+		"tz=Mars/Olympus",
+		"updated_from=today",
+		"updated_from=2024-02-01&updated_to=2024-01-01",
+		"day=5&created_from=2024-01-01",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			_, err := client.listAdsQuery(query)
+			assert.ErrorIs(t, err, ErrBadRequest)
+		})
+	}
+}
+
+func TestGRPCFindAdsDates(t *testing.T) {
+	client, ctx, a := newClient(t)
+	authorCtx, _ := signedIn(t, a, ctx, "alex")
+
+	ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err, "client.CreateAd")
+	_, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+	assert.NoError(t, err, "client.ChangeAdStatus")
+
+	list, err := client.ListAds(ctx, &grpcPort.ListAdsRequest{UpdatedFrom: timestamppb.New(time.Now().Add(-time.Hour))})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, list.List, 1)
+
+	loc, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+	list, err = client.ListAds(ctx, &grpcPort.ListAdsRequest{Day: int32(time.Now().In(loc).Day()), TimeZone: "Asia/Tokyo"})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, list.List, 1)
+
+	requests := [...]*grpcPort.ListAdsRequest{
+		{Day: 32},
+		{Day: 1, TimeZone: "Mars/Olympus"},
+		{Day: 1, CreatedFrom: timestamppb.Now()},
+		{UpdatedFrom: timestamppb.Now(), UpdatedTo: timestamppb.New(time.Now().Add(-time.Hour))},
+	}
+	for _, req := range requests {
+		_, err = client.ListAds(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "%v", req)
+	}
+}
@@ -8,42 +8,48 @@ import (
 
 func TestChangeStatusAdOfAnotherUser(t *testing.T) {
 	client := getTestClient()
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
+	assert.NoError(t, err)
+
+	other, err := client.createAccount("Gopher Gophersky", "gopher@go.com")
 	assert.NoError(t, err)
 
 	resp, err := client.createAd(1, "hello", "world")
 	assert.NoError(t, err)
 
-	_, err = client.changeAdStatus(100, resp.Data.ID, true)
+	_, err = client.changeAdStatus(other.Data.UserID, resp.Data.ID, true)
 	assert.ErrorIs(t, err, ErrForbidden)
 }
 
 func TestUpdateAdOfAnotherUser(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
+	assert.NoError(t, err)
+
+	other, err := client.createAccount("Gopher Gophersky", "gopher@go.com")
 	assert.NoError(t, err)
 
 	resp, err := client.createAd(1, "hello", "world")
 	assert.NoError(t, err)
 
-	_, err = client.updateAd(100, resp.Data.ID, "title", "text")
+	_, err = client.updateAd(other.Data.UserID, resp.Data.ID, "title", "text")
 	assert.ErrorIs(t, err, ErrForbidden)
 }
 
 func TestCreateAd_ID(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
 	assert.NoError(t, err)
 
 	resp, err := client.createAd(1, "hello", "world")
@@ -58,3 +64,61 @@ func TestCreateAd_ID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, resp.Data.ID, int64(2))
 }
+
+func TestDeleteAdOfAnotherUser(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com")
+	assert.NoError(t, err)
+
+	other, err := client.createAccount("Gopher Goshevich", "gopher@go.com")
+	assert.NoError(t, err)
+
+	resp, err := client.createAd(0, "hello", "world")
+	assert.NoError(t, err)
+
+	_, err = client.deleteAd(resp.Data.ID, other.Data.UserID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = client.getAd(resp.Data.ID)
+	assert.NoError(t, err)
+}
+
+func TestCreateAdWithoutToken(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.createUser("Gopher Gopherich", "gopher@go.com")
+	assert.NoError(t, err)
+
+	_, err = client.createAd(0, "hello", "world")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestChangeStatusWithInvalidToken(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("Gopher Gopherich", "gopher@go.com")
+	assert.NoError(t, err)
+
+	resp, err := client.createAd(u.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+
+	client.tokens[u.Data.UserID] += "x"
+	_, err = client.changeAdStatus(u.Data.UserID, resp.Data.ID, true)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestCreateAdForAnotherUserID(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com")
+	assert.NoError(t, err)
+
+	other, err := client.createAccount("Gopher Goshevich", "gopher@go.com")
+	assert.NoError(t, err)
+
+	// the token belongs to user 0, the deprecated body user_id says otherwise
+	client.tokens[other.Data.UserID] = client.tokens[0]
+	_, err = client.createAd(other.Data.UserID, "hello", "world")
+	assert.ErrorIs(t, err, ErrForbidden)
+}
@@ -0,0 +1,266 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"ads/internal/adapters/adrepo"
+	"ads/internal/ads"
+	"ads/internal/app"
+	grpcPort "ads/internal/ports/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testAdTTL        = 10 * 24 * time.Hour
+	testExpiryNotice = 2 * 24 * time.Hour
+)
+
+// fakeClock stands still until moved.
+type fakeClock struct {
+	sync.Mutex
+	t time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.t = c.t.Add(d)
+}
+
+// notices records the ads whose authors were warned.
+type notices struct {
+	sync.Mutex
+	adIDs []int64
+}
+
+func (n *notices) AdExpiring(ctx context.Context, ad *ads.Ad) error {
+	n.Lock()
+	defer n.Unlock()
+	n.adIDs = append(n.adIDs, ad.ID)
+	return nil
+}
+
+func (n *notices) taken() []int64 {
+	n.Lock()
+	defer n.Unlock()
+	result := n.adIDs
+	n.adIDs = nil
+	return result
+}
+
+func getExpiryClient(opts ...app.Option) (*testClient, *fakeClock, *notices) {
+	clock, sent := newFakeClock(), &notices{}
+	opts = append([]app.Option{app.WithClock(clock.Now), app.WithNotifier(sent), app.WithAdExpiry(testAdTTL, testExpiryNotice)}, opts...)
+	return getTestClient(opts...), clock, sent
+}
+
+func TestPublishSetsExpiry(t *testing.T) {
+	client, clock, _ := getExpiryClient()
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(u.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	assert.Nil(t, ad.Data.ExpiresAt)
+
+	ad, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, clock.Now().Add(testAdTTL), *ad.Data.ExpiresAt)
+
+	ad, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, false)
+	assert.NoError(t, err)
+	assert.Nil(t, ad.Data.ExpiresAt)
+}
+
+func TestSweepAds(t *testing.T) {
+	client, clock, sent := getExpiryClient()
+	ctx := context.Background()
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	first, err := client.createAd(u.Data.UserID, "first", "text")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(u.Data.UserID, first.Data.ID, true)
+	assert.NoError(t, err)
+	clock.Advance(24 * time.Hour)
+	second, err := client.createAd(u.Data.UserID, "second", "text")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(u.Data.UserID, second.Data.ID, true)
+	assert.NoError(t, err)
+
+	clock.Advance(6 * 24 * time.Hour)
+	assert.NoError(t, client.app.SweepAds(ctx))
+	assert.Empty(t, sent.taken())
+
+	// the first ad is within the notice, and warned of once
+	clock.Advance(36 * time.Hour)
+	assert.NoError(t, client.app.SweepAds(ctx))
+	assert.Equal(t, []int64{first.Data.ID}, sent.taken())
+	assert.NoError(t, client.app.SweepAds(ctx))
+	assert.Empty(t, sent.taken())
+
+	// at its expiry the first ad is left out before the sweeper runs
+	clock.Advance(36 * time.Hour)
+	list, err := client.listAds()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"second"}, titles(list.Data))
+	ad, err := client.getAd(first.Data.ID)
+	assert.NoError(t, err)
+	assert.True(t, ad.Data.Published)
+
+	assert.NoError(t, client.app.SweepAds(ctx))
+	assert.Equal(t, []int64{second.Data.ID}, sent.taken())
+	ad, err = client.getAd(first.Data.ID)
+	assert.NoError(t, err)
+	assert.False(t, ad.Data.Published)
+	assert.NotNil(t, ad.Data.ExpiresAt)
+
+	list, err = client.listAdsQueryAs(u.Data.UserID, fmt.Sprintf("published=any&author_id=%d", u.Data.UserID))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, titles(list.Data))
+}
+
+func TestRenewAd(t *testing.T) {
+	client, clock, sent := getExpiryClient()
+	ctx := context.Background()
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	other, err := client.createAccount("bob", "bob@mai.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(u.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	adID := ad.Data.ID
+
+	// a draft has no term to renew
+	_, err = client.renewAd(u.Data.UserID, adID)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	_, err = client.changeAdStatus(u.Data.UserID, adID, true)
+	assert.NoError(t, err)
+	clock.Advance(testAdTTL - time.Hour)
+	assert.NoError(t, client.app.SweepAds(ctx))
+	assert.Equal(t, []int64{adID}, sent.taken())
+
+	ad, err = client.renewAd(u.Data.UserID, adID)
+	assert.NoError(t, err)
+	assert.True(t, ad.Data.Published)
+	assert.Equal(t, clock.Now().Add(testAdTTL), *ad.Data.ExpiresAt)
+
+	// the renewed ad is warned of again before its new expiry
+	clock.Advance(testAdTTL - time.Hour)
+	assert.NoError(t, client.app.SweepAds(ctx))
+	assert.Equal(t, []int64{adID}, sent.taken())
+	clock.Advance(time.Hour)
+	assert.NoError(t, client.app.SweepAds(ctx))
+	assert.Empty(t, sent.taken())
+	ad, err = client.getAd(adID)
+	assert.NoError(t, err)
+	assert.False(t, ad.Data.Published)
+
+	_, err = client.renewAd(other.Data.UserID, adID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.renewAd(u.Data.UserID, adID+100)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	// an expired ad comes back
+	ad, err = client.renewAd(u.Data.UserID, adID)
+	assert.NoError(t, err)
+	assert.True(t, ad.Data.Published)
+	list, err := client.listAds()
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 1)
+
+	// unpublished by the author it stays down
+	_, err = client.changeAdStatus(u.Data.UserID, adID, false)
+	assert.NoError(t, err)
+	_, err = client.renewAd(u.Data.UserID, adID)
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestRenewChecksStatus(t *testing.T) {
+	ctx := context.Background()
+	repo := adrepo.New()
+	adID, err := repo.Add(ctx, &ads.Ad{Title: "hello", Text: "world", AuthorID: 1, Status: ads.StatusArchived, Version: 1})
+	assert.NoError(t, err)
+	_, err = repo.ChangeStatus(ctx, adID, ads.StatusArchived, ads.StatusPendingReview, "", nil, true)
+	assert.NoError(t, err)
+
+	_, err = repo.Renew(ctx, adID, ads.StatusArchived, time.Now().Add(testAdTTL))
+	assert.ErrorIs(t, err, ads.ErrStatusChanged, "an ad taken into review after it was read is not renewed")
+	ad, err := repo.GetAd(ctx, adID)
+	assert.NoError(t, err)
+	assert.Equal(t, ads.StatusPendingReview, ad.Status)
+
+	ad, err = repo.Renew(ctx, adID, ads.StatusPendingReview, time.Now().Add(testAdTTL))
+	assert.NoError(t, err)
+	assert.Equal(t, ads.StatusPublished, ad.Status)
+}
+
+func TestExpirySweeper(t *testing.T) {
+	client, clock, sent := getExpiryClient(app.WithExpirySweeper(5 * time.Millisecond))
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(u.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+
+	clock.Advance(testAdTTL - time.Hour)
+	var warned []int64
+	assert.Eventually(t, func() bool {
+		warned = append(warned, sent.taken()...)
+		return len(warned) > 0
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []int64{ad.Data.ID}, warned)
+
+	clock.Advance(time.Hour)
+	assert.Eventually(t, func() bool {
+		ad, err := client.getAd(ad.Data.ID)
+		return err == nil && !ad.Data.Published
+	}, time.Second, 5*time.Millisecond)
+	assert.NoError(t, client.app.Close())
+	assert.Empty(t, sent.taken())
+}
+
+func TestGRPCRenewAd(t *testing.T) {
+	clock := newFakeClock()
+	client, ctx, a := newClient(t, app.WithClock(clock.Now), app.WithAdExpiry(testAdTTL, testExpiryNotice))
+	authorCtx, _ := signedIn(t, a, ctx, "alex")
+	otherCtx, _ := signedIn(t, a, ctx, "bob")
+
+	ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err, "client.CreateAd")
+	assert.Nil(t, ad.ExpiresAt)
+	ad, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+	assert.NoError(t, err, "client.ChangeAdStatus")
+	assert.Equal(t, clock.Now().Add(testAdTTL), ad.ExpiresAt.AsTime())
+
+	clock.Advance(time.Hour)
+	ad, err = client.RenewAd(authorCtx, &grpcPort.RenewAdRequest{AdId: ad.Id})
+	assert.NoError(t, err, "client.RenewAd")
+	assert.Equal(t, clock.Now().Add(testAdTTL), ad.ExpiresAt.AsTime())
+
+	_, err = client.RenewAd(otherCtx, &grpcPort.RenewAdRequest{AdId: ad.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.RenewAd(ctx, &grpcPort.RenewAdRequest{AdId: ad.Id})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	grpcPort "ads/internal/ports/grpc"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// publishFacetAds publishes three priced ads of alex, two of them in cars,
+// and one of bob.
+func publishFacetAds(t *testing.T, client *testClient) (alexID, bobID, carsID int64) {
+	adminID, err := client.createStaff("admin")
+	assert.NoError(t, err)
+	cars, err := client.createCategory(adminID, nil, "Cars", "cars")
+	assert.NoError(t, err)
+
+	alex, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	bob, err := client.createAccount("bob", "bob@mai.com")
+	assert.NoError(t, err)
+
+	publish := func(userID, categoryID, price int64, title string) {
+		_, err := client.publishAd(userID, map[string]any{"title": title, "category_id": categoryID, "price": price, "currency": "RUB"})
+		assert.NoError(t, err)
+	}
+	publish(alex.Data.UserID, cars.Data.ID, 50000, "red car")
+	publish(alex.Data.UserID, cars.Data.ID, 700000, "blue car")
+	publish(alex.Data.UserID, 0, 9000000, "red sofa")
+	publish(bob.Data.UserID, 0, 300000, "red lamp")
+
+	return alex.Data.UserID, bob.Data.UserID, cars.Data.ID
+}
+
+func TestListAdsFacets(t *testing.T) {
+	client := getTestClient()
+	alexID, bobID, carsID := publishFacetAds(t, client)
+
+	list, err := client.listAdsQuery("facets=true&limit=1")
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 1)
+
+	// the counts cover every matching ad, not only the page
+	facets := list.Facets
+	assert.NotNil(t, facets)
+	assert.Equal(t, []idCountData{{ID: 0, Count: 2}, {ID: carsID, Count: 2}}, facets.Categories)
+	assert.Equal(t, []idCountData{{ID: alexID, Count: 3}, {ID: bobID, Count: 1}}, facets.Authors)
+
+	var buckets []string
+	for _, b := range facets.Prices {
+		max := "inf"
+		if b.Max != nil {
+			max = fmt.Sprint(*b.Max)
+		}
+		buckets = append(buckets, fmt.Sprintf("%d-%s:%d", b.Min, max, b.Count))
+	}
+	assert.Equal(t, []string{"0-100000:1", "100000-500000:1", "500000-1000000:1", "5000000-inf:1"}, buckets)
+
+	assert.Len(t, facets.Months, 1)
+	assert.Equal(t, time.Now().UTC().Format("2006-01"), facets.Months[0].Month)
+	assert.Equal(t, int64(4), facets.Months[0].Count)
+
+	list, err = client.listAdsQuery("facets=true&category=cars")
+	assert.NoError(t, err)
+	assert.Equal(t, []idCountData{{ID: carsID, Count: 2}}, list.Facets.Categories)
+	assert.Equal(t, []idCountData{{ID: alexID, Count: 2}}, list.Facets.Authors)
+
+	list, err = client.listAdsQuery("limit=1")
+	assert.NoError(t, err)
+	assert.Nil(t, list.Facets)
+
+	_, err = client.listAdsQuery("facets=maybe")
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestSearchAdsFacets(t *testing.T) {
+	client := getTestClient()
+	alexID, bobID, _ := publishFacetAds(t, client)
+
+	list, err := client.searchAds("q=red&facets=true")
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 3)
+	assert.Equal(t, []idCountData{{ID: alexID, Count: 2}, {ID: bobID, Count: 1}}, list.Facets.Authors)
+}
+
+func TestGRPCListAdsFacets(t *testing.T) {
+	client, ctx, a := newClient(t)
+	alexCtx, alexID := signedIn(t, a, ctx, "alex")
+
+	for _, price := range []int64{100, 200000} {
+		ad, err := client.CreateAd(alexCtx, &grpcPort.CreateAdRequest{Title: "lamp", Text: "text", Price: price})
+		assert.NoError(t, err, "client.CreateAd")
+		_, err = client.ChangeAdStatus(alexCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+		assert.NoError(t, err, "client.ChangeAdStatus")
+	}
+
+	list, err := client.ListAds(ctx, &grpcPort.ListAdsRequest{Facets: true})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, list.Facets.Authors, 1)
+	assert.Equal(t, alexID, list.Facets.Authors[0].Id)
+	assert.Equal(t, int64(2), list.Facets.Authors[0].Count)
+	assert.Len(t, list.Facets.Prices, 2)
+	assert.Equal(t, int64(100000), list.Facets.Prices[0].GetMax())
+
+	search, err := client.SearchAds(ctx, &grpcPort.SearchAdsRequest{Query: "lamp", Filter: &grpcPort.ListAdsRequest{Facets: true}})
+	assert.NoError(t, err, "client.SearchAds")
+	assert.Equal(t, int64(2), search.Facets.Months[0].Count)
+
+	list, err = client.ListAds(ctx, &grpcPort.ListAdsRequest{})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Nil(t, list.Facets)
+}
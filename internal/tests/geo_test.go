@@ -0,0 +1,268 @@
+package tests
+
+import (
+	"testing"
+
+	"ads/internal/ads"
+	grpcPort "ads/internal/ports/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	moscow = ads.Location{Lat: 55.7558, Lon: 37.6173}
+	khimki = ads.Location{Lat: 55.8970, Lon: 37.4297}
+	tver   = ads.Location{Lat: 56.8587, Lon: 35.9176}
+	spb    = ads.Location{Lat: 59.9343, Lon: 30.3351}
+)
+
+// publishPlacedAds publishes an ad in each of Moscow, Khimki, Tver and Saint
+// Petersburg, and one with no location.
+func publishPlacedAds(t *testing.T, client *testClient) int64 {
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	for _, place := range []map[string]any{
+		{"title": "Moscow", "lat": moscow.Lat, "lon": moscow.Lon, "city": "Moscow"},
+		{"title": "Khimki", "lat": khimki.Lat, "lon": khimki.Lon, "city": "Khimki"},
+		{"title": "Tver", "lat": tver.Lat, "lon": tver.Lon, "city": "Tver"},
+		{"title": "Saint Petersburg", "lat": spb.Lat, "lon": spb.Lon, "city": "Saint Petersburg"},
+		{"title": "Nowhere", "city": "Moscow"},
+	} {
+		_, err := client.publishAd(u.Data.UserID, place)
+		assert.NoError(t, err)
+	}
+	return u.Data.UserID
+}
+
+func TestDistance(t *testing.T) {
+	assert.InDelta(t, 634, ads.DistanceKm(moscow, spb), 5)
+	assert.InDelta(t, 19.6, ads.DistanceKm(moscow, khimki), 0.5)
+	assert.Zero(t, ads.DistanceKm(tver, tver))
+	assert.InDelta(t, 22.26, ads.DistanceKm(ads.Location{Lon: 179.9}, ads.Location{Lon: -179.9}), 0.01)
+}
+
+func TestGeohash(t *testing.T) {
+	assert.Equal(t, "u4pruydqqvj", ads.Geohash(ads.Location{Lat: 57.64911, Lon: 10.40744}, 11))
+	assert.Equal(t, "ucfv0", ads.Geohash(moscow, 5))
+
+	hashes, ok := ads.GeohashesCovering(ads.BoxAround(moscow, 1), 5, 32)
+	assert.True(t, ok)
+	assert.Contains(t, hashes, "ucfv0")
+	_, ok = ads.GeohashesCovering(ads.BoxAround(moscow, 1000), 5, 32)
+	assert.False(t, ok)
+
+	// the box crosses the antimeridian
+	hashes, ok = ads.GeohashesCovering(ads.Box{MinLat: -1, MinLon: 179, MaxLat: 1, MaxLon: -179}, 2, 32)
+	assert.True(t, ok)
+	assert.ElementsMatch(t, []string{"xb", "80", "rz", "2p"}, hashes)
+}
+
+func TestBoxAround(t *testing.T) {
+	b := ads.BoxAround(moscow, 100)
+	assert.True(t, b.Contains(moscow))
+	assert.True(t, b.Contains(tver) == (ads.DistanceKm(moscow, tver) <= 100))
+	assert.InDelta(t, 0.9, b.MaxLat-moscow.Lat, 0.01)
+
+	b = ads.BoxAround(ads.Location{Lon: 179.9}, 50)
+	assert.True(t, b.Wraps())
+	assert.True(t, b.Contains(ads.Location{Lon: -179.9}))
+	assert.InDelta(t, 179.9, b.Center().Lon, 1e-9)
+
+	b = ads.BoxAround(ads.Location{Lat: 89.9}, 50)
+	assert.Equal(t, ads.Box{MinLat: b.MinLat, MinLon: -180, MaxLat: 90, MaxLon: 180}, b)
+
+	_, err := ads.ParseBox("1,2,3")
+	assert.Error(t, err)
+	_, err = ads.ParseBox("0,10,1,5")
+	assert.Error(t, err)
+	box, err := ads.ParseBox("179, -1, -179, 1")
+	assert.NoError(t, err)
+	assert.Equal(t, ads.Box{MinLat: -1, MinLon: 179, MaxLat: 1, MaxLon: -179}, box)
+}
+
+func TestCreateAdWithPlace(t *testing.T) {
+	client := getTestClient()
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAdAt(u.Data.UserID, "Sofa", map[string]any{"lat": moscow.Lat, "lon": moscow.Lon, "city": " Moscow "})
+	assert.NoError(t, err)
+	assert.Equal(t, moscow.Lat, *ad.Data.Lat)
+	assert.Equal(t, moscow.Lon, *ad.Data.Lon)
+	assert.Equal(t, "Moscow", ad.Data.City)
+	assert.Nil(t, ad.Data.DistanceKm)
+
+	// the place is kept unless the update gives one
+	ad, err = client.updateAd(u.Data.UserID, ad.Data.ID, "Old sofa", "text")
+	assert.NoError(t, err)
+	assert.Equal(t, moscow.Lat, *ad.Data.Lat)
+	assert.Equal(t, "Moscow", ad.Data.City)
+
+	ad, err = client.updateAdAt(u.Data.UserID, ad.Data.ID, "Old sofa", map[string]any{"city": "Tver"})
+	assert.NoError(t, err)
+	assert.Nil(t, ad.Data.Lat)
+	assert.Nil(t, ad.Data.Lon)
+	assert.Equal(t, "Tver", ad.Data.City)
+
+	for _, place := range []map[string]any{
+		{"lat": 55.0},
+		{"lon": 37.0},
+		{"lat": 91.0, "lon": 0.0},
+		{"lat": 0.0, "lon": -181.0},
+	} {
+		_, err := client.createAdAt(u.Data.UserID, "Sofa", place)
+		assert.ErrorIs(t, err, ErrBadRequest, place)
+		_, err = client.updateAdAt(u.Data.UserID, ad.Data.ID, "Sofa", place)
+		assert.ErrorIs(t, err, ErrBadRequest, place)
+	}
+}
+
+func TestListAdsNear(t *testing.T) {
+	client := getTestClient()
+	publishPlacedAds(t, client)
+
+	type Test struct {
+		Params string
+		Expect []string
+	}
+
+	tests := [...]Test{
+		{"lat=55.7558&lon=37.6173&radius_km=50&sort=distance", []string{"Moscow", "Khimki"}},
+		{"lat=55.7558&lon=37.6173&radius_km=200&sort=distance", []string{"Moscow", "Khimki", "Tver"}},
+		{"lat=59.9&lon=30.3&sort=distance", []string{"Saint Petersburg", "Tver", "Khimki", "Moscow"}},
+		{"lat=59.9&lon=30.3&radius_km=1000&sort=-created", []string{"Saint Petersburg", "Tver", "Khimki", "Moscow"}},
+		{"bbox=37,55,38,56&sort=distance", []string{"Moscow", "Khimki"}},
+		{"bbox=30,59,31,61", []string{"Saint Petersburg"}},
+		{"city=moscow", []string{"Moscow", "Nowhere"}},
+		{"city=moscow&lat=55.7558&lon=37.6173&radius_km=10", []string{"Moscow"}},
+	}
+
+	for _, test := range tests {
+		list, err := client.listAdsQuery(test.Params)
+		assert.NoError(t, err, test.Params)
+		assert.Equal(t, test.Expect, titles(list.Data), test.Params)
+	}
+
+	list, err := client.listAdsQuery("lat=55.7558&lon=37.6173&radius_km=50&sort=distance")
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, *list.Data[0].DistanceKm, 0.001)
+	assert.InDelta(t, ads.DistanceKm(moscow, khimki), *list.Data[1].DistanceKm, 0.001)
+
+	_, err = client.listAdsQuery("lat=0&lon=0&radius_km=100")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	for _, params := range []string{
+		"lat=55",
+		"lat=north&lon=37",
+		"lat=91&lon=37",
+		"radius_km=10",
+		"lat=55&lon=37&radius_km=-1",
+		"sort=distance",
+		"sort=-distance&lat=55&lon=37",
+		"bbox=1,2,3",
+		"bbox=0,10,1,5",
+	} {
+		_, err := client.listAdsQuery(params)
+		assert.ErrorIs(t, err, ErrBadRequest, params)
+	}
+}
+
+func TestListAdsNearPages(t *testing.T) {
+	client := getTestClient()
+	publishPlacedAds(t, client)
+
+	var got []string
+	query := "lat=59.9&lon=30.3&sort=distance&limit=1"
+	cursor := ""
+	for i := 0; i < 5; i++ {
+		params := query
+		if cursor != "" {
+			params += "&cursor=" + cursor
+		}
+		list, err := client.listAdsQuery(params)
+		assert.NoError(t, err)
+		got = append(got, titles(list.Data)...)
+		if cursor = list.NextCursor; cursor == "" {
+			break
+		}
+	}
+	assert.Equal(t, []string{"Saint Petersburg", "Tver", "Khimki", "Moscow"}, got)
+}
+
+func TestListAdsNearMoved(t *testing.T) {
+	client := getTestClient()
+	userID := publishPlacedAds(t, client)
+
+	list, err := client.listAdsQuery("city=tver")
+	assert.NoError(t, err)
+	tverID := list.Data[0].ID
+
+	_, err = client.updateAdAt(userID, tverID, "Tver", map[string]any{"lat": 0.0, "lon": 179.9})
+	assert.NoError(t, err)
+
+	list, err = client.listAdsQuery("lat=55.7558&lon=37.6173&radius_km=200&sort=distance")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Moscow", "Khimki"}, titles(list.Data))
+
+	// the circle and the box cross the antimeridian
+	list, err = client.listAdsQuery("lat=0&lon=-179.9&radius_km=50")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Tver"}, titles(list.Data))
+	assert.InDelta(t, 22.26, *list.Data[0].DistanceKm, 0.01)
+
+	list, err = client.listAdsQuery("bbox=179,-1,-179,1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Tver"}, titles(list.Data))
+
+	_, err = client.deleteAd(tverID, userID)
+	assert.NoError(t, err)
+	_, err = client.listAdsQuery("bbox=179,-1,-179,1")
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestGRPCListAdsNear(t *testing.T) {
+	client, ctx, a := newClient(t)
+	authorCtx, _ := signedIn(t, a, ctx, "alex")
+
+	for _, place := range []struct {
+		title string
+		at    ads.Location
+	}{{"Moscow", moscow}, {"Saint Petersburg", spb}, {"Tver", tver}} {
+		ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: place.title, Text: "text", Lat: proto.Float64(place.at.Lat), Lon: proto.Float64(place.at.Lon), City: place.title})
+		assert.NoError(t, err, "client.CreateAd")
+		assert.Equal(t, place.at.Lat, ad.GetLat())
+		assert.Equal(t, place.title, ad.GetCity())
+		_, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+		assert.NoError(t, err, "client.ChangeAdStatus")
+	}
+
+	list, err := client.ListAds(ctx, &grpcPort.ListAdsRequest{Lat: proto.Float64(moscow.Lat), Lon: proto.Float64(moscow.Lon), RadiusKm: 200, Sort: "distance"})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, list.List, 2)
+	assert.Equal(t, "Moscow", list.List[0].Title)
+	assert.Equal(t, "Tver", list.List[1].Title)
+	assert.InDelta(t, ads.DistanceKm(moscow, tver), list.List[1].GetDistanceKm(), 0.001)
+
+	list, err = client.ListAds(ctx, &grpcPort.ListAdsRequest{Bbox: &grpcPort.BoundingBox{MinLat: 59, MinLon: 30, MaxLat: 61, MaxLon: 31}})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, list.List, 1)
+	assert.Equal(t, "Saint Petersburg", list.List[0].Title)
+
+	list, err = client.ListAds(ctx, &grpcPort.ListAdsRequest{})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Nil(t, list.List[0].DistanceKm)
+
+	moved, err := client.UpdateAd(authorCtx, &grpcPort.UpdateAdRequest{AdId: list.List[0].Id, Title: "Moscow", Text: "text", City: proto.String("Moscow")})
+	assert.NoError(t, err, "client.UpdateAd")
+	assert.Nil(t, moved.Lat)
+	assert.Equal(t, "Moscow", moved.GetCity())
+
+	_, err = client.ListAds(ctx, &grpcPort.ListAdsRequest{Lat: proto.Float64(55)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.ListAds(ctx, &grpcPort.ListAdsRequest{Sort: "distance"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -9,10 +9,10 @@ import (
 func TestGetAd(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
 	assert.NoError(t, err)
 
 	_, err = client.createAd(1, "hello", "world")
@@ -30,10 +30,10 @@ func TestGetAd(t *testing.T) {
 func TestGetAdErr(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
 	assert.NoError(t, err)
 
 	_, err = client.createAd(1, "hello", "world")
@@ -46,31 +46,33 @@ func TestGetAdErr(t *testing.T) {
 func TestSearchByName(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
 	assert.NoError(t, err)
 
 	_, err = client.createAd(1, "hello", "world")
 	assert.NoError(t, err)
+	_, err = client.changeAdStatus(1, 0, true)
+	assert.NoError(t, err)
 
-	response, err := client.searchAdByName("h")
+	response, err := client.searchAdByName("Hello")
 	assert.NoError(t, err)
 	assert.Zero(t, response.Data[0].ID)
 	assert.Equal(t, response.Data[0].Title, "hello")
 	assert.Equal(t, response.Data[0].Text, "world")
 	assert.Equal(t, response.Data[0].AuthorID, int64(1))
-	assert.False(t, response.Data[0].Published)
+	assert.True(t, response.Data[0].Published)
 }
 
 func TestListAdsAuthor(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
 	assert.NoError(t, err)
 
 	response, err := client.createAd(1, "best cat", "not for sale")
@@ -99,10 +101,10 @@ func TestListAdsAuthorErr(t *testing.T) {
 func TestListAdsDate(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
 	assert.NoError(t, err)
 
 	response, err := client.createAd(1, "best cat", "not for sale")
@@ -128,10 +130,10 @@ func TestListAdsDateErr(t *testing.T) {
 func TestDeleteAd(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	u, err := client.createUser("Gopher Goshevich", "gopher@go.com") 
+	u, err := client.createAccount("Gopher Goshevich", "gopher@go.com") 
 	assert.NoError(t, err)
 
 	response, err := client.createAd(u.Data.UserID, "best cat", "not for sale")
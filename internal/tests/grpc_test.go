@@ -11,13 +11,16 @@ import (
 	"ads/internal/app"
 	grpcPort "ads/internal/ports/grpc"
 	"ads/internal/tests/mocks"
+	"ads/internal/user"
 
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/test/bufconn"
-	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 func getClientGRPC() (grpcPort.AdServiceClient, context.Context) {
@@ -58,25 +61,34 @@ func getClientGRPC() (grpcPort.AdServiceClient, context.Context) {
 }
 
 func Client(t *testing.T) (grpcPort.AdServiceClient, context.Context) {
+	client, ctx, _ := newClient(t)
+	return client, ctx
+}
+
+func newClient(t *testing.T, opts ...app.Option) (grpcPort.AdServiceClient, context.Context, app.App) {
 	lis := bufconn.Listen(1024 * 1024)
 	t.Cleanup(func() {
 		lis.Close()
 	})
 
+	logrus.SetFormatter(new(logrus.JSONFormatter))
+
+	users := userrepo.New()
+
+	a := app.NewApp(adrepo.New(), users, users, append([]app.Option{app.WithPasswordHasher(testHasher)}, opts...)...)
+	// ads created without a category_id go to this one, id 0
+	_, err := a.CreateCategory(operatorContext(), nil, "Other", "other")
+	assert.NoError(t, err, "a.CreateCategory")
+
 	srv := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(grpcPort.UnaryServerInterceptorPanicMethod),
 		grpc.ChainUnaryInterceptor(grpcPort.UnaryServerInterceptorLogMethod),
+		grpc.ChainUnaryInterceptor(grpcPort.UnaryServerInterceptorAuth(a)),
 	)
 	t.Cleanup(func() {
 		srv.Stop()
 	})
 
-	logrus.SetFormatter(new(logrus.JSONFormatter))
-
-	db := &mocks.RepositoryDbUser{}
-
-	a := app.NewApp(adrepo.New(), userrepo.New(), db)
-
 	svc := grpcPort.NewService(a)
 	grpcPort.RegisterAdServiceServer(srv, svc)
 
@@ -104,7 +116,33 @@ func Client(t *testing.T) (grpcPort.AdServiceClient, context.Context) {
 		conn.Close()
 	})
 
-	return grpcPort.NewAdServiceClient(conn), ctx
+	return grpcPort.NewAdServiceClient(conn), ctx, a
+}
+
+// signedIn signs a user up through a and returns ctx carrying their access token.
+func signedIn(t *testing.T, a app.App, ctx context.Context, name string) (context.Context, int64) {
+	id, err := a.CreateUserDb(user.User{NickName: name, Username: name, Password: "qwerty"})
+	assert.NoError(t, err, "a.CreateUserDb")
+
+	tokens, err := a.GenerateToken(ctx, name, "qwerty")
+	assert.NoError(t, err, "a.GenerateToken")
+
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tokens.AccessToken), int64(id)
+}
+
+// signedInAs is signedIn for a user granted role.
+func signedInAs(t *testing.T, a app.App, ctx context.Context, name string, role user.Role) (context.Context, int64) {
+	id, err := a.CreateUserDb(user.User{NickName: name, Username: name, Password: "qwerty"})
+	assert.NoError(t, err, "a.CreateUserDb")
+
+	operator := app.ContextWithPrincipal(ctx, app.Principal{Role: user.RoleAdmin})
+	_, err = a.SetUserRole(operator, int64(id), role)
+	assert.NoError(t, err, "a.SetUserRole")
+
+	tokens, err := a.GenerateToken(ctx, name, "qwerty")
+	assert.NoError(t, err, "a.GenerateToken")
+
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+tokens.AccessToken), int64(id)
 }
 
 func TestGRRPCCreateUser(t *testing.T) {
@@ -122,11 +160,10 @@ func TestGRRPCCreateUserErr(t *testing.T) {
 }
 
 func TestGRRPCCreateAd(t *testing.T) {
-	client, ctx := Client(t)
-	u, err := client.CreateUser(ctx, &grpcPort.CreateUserRequest{Name: "alex"})
-	assert.NoError(t, err, "client.CreateUser")
+	client, ctx, a := newClient(t)
+	ctx, _ = signedIn(t, a, ctx, "alex")
 
-	res, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world", UserId: u.Id})
+	res, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
 	assert.NoError(t, err, "client.CreateAd")
 
 	assert.Equal(t, "world", res.Text)
@@ -137,21 +174,41 @@ func TestGRRPCCreateAd(t *testing.T) {
 }
 
 func TestGRRPCCreateAdErr(t *testing.T) {
-	client, ctx := Client(t)
-	u, err := client.CreateUser(ctx, &grpcPort.CreateUserRequest{Name: "alex"})
-	assert.NoError(t, err, "client.CreateUser")
+	client, ctx, a := newClient(t)
+	ctx, _ = signedIn(t, a, ctx, "alex")
 
-	_, err = client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "", Text: "", UserId: u.Id})
+	_, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "", Text: ""})
 	assert.Error(t, err)
 }
 
-func TestGRRPCChangeAdStatus(t *testing.T) {
+func TestGRRPCCreateAdUnauthenticated(t *testing.T) {
 	client, ctx := Client(t)
 	u, err := client.CreateUser(ctx, &grpcPort.CreateUserRequest{Name: "alex"})
 	assert.NoError(t, err, "client.CreateUser")
-	ad, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world", UserId: u.Id})
+
+	_, err = client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world", UserId: u.Id})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer nonsense")
+	_, err = client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world", UserId: u.Id})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestGRRPCCreateAdForAnotherUserID(t *testing.T) {
+	client, ctx, a := newClient(t)
+	ctx, _ = signedIn(t, a, ctx, "alex")
+	_, otherID := signedIn(t, a, ctx, "oleg")
+
+	_, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world", UserId: otherID})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestGRRPCChangeAdStatus(t *testing.T) {
+	client, ctx, a := newClient(t)
+	ctx, _ = signedIn(t, a, ctx, "alex")
+	ad, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
 	assert.NoError(t, err, "client.CreateAd")
-	res, err := client.ChangeAdStatus(ctx, &grpcPort.ChangeAdStatusRequest{UserId: ad.AuthorId, AdId: ad.Id, Published: true})
+	res, err := client.ChangeAdStatus(ctx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
 	assert.NoError(t, err, "client.ChangeStatusAd")
 
 	assert.Equal(t, ad.Text, res.Text)
@@ -162,31 +219,39 @@ func TestGRRPCChangeAdStatus(t *testing.T) {
 }
 
 func TestGRRPCUpdateAd(t *testing.T) {
-	client, ctx := Client(t)
-	u, err := client.CreateUser(ctx, &grpcPort.CreateUserRequest{Name: "alex"})
-	assert.NoError(t, err, "client.CreateUser")
-	ad, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world", UserId: u.Id})
+	client, ctx, a := newClient(t)
+	ctx, _ = signedIn(t, a, ctx, "alex")
+	ad, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
 	assert.NoError(t, err, "client.CreateAd")
-	_, err = client.UpdateAd(ctx, &grpcPort.UpdateAdRequest{UserId: ad.AuthorId, AdId: ad.Id, Title: "", Text: ""})
+	_, err = client.UpdateAd(ctx, &grpcPort.UpdateAdRequest{AdId: ad.Id, Title: "", Text: ""})
+	assert.Error(t, err)
+}
+
+func TestGRRPCUpdateAdOfAnotherUser(t *testing.T) {
+	client, ctx, a := newClient(t)
+	authorCtx, _ := signedIn(t, a, ctx, "alex")
+	otherCtx, _ := signedIn(t, a, ctx, "oleg")
+	ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err, "client.CreateAd")
+	_, err = client.UpdateAd(otherCtx, &grpcPort.UpdateAdRequest{AdId: ad.Id, Title: "title", Text: "text"})
 	assert.Error(t, err)
 }
 
 func TestGRRPCListAds(t *testing.T) {
-	client, ctx := Client(t)
-	u, err := client.CreateUser(ctx, &grpcPort.CreateUserRequest{Name: "alex"})
-	assert.NoError(t, err, "client.CreateUser")
+	client, ctx, a := newClient(t)
+	ctx, _ = signedIn(t, a, ctx, "alex")
 
-	ad, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world", UserId: u.Id})
+	ad, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
 	assert.NoError(t, err, "client.CreateAd")
-	_, err = client.ChangeAdStatus(ctx, &grpcPort.ChangeAdStatusRequest{UserId: ad.AuthorId, AdId: ad.Id, Published: true})
+	_, err = client.ChangeAdStatus(ctx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
 	assert.NoError(t, err, "client.ChangeStatusAd")
 
-	ad, err = client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world", UserId: u.Id})
+	ad, err = client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
 	assert.NoError(t, err, "client.CreateAd")
-	_, err = client.ChangeAdStatus(ctx, &grpcPort.ChangeAdStatusRequest{UserId: ad.AuthorId, AdId: ad.Id, Published: true})
+	_, err = client.ChangeAdStatus(ctx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
 	assert.NoError(t, err, "client.ChangeStatusAd")
 
-	ads, err := client.ListAds(ctx, &emptypb.Empty{})
+	ads, err := client.ListAds(ctx, &grpcPort.ListAdsRequest{})
 	assert.NoError(t, err, "client.ChangeStatusAd")
 
 	assert.Len(t, ads.GetList(), 2)
@@ -214,11 +279,19 @@ func TestGRRPCGetUserErr(t *testing.T) {
 }
 
 func TestGRRPCDeleteUser(t *testing.T) {
-	client, ctx := Client(t)
+	client, ctx, a := newClient(t)
 	u, err := client.CreateUser(ctx, &grpcPort.CreateUserRequest{Name: "alex"})
 	assert.NoError(t, err, "client.CreateUser")
 
 	_, err = client.DeleteUser(ctx, &grpcPort.DeleteUserRequest{Id: u.Id})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	userCtx, _ := signedIn(t, a, ctx, "oleg")
+	_, err = client.DeleteUser(userCtx, &grpcPort.DeleteUserRequest{Id: u.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	adminCtx, _ := signedInAs(t, a, ctx, "admin", user.RoleAdmin)
+	_, err = client.DeleteUser(adminCtx, &grpcPort.DeleteUserRequest{Id: u.Id})
 	assert.NoError(t, err, "client.DeleteUser")
 }
 
@@ -232,13 +305,12 @@ func TestGRRPCDeleteUserErr(t *testing.T) {
 }
 
 func TestGRRPCDeleteAd(t *testing.T) {
-	client, ctx := Client(t)
-	u, err := client.CreateUser(ctx, &grpcPort.CreateUserRequest{Name: "alex"})
-	assert.NoError(t, err, "client.CreateUser")
+	client, ctx, a := newClient(t)
+	ctx, _ = signedIn(t, a, ctx, "alex")
 
-	ad, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world", UserId: u.Id})
+	ad, err := client.CreateAd(ctx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
 	assert.NoError(t, err, "client.CreateAd")
 
-	_, err = client.DeleteAd(ctx, &grpcPort.DeleteAdRequest{AuthorId: u.Id, AdId: ad.Id})
+	_, err = client.DeleteAd(ctx, &grpcPort.DeleteAdRequest{AdId: ad.Id})
 	assert.NoError(t, err, "client.GetUser")
 }
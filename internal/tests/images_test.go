@@ -0,0 +1,328 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"ads/internal/adapters/fsblob"
+	"ads/internal/adapters/s3blob"
+	"ads/internal/ads"
+	"ads/internal/app"
+	grpcPort "ads/internal/ports/grpc"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gifImage is the smallest GIF there is, a single transparent pixel.
+var gifImage = []byte("GIF89a\x01\x00\x01\x00\x80\x00\x00\x00\x00\x00\xff\xff\xff!\xf9\x04\x01\x00\x00\x00\x00,\x00\x00\x00\x00\x01\x00\x01\x00\x00\x02\x02D\x01\x00;")
+
+func pngImage(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.Set(0, 0, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// newMediaServer serves the images of a filesystem store from a server of
+// their own, the way cmd/main mounts them next to the API.
+func newMediaServer(t *testing.T, store *fsblob.Store) *httptest.Server {
+	media := httptest.NewServer(store.Handler())
+	t.Cleanup(media.Close)
+	return media
+}
+
+func newMediaClient(t *testing.T) (*testClient, *httptest.Server) {
+	store, err := fsblob.New(t.TempDir(), "/media")
+	assert.NoError(t, err)
+	return getTestClient(app.WithBlobStore(store)), newMediaServer(t, store)
+}
+
+func download(t *testing.T, media *httptest.Server, url string) (int, []byte) {
+	resp, err := http.Get(media.URL + url)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return resp.StatusCode, body
+}
+
+func TestUploadImages(t *testing.T) {
+	client, media := newMediaClient(t)
+	u, err := client.createAccount("alex", "alex@mail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(u.Data.UserID, "Bicycle", "red")
+	assert.NoError(t, err)
+	assert.Empty(t, ad.Data.Images)
+
+	pic := pngImage(t)
+	ad, err = client.uploadImages(u.Data.UserID, ad.Data.ID, pic, gifImage)
+	assert.NoError(t, err)
+	assert.Len(t, ad.Data.Images, 2)
+	assert.Equal(t, "image/png", ad.Data.Images[0].ContentType)
+	assert.Equal(t, int64(len(pic)), ad.Data.Images[0].Size)
+	assert.Equal(t, 0, ad.Data.Images[0].Position)
+	assert.Equal(t, "image/gif", ad.Data.Images[1].ContentType)
+	assert.Equal(t, 1, ad.Data.Images[1].Position)
+	assert.True(t, strings.HasPrefix(ad.Data.Images[0].URL, "/media/ads/"))
+	assert.True(t, strings.HasSuffix(ad.Data.Images[0].URL, ".png"))
+
+	code, body := download(t, media, ad.Data.Images[0].URL)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, pic, body)
+
+	_, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	list, err := client.listAds()
+	assert.NoError(t, err)
+	assert.Equal(t, ad.Data.Images, list.Data[0].Images)
+
+	images, err := client.listImages(ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, ad.Data.Images, images)
+}
+
+func TestListImagesOfUnpublishedAd(t *testing.T) {
+	client, _ := newMediaClient(t)
+	u, err := client.createAccount("alex", "alex@mail.com")
+	assert.NoError(t, err)
+	other, err := client.createAccount("oleg", "oleg@mail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(u.Data.UserID, "Bicycle", "red")
+	assert.NoError(t, err)
+	_, err = client.uploadImages(u.Data.UserID, ad.Data.ID, gifImage)
+	assert.NoError(t, err)
+
+	_, err = client.listImagesAs(-1, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden, "the images of a draft are not shown to anyone")
+	_, err = client.listImagesAs(other.Data.UserID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	images, err := client.listImagesAs(u.Data.UserID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, images, 1)
+
+	_, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	images, err = client.listImagesAs(-1, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, images, 1)
+}
+
+func TestUploadImagesInvalid(t *testing.T) {
+	client, _ := newMediaClient(t)
+	u, err := client.createAccount("alex", "alex@mail.com")
+	assert.NoError(t, err)
+	other, err := client.createAccount("oleg", "oleg@mail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(u.Data.UserID, "Bicycle", "red")
+	assert.NoError(t, err)
+
+	_, err = client.uploadImages(u.Data.UserID, ad.Data.ID, []byte("just some text, named photo.png"))
+	assert.ErrorIs(t, err, ErrUnsupportedMedia)
+	_, err = client.uploadImages(u.Data.UserID, ad.Data.ID, []byte("RIFF\x1a\x00\x00\x00WEBPVP8 \x0e\x00\x00\x00"))
+	assert.ErrorIs(t, err, ErrUnsupportedMedia, "WebP has no thumbnails")
+
+	huge := append(pngImage(t), make([]byte, ads.MaxImageSize)...)
+	_, err = client.uploadImages(u.Data.UserID, ad.Data.ID, huge)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = client.uploadImages(other.Data.UserID, ad.Data.ID, gifImage)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = client.uploadImages(u.Data.UserID, 100, gifImage)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	_, err = client.uploadImages(u.Data.UserID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	for i := 0; i < ads.MaxImages; i++ {
+		_, err = client.uploadImages(u.Data.UserID, ad.Data.ID, gifImage)
+		assert.NoError(t, err)
+	}
+	_, err = client.uploadImages(u.Data.UserID, ad.Data.ID, gifImage)
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestUploadImagesWithoutStore(t *testing.T) {
+	client := getTestClient()
+	u, err := client.createAccount("alex", "alex@mail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(u.Data.UserID, "Bicycle", "red")
+	assert.NoError(t, err)
+
+	_, err = client.uploadImages(u.Data.UserID, ad.Data.ID, gifImage)
+	assert.Error(t, err)
+}
+
+func TestReorderAndDeleteImages(t *testing.T) {
+	client, media := newMediaClient(t)
+	u, err := client.createAccount("alex", "alex@mail.com")
+	assert.NoError(t, err)
+	other, err := client.createAccount("oleg", "oleg@mail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(u.Data.UserID, "Bicycle", "red")
+	assert.NoError(t, err)
+	ad, err = client.uploadImages(u.Data.UserID, ad.Data.ID, pngImage(t), gifImage, pngImage(t))
+	assert.NoError(t, err)
+	first, second, third := ad.Data.Images[0], ad.Data.Images[1], ad.Data.Images[2]
+
+	ad, err = client.reorderImages(u.Data.UserID, ad.Data.ID, third.ID, first.ID, second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{third.ID, first.ID, second.ID}, imageIDs(ad.Data.Images))
+	assert.Equal(t, []int{0, 1, 2}, imagePositions(ad.Data.Images))
+
+	_, err = client.reorderImages(u.Data.UserID, ad.Data.ID, third.ID, first.ID)
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.reorderImages(u.Data.UserID, ad.Data.ID, third.ID, first.ID, first.ID)
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.reorderImages(other.Data.UserID, ad.Data.ID, first.ID, second.ID, third.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	ad, err = client.deleteImage(u.Data.UserID, ad.Data.ID, first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{third.ID, second.ID}, imageIDs(ad.Data.Images))
+	assert.Equal(t, []int{0, 1}, imagePositions(ad.Data.Images))
+	code, _ := download(t, media, first.URL)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	_, err = client.deleteImage(u.Data.UserID, ad.Data.ID, first.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = client.deleteImage(other.Data.UserID, ad.Data.ID, second.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = client.deleteAd(ad.Data.ID, u.Data.UserID)
+	assert.NoError(t, err)
+	code, _ = download(t, media, second.URL)
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = download(t, media, "/media/ads/")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func imageIDs(images []imageData) []int64 {
+	result := []int64{}
+	for _, img := range images {
+		result = append(result, img.ID)
+	}
+	return result
+}
+
+func imagePositions(images []imageData) []int {
+	result := []int{}
+	for _, img := range images {
+		result = append(result, img.Position)
+	}
+	return result
+}
+
+// fakeS3 keeps objects the way a bucket of MinIO would, refusing requests
+// that are not signed.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	sum := sha256.Sum256(body)
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=minio/") ||
+		r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) ||
+		r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[r.URL.Path] = body
+		s.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		object, ok := s.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(object)
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3BlobStore(t *testing.T) {
+	bucket := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	server := httptest.NewServer(bucket)
+	defer server.Close()
+
+	store := s3blob.New(s3blob.Config{Endpoint: server.URL, Bucket: "ads", AccessKey: "minio", SecretKey: "secret"}, server.Client())
+	client := getTestClient(app.WithBlobStore(store))
+	u, err := client.createAccount("alex", "alex@mail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(u.Data.UserID, "Bicycle", "red")
+	assert.NoError(t, err)
+
+	pic := pngImage(t)
+	ad, err = client.uploadImages(u.Data.UserID, ad.Data.ID, pic)
+	assert.NoError(t, err)
+	url := ad.Data.Images[0].URL
+	assert.True(t, strings.HasPrefix(url, server.URL+"/ads/ads/"))
+	path := strings.TrimPrefix(url, server.URL)
+	assert.Equal(t, pic, bucket.objects[path])
+	assert.Equal(t, "image/png", bucket.types[path])
+	stored, err := store.Get(context.Background(), strings.TrimPrefix(path, "/ads/"))
+	assert.NoError(t, err)
+	assert.Equal(t, pic, stored)
+
+	_, err = client.deleteImage(u.Data.UserID, ad.Data.ID, ad.Data.Images[0].ID)
+	assert.NoError(t, err)
+	assert.Empty(t, bucket.objects)
+
+	rejected := s3blob.New(s3blob.Config{Endpoint: server.URL, Bucket: "ads", AccessKey: "other", SecretKey: "secret"}, server.Client())
+	err = rejected.Put(context.Background(), "key.png", "image/png", bytes.NewReader(pic), int64(len(pic)))
+	assert.Error(t, err)
+
+	public := s3blob.New(s3blob.Config{Endpoint: server.URL, Bucket: "ads", PublicURL: "https://cdn.example.com/"}, nil)
+	assert.Equal(t, "https://cdn.example.com/ads/1/a%20b.png", public.URL("ads/1/a b.png"))
+}
+
+func TestGRPCAdImages(t *testing.T) {
+	store, err := fsblob.New(t.TempDir(), "/media")
+	assert.NoError(t, err)
+	client, ctx, a := newClient(t, app.WithBlobStore(store))
+	authorCtx, authorID := signedIn(t, a, ctx, "alex")
+
+	ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err)
+
+	principal := app.ContextWithPrincipal(ctx, app.Principal{UserID: authorID, Role: user.RoleUser})
+	_, err = a.AddImage(principal, ad.Id, bytes.NewReader(gifImage), int64(len(gifImage)))
+	assert.NoError(t, err)
+
+	ad, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+	assert.NoError(t, err)
+	assert.Len(t, ad.Images, 1)
+	assert.Equal(t, "image/gif", ad.Images[0].ContentType)
+	assert.Equal(t, int64(len(gifImage)), ad.Images[0].Size)
+	assert.True(t, strings.HasPrefix(ad.Images[0].Url, "/media/ads/"))
+
+	list, err := client.ListAds(ctx, &grpcPort.ListAdsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, ad.Images[0].Url, list.List[0].Images[0].Url)
+}
@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"ads/internal/adapters/pgrepo"
+	"ads/schema"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaMigrations(t *testing.T) {
+	m, err := pgrepo.NewMigrator(nil, schema.FS)
+	assert.NoError(t, err)
+
+	migrations := m.Migrations()
+	assert.NotEmpty(t, migrations)
+	for i, mig := range migrations {
+		assert.Equal(t, int64(i+1), mig.Version, mig.Name)
+		assert.NotEmpty(t, mig.Up, mig.Name)
+		assert.NotEmpty(t, mig.Down, mig.Name)
+	}
+}
+
+func TestMigrationsOrderedByVersion(t *testing.T) {
+	m, err := pgrepo.NewMigrator(nil, fstest.MapFS{
+		"000010_ten.up.sql":   {Data: []byte("SELECT 10;")},
+		"000002_two.up.sql":   {Data: []byte("SELECT 2;")},
+		"000002_two.down.sql": {Data: []byte("SELECT -2;")},
+		"readme.md":           {Data: []byte("not a migration")},
+	})
+	assert.NoError(t, err)
+
+	migrations := m.Migrations()
+	assert.Len(t, migrations, 2)
+	assert.Equal(t, int64(2), migrations[0].Version)
+	assert.Equal(t, "SELECT -2;", migrations[0].Down)
+	assert.Equal(t, int64(10), migrations[1].Version)
+	assert.Equal(t, "", migrations[1].Down)
+}
+
+func TestMigrationWithoutUpFile(t *testing.T) {
+	_, err := pgrepo.NewMigrator(nil, fstest.MapFS{
+		"000001_init.down.sql": {Data: []byte("DROP TABLE users;")},
+	})
+	assert.Error(t, err)
+}
@@ -25,7 +25,7 @@ func TestProductService_CreateUser(t *testing.T) {
 	repoUser := &mocks.RepositoryUser{}
 	repoPgUser := &mocks.RepositoryDbUser{}
 	repoUser.
-	On("AddUser", mock.Anything, &user.User{NickName: "Alex", Email: "email@tin.com"}).
+	On("AddUser", mock.Anything, &user.User{NickName: "Alex", Email: "email@tin.com", Role: user.RoleUser}).
 	Return(int64(0), nil)
 
 	a := app.NewApp(repoAd, repoUser, repoPgUser)
@@ -44,7 +44,7 @@ func TestProductService_CreateAd(t *testing.T) {
 	repoUser := &mocks.RepositoryUser{}
 	repoPgUser := &mocks.RepositoryDbUser{}
 	repoUser.
-	On("AddUser", mock.Anything, &user.User{NickName: "Alex", Email: "email@tin.com"}).
+	On("AddUser", mock.Anything, &user.User{NickName: "Alex", Email: "email@tin.com", Role: user.RoleUser}).
 	Return(int64(0), nil)
 
 	a := app.NewApp(repoAd, repoUser, repoPgUser)
@@ -55,17 +55,23 @@ func TestProductService_CreateAd(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, u.UserID, int64(0))
 
+	repoAd.
+	On("GetCategory", mock.Anything, int64(1)).
+	Return(&ads.Category{ID: 1, Name: "Other", Slug: "other"}, nil)
 	repoAd.
 	On("Add", mock.Anything, mock.Anything).
 	Return(int64(0), nil)
 
-	ad, err := a.CreateAd(ctx, "title", "text", u.UserID)
+	ad, err := a.CreateAd(app.ContextWithPrincipal(ctx, app.Principal{UserID: u.UserID}), "title", "text", 1, 150000, "RUB", nil)
 	log.Println(ad, err)
 	assert.Nil(t, err)
 	assert.Equal(t, ad.ID, int64(0))
 	assert.Equal(t, ad.Title, "title")
 	assert.Equal(t, ad.Text, "text")
 	assert.Equal(t, ad.AuthorID, u.UserID)
+	assert.Equal(t, ad.CategoryID, int64(1))
+	assert.Equal(t, ad.Price, int64(150000))
+	assert.Equal(t, ad.Currency, "RUB")
 }
 
 func getTestMockClient(a *mocks.App) *testClient {
@@ -74,6 +80,9 @@ func getTestMockClient(a *mocks.App) *testClient {
 	return &testClient{
 		client:  testServer.Client(),
 		baseURL: testServer.URL,
+		app:     a,
+		tokens:  map[int64]string{},
+		adminID: -1,
 	}
 }
 
@@ -95,13 +104,15 @@ func TestAdService_HandlerCreateUser(t *testing.T) {
 
 func TestAdService_HandlerCreateAd(t *testing.T) {
 	a := &mocks.App{}
+	a.On("ParseToken", mock.Anything, "token").Return(app.Principal{UserID: 0, SessionID: "session"}, nil)
 	a.On("CheckUser", mock.Anything, int64(0)).Return(nil)
-	a.On("CreateAd", mock.Anything, "hello", "world", int64(0)).Return(&ads.Ad{
+	a.On("CreateAd", mock.Anything, "hello", "world", int64(0), int64(0), "", &ads.Place{}).Return(&ads.Ad{
 		AuthorID: int64(0),
 		Title: "hello",
 		Text: "world",
 	}, nil)
 	client := getTestMockClient(a)
+	client.tokens[0] = "token"
 
 	response, err := client.createAd(0, "hello", "world")
 
@@ -129,7 +140,8 @@ func TestAdService_HandlerSearchAd(t *testing.T) {
 		Text: "world",
 	})
 
-	a.On("SearchAdByName", mock.Anything, mock.Anything).Return(result, nil)
+	published := true
+	a.On("SearchAds", mock.Anything, ads.Query{Published: &published, Search: "h"}).Return(&ads.List{Ads: result}, nil)
 
 	client := getTestMockClient(a)
 
@@ -154,7 +166,9 @@ func TestAdService_HandlerAuthorAds(t *testing.T) {
 		Text: "world",
 	})
 
-	a.On("ListAdsAuthor", mock.Anything, int64(0)).Return(result, nil)
+	// the drafts are not listed to an anonymous caller
+	authorID, published := int64(0), true
+	a.On("FindAds", mock.Anything, ads.Query{AuthorID: &authorID, Published: &published}).Return(&ads.List{Ads: result}, nil)
 
 	client := getTestMockClient(a)
 
@@ -179,7 +193,8 @@ func TestAdService_HandlerGetAds(t *testing.T) {
 		Text: "world",
 	})
 
-	a.On("ListAds", mock.Anything).Return(result, nil)
+	published := true
+	a.On("FindAds", mock.Anything, ads.Query{Published: &published}).Return(&ads.List{Ads: result}, nil)
 
 	client := getTestMockClient(a)
 
@@ -204,7 +219,9 @@ func TestAdService_HandlerGetAdsDate(t *testing.T) {
 		Text: "world",
 	})
 	day := int64(time.Now().UTC().Day())
-	a.On("ListAdsDate", mock.Anything, day).Return(result, nil)
+	from, to := ads.DayRange(int(day), time.Now().UTC())
+	published := true
+	a.On("FindAds", mock.Anything, ads.Query{CreatedFrom: &from, CreatedTo: &to, Published: &published}).Return(&ads.List{Ads: result}, nil)
 
 	client := getTestMockClient(a)
 
@@ -223,3 +240,15 @@ func TestAdService_HandlerErrCreateUser(t *testing.T) {
 	
 	assert.Error(t, err)
 }
+
+func TestProductService_CreateAdWithoutPrincipal(t *testing.T) {
+	repoAd := &mocks.RepositryAd{}
+	repoUser := &mocks.RepositoryUser{}
+	repoPgUser := &mocks.RepositoryDbUser{}
+
+	a := app.NewApp(repoAd, repoUser, repoPgUser)
+
+	_, err := a.CreateAd(context.Background(), "title", "text", 1, 0, "", nil)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+	repoAd.AssertNotCalled(t, "Add", mock.Anything, mock.Anything)
+}
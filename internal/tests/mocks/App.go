@@ -5,8 +5,12 @@ package mocks
 import (
 	ads "ads/internal/ads"
 
+	app "ads/internal/app"
+
 	context "context"
 
+	io "io"
+
 	mock "github.com/stretchr/testify/mock"
 
 	user "ads/internal/user"
@@ -17,25 +21,77 @@ type App struct {
 	mock.Mock
 }
 
-// ChangeAdStatus provides a mock function with given fields: ctx, adID, published, authorID
-func (_m *App) ChangeAdStatus(ctx context.Context, adID int64, published bool, authorID int64) (*ads.Ad, error) {
-	ret := _m.Called(ctx, adID, published, authorID)
+// AddImage provides a mock function with given fields: ctx, adID, r, size
+func (_m *App) AddImage(ctx context.Context, adID int64, r io.Reader, size int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, r, size)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, io.Reader, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, r, size)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, io.Reader, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, r, size)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, io.Reader, int64) error); ok {
+		r1 = rf(ctx, adID, r, size)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ApproveAd provides a mock function with given fields: ctx, adID
+func (_m *App) ApproveAd(ctx context.Context, adID int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID)
 
 	var r0 *ads.Ad
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, bool, int64) (*ads.Ad, error)); ok {
-		return rf(ctx, adID, published, authorID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, bool, int64) *ads.Ad); ok {
-		r0 = rf(ctx, adID, published, authorID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*ads.Ad)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, bool, int64) error); ok {
-		r1 = rf(ctx, adID, published, authorID)
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, adID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChangeAdStatus provides a mock function with given fields: ctx, adID, published
+func (_m *App) ChangeAdStatus(ctx context.Context, adID int64, published bool) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, published)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, bool) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, published)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, bool) *ads.Ad); ok {
+		r0 = rf(ctx, adID, published)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, bool) error); ok {
+		r1 = rf(ctx, adID, published)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -57,25 +113,65 @@ func (_m *App) CheckUser(ctx context.Context, userID int64) error {
 	return r0
 }
 
-// CreateAd provides a mock function with given fields: ctx, title, text, authorID
-func (_m *App) CreateAd(ctx context.Context, title string, text string, authorID int64) (*ads.Ad, error) {
-	ret := _m.Called(ctx, title, text, authorID)
+// Close provides a mock function with given fields: 
+func (_m *App) Close() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateAd provides a mock function with given fields: ctx, title, text, categoryID, price, currency, place
+func (_m *App) CreateAd(ctx context.Context, title string, text string, categoryID int64, price int64, currency string, place *ads.Place) (*ads.Ad, error) {
+	ret := _m.Called(ctx, title, text, categoryID, price, currency, place)
 
 	var r0 *ads.Ad
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64) (*ads.Ad, error)); ok {
-		return rf(ctx, title, text, authorID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, int64, string, *ads.Place) (*ads.Ad, error)); ok {
+		return rf(ctx, title, text, categoryID, price, currency, place)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64) *ads.Ad); ok {
-		r0 = rf(ctx, title, text, authorID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, int64, string, *ads.Place) *ads.Ad); ok {
+		r0 = rf(ctx, title, text, categoryID, price, currency, place)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*ads.Ad)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64) error); ok {
-		r1 = rf(ctx, title, text, authorID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64, int64, string, *ads.Place) error); ok {
+		r1 = rf(ctx, title, text, categoryID, price, currency, place)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateCategory provides a mock function with given fields: ctx, parentID, name, slug
+func (_m *App) CreateCategory(ctx context.Context, parentID *int64, name string, slug string) (*ads.Category, error) {
+	ret := _m.Called(ctx, parentID, name, slug)
+
+	var r0 *ads.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *int64, string, string) (*ads.Category, error)); ok {
+		return rf(ctx, parentID, name, slug)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *int64, string, string) *ads.Category); ok {
+		r0 = rf(ctx, parentID, name, slug)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *int64, string, string) error); ok {
+		r1 = rf(ctx, parentID, name, slug)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -110,21 +206,21 @@ func (_m *App) CreateUser(ctx context.Context, nickname string, email string) (*
 }
 
 // CreateUserDb provides a mock function with given fields: _a0
-func (_m *App) CreateUserDb(_a0 user.UserDb) (int, error) {
+func (_m *App) CreateUserDb(_a0 user.User) (int, error) {
 	ret := _m.Called(_a0)
 
 	var r0 int
 	var r1 error
-	if rf, ok := ret.Get(0).(func(user.UserDb) (int, error)); ok {
+	if rf, ok := ret.Get(0).(func(user.User) (int, error)); ok {
 		return rf(_a0)
 	}
-	if rf, ok := ret.Get(0).(func(user.UserDb) int); ok {
+	if rf, ok := ret.Get(0).(func(user.User) int); ok {
 		r0 = rf(_a0)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
-	if rf, ok := ret.Get(1).(func(user.UserDb) error); ok {
+	if rf, ok := ret.Get(1).(func(user.User) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -133,17 +229,57 @@ func (_m *App) CreateUserDb(_a0 user.UserDb) (int, error) {
 	return r0, r1
 }
 
-// DeleteAd provides a mock function with given fields: ctx, authorID, adID
-func (_m *App) DeleteAd(ctx context.Context, authorID int64, adID int64) (*ads.Ad, error) {
-	ret := _m.Called(ctx, authorID, adID)
+// DeleteAd provides a mock function with given fields: ctx, adID
+func (_m *App) DeleteAd(ctx context.Context, adID int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, adID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteCategory provides a mock function with given fields: ctx, categoryID
+func (_m *App) DeleteCategory(ctx context.Context, categoryID int64) error {
+	ret := _m.Called(ctx, categoryID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, categoryID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteImage provides a mock function with given fields: ctx, adID, imageID
+func (_m *App) DeleteImage(ctx context.Context, adID int64, imageID int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, imageID)
 
 	var r0 *ads.Ad
 	var r1 error
 	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*ads.Ad, error)); ok {
-		return rf(ctx, authorID, adID)
+		return rf(ctx, adID, imageID)
 	}
 	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *ads.Ad); ok {
-		r0 = rf(ctx, authorID, adID)
+		r0 = rf(ctx, adID, imageID)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*ads.Ad)
@@ -151,7 +287,7 @@ func (_m *App) DeleteAd(ctx context.Context, authorID int64, adID int64) (*ads.A
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
-		r1 = rf(ctx, authorID, adID)
+		r1 = rf(ctx, adID, imageID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -173,6 +309,58 @@ func (_m *App) DeleteUser(ctx context.Context, userID int64) error {
 	return r0
 }
 
+// FindAds provides a mock function with given fields: ctx, q
+func (_m *App) FindAds(ctx context.Context, q ads.Query) (*ads.List, error) {
+	ret := _m.Called(ctx, q)
+
+	var r0 *ads.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ads.Query) (*ads.List, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ads.Query) *ads.List); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ads.Query) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GenerateToken provides a mock function with given fields: ctx, username, password
+func (_m *App) GenerateToken(ctx context.Context, username string, password string) (*app.Tokens, error) {
+	ret := _m.Called(ctx, username, password)
+
+	var r0 *app.Tokens
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) (*app.Tokens, error)); ok {
+		return rf(ctx, username, password)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) *app.Tokens); ok {
+		r0 = rf(ctx, username, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*app.Tokens)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
+		r1 = rf(ctx, username, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAd provides a mock function with given fields: ctx, adID
 func (_m *App) GetAd(ctx context.Context, adID int64) (*ads.Ad, error) {
 	ret := _m.Called(ctx, adID)
@@ -199,6 +387,84 @@ func (_m *App) GetAd(ctx context.Context, adID int64) (*ads.Ad, error) {
 	return r0, r1
 }
 
+// GetCategory provides a mock function with given fields: ctx, categoryID
+func (_m *App) GetCategory(ctx context.Context, categoryID int64) (*ads.Category, error) {
+	ret := _m.Called(ctx, categoryID)
+
+	var r0 *ads.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*ads.Category, error)); ok {
+		return rf(ctx, categoryID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *ads.Category); ok {
+		r0 = rf(ctx, categoryID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, categoryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCategoryBySlug provides a mock function with given fields: ctx, slug
+func (_m *App) GetCategoryBySlug(ctx context.Context, slug string) (*ads.Category, error) {
+	ret := _m.Called(ctx, slug)
+
+	var r0 *ads.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*ads.Category, error)); ok {
+		return rf(ctx, slug)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *ads.Category); ok {
+		r0 = rf(ctx, slug)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRevision provides a mock function with given fields: ctx, adID, number
+func (_m *App) GetRevision(ctx context.Context, adID int64, number int) (*ads.Revision, error) {
+	ret := _m.Called(ctx, adID, number)
+
+	var r0 *ads.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) (*ads.Revision, error)); ok {
+		return rf(ctx, adID, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) *ads.Revision); ok {
+		r0 = rf(ctx, adID, number)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = rf(ctx, adID, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUser provides a mock function with given fields: ctx, userID
 func (_m *App) GetUser(ctx context.Context, userID int64) (*user.User, error) {
 	ret := _m.Called(ctx, userID)
@@ -225,20 +491,150 @@ func (_m *App) GetUser(ctx context.Context, userID int64) (*user.User, error) {
 	return r0, r1
 }
 
-// ListAds provides a mock function with given fields: ctx
-func (_m *App) ListAds(ctx context.Context) ([]*ads.Ad, error) {
+// ListAds provides a mock function with given fields: ctx, page
+func (_m *App) ListAds(ctx context.Context, page ads.Page) (*ads.List, error) {
+	ret := _m.Called(ctx, page)
+
+	var r0 *ads.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ads.Page) (*ads.List, error)); ok {
+		return rf(ctx, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ads.Page) *ads.List); ok {
+		r0 = rf(ctx, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ads.Page) error); ok {
+		r1 = rf(ctx, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAdsAuthor provides a mock function with given fields: ctx, author, page
+func (_m *App) ListAdsAuthor(ctx context.Context, author int64, page ads.Page) (*ads.List, error) {
+	ret := _m.Called(ctx, author, page)
+
+	var r0 *ads.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Page) (*ads.List, error)); ok {
+		return rf(ctx, author, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Page) *ads.List); ok {
+		r0 = rf(ctx, author, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, ads.Page) error); ok {
+		r1 = rf(ctx, author, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAdsCategory provides a mock function with given fields: ctx, categoryID, page
+func (_m *App) ListAdsCategory(ctx context.Context, categoryID int64, page ads.Page) (*ads.List, error) {
+	ret := _m.Called(ctx, categoryID, page)
+
+	var r0 *ads.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Page) (*ads.List, error)); ok {
+		return rf(ctx, categoryID, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Page) *ads.List); ok {
+		r0 = rf(ctx, categoryID, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, ads.Page) error); ok {
+		r1 = rf(ctx, categoryID, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAdsDate provides a mock function with given fields: ctx, day, page
+func (_m *App) ListAdsDate(ctx context.Context, day int64, page ads.Page) (*ads.List, error) {
+	ret := _m.Called(ctx, day, page)
+
+	var r0 *ads.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Page) (*ads.List, error)); ok {
+		return rf(ctx, day, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Page) *ads.List); ok {
+		r0 = rf(ctx, day, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, ads.Page) error); ok {
+		r1 = rf(ctx, day, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListAdsPrice provides a mock function with given fields: ctx, filter, page
+func (_m *App) ListAdsPrice(ctx context.Context, filter ads.PriceFilter, page ads.Page) (*ads.List, error) {
+	ret := _m.Called(ctx, filter, page)
+
+	var r0 *ads.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ads.PriceFilter, ads.Page) (*ads.List, error)); ok {
+		return rf(ctx, filter, page)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ads.PriceFilter, ads.Page) *ads.List); ok {
+		r0 = rf(ctx, filter, page)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ads.PriceFilter, ads.Page) error); ok {
+		r1 = rf(ctx, filter, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCategories provides a mock function with given fields: ctx
+func (_m *App) ListCategories(ctx context.Context) ([]*ads.Category, error) {
 	ret := _m.Called(ctx)
 
-	var r0 []*ads.Ad
+	var r0 []*ads.Category
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]*ads.Ad, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*ads.Category, error)); ok {
 		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []*ads.Ad); ok {
+	if rf, ok := ret.Get(0).(func(context.Context) []*ads.Category); ok {
 		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*ads.Ad)
+			r0 = ret.Get(0).([]*ads.Category)
 		}
 	}
 
@@ -251,25 +647,25 @@ func (_m *App) ListAds(ctx context.Context) ([]*ads.Ad, error) {
 	return r0, r1
 }
 
-// ListAdsAuthor provides a mock function with given fields: ctx, author
-func (_m *App) ListAdsAuthor(ctx context.Context, author int64) ([]*ads.Ad, error) {
-	ret := _m.Called(ctx, author)
+// ListReports provides a mock function with given fields: ctx, q
+func (_m *App) ListReports(ctx context.Context, q ads.ReportQuery) (*ads.ReportList, error) {
+	ret := _m.Called(ctx, q)
 
-	var r0 []*ads.Ad
+	var r0 *ads.ReportList
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Ad, error)); ok {
-		return rf(ctx, author)
+	if rf, ok := ret.Get(0).(func(context.Context, ads.ReportQuery) (*ads.ReportList, error)); ok {
+		return rf(ctx, q)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Ad); ok {
-		r0 = rf(ctx, author)
+	if rf, ok := ret.Get(0).(func(context.Context, ads.ReportQuery) *ads.ReportList); ok {
+		r0 = rf(ctx, q)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*ads.Ad)
+			r0 = ret.Get(0).(*ads.ReportList)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, author)
+	if rf, ok := ret.Get(1).(func(context.Context, ads.ReportQuery) error); ok {
+		r1 = rf(ctx, q)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -277,25 +673,25 @@ func (_m *App) ListAdsAuthor(ctx context.Context, author int64) ([]*ads.Ad, erro
 	return r0, r1
 }
 
-// ListAdsDate provides a mock function with given fields: ctx, day
-func (_m *App) ListAdsDate(ctx context.Context, day int64) ([]*ads.Ad, error) {
-	ret := _m.Called(ctx, day)
+// ListRevisions provides a mock function with given fields: ctx, adID
+func (_m *App) ListRevisions(ctx context.Context, adID int64) ([]*ads.Revision, error) {
+	ret := _m.Called(ctx, adID)
 
-	var r0 []*ads.Ad
+	var r0 []*ads.Revision
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Ad, error)); ok {
-		return rf(ctx, day)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Revision, error)); ok {
+		return rf(ctx, adID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Ad); ok {
-		r0 = rf(ctx, day)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Revision); ok {
+		r0 = rf(ctx, adID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*ads.Ad)
+			r0 = ret.Get(0).([]*ads.Revision)
 		}
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, day)
+		r1 = rf(ctx, adID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -303,25 +699,49 @@ func (_m *App) ListAdsDate(ctx context.Context, day int64) ([]*ads.Ad, error) {
 	return r0, r1
 }
 
-// SearchAdByName provides a mock function with given fields: ctx, title
-func (_m *App) SearchAdByName(ctx context.Context, title string) ([]*ads.Ad, error) {
-	ret := _m.Called(ctx, title)
+// ModerationQueue provides a mock function with given fields: ctx, page
+func (_m *App) ModerationQueue(ctx context.Context, page ads.Page) (*ads.List, error) {
+	ret := _m.Called(ctx, page)
 
-	var r0 []*ads.Ad
+	var r0 *ads.List
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*ads.Ad, error)); ok {
-		return rf(ctx, title)
+	if rf, ok := ret.Get(0).(func(context.Context, ads.Page) (*ads.List, error)); ok {
+		return rf(ctx, page)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []*ads.Ad); ok {
-		r0 = rf(ctx, title)
+	if rf, ok := ret.Get(0).(func(context.Context, ads.Page) *ads.List); ok {
+		r0 = rf(ctx, page)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*ads.Ad)
+			r0 = ret.Get(0).(*ads.List)
 		}
 	}
 
+	if rf, ok := ret.Get(1).(func(context.Context, ads.Page) error); ok {
+		r1 = rf(ctx, page)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ParseToken provides a mock function with given fields: ctx, accessToken
+func (_m *App) ParseToken(ctx context.Context, accessToken string) (app.Principal, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	var r0 app.Principal
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (app.Principal, error)); ok {
+		return rf(ctx, accessToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) app.Principal); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		r0 = ret.Get(0).(app.Principal)
+	}
+
 	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, title)
+		r1 = rf(ctx, accessToken)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -329,25 +749,367 @@ func (_m *App) SearchAdByName(ctx context.Context, title string) ([]*ads.Ad, err
 	return r0, r1
 }
 
-// UpdateAd provides a mock function with given fields: ctx, authorID, title, text, adID
-func (_m *App) UpdateAd(ctx context.Context, authorID int64, title string, text string, adID int64) (*ads.Ad, error) {
-	ret := _m.Called(ctx, authorID, title, text, adID)
+// RefreshToken provides a mock function with given fields: ctx, refreshToken
+func (_m *App) RefreshToken(ctx context.Context, refreshToken string) (*app.Tokens, error) {
+	ret := _m.Called(ctx, refreshToken)
+
+	var r0 *app.Tokens
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*app.Tokens, error)); ok {
+		return rf(ctx, refreshToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *app.Tokens); ok {
+		r0 = rf(ctx, refreshToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*app.Tokens)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RejectAd provides a mock function with given fields: ctx, adID, reason
+func (_m *App) RejectAd(ctx context.Context, adID int64, reason string) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, reason)
 
 	var r0 *ads.Ad
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, int64) (*ads.Ad, error)); ok {
-		return rf(ctx, authorID, title, text, adID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, reason)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, int64) *ads.Ad); ok {
-		r0 = rf(ctx, authorID, title, text, adID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) *ads.Ad); ok {
+		r0 = rf(ctx, adID, reason)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*ads.Ad)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string, int64) error); ok {
-		r1 = rf(ctx, authorID, title, text, adID)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, adID, reason)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenewAd provides a mock function with given fields: ctx, adID
+func (_m *App) RenewAd(ctx context.Context, adID int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, adID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReorderImages provides a mock function with given fields: ctx, adID, imageIDs
+func (_m *App) ReorderImages(ctx context.Context, adID int64, imageIDs []int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, imageIDs)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, imageIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, imageIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, []int64) error); ok {
+		r1 = rf(ctx, adID, imageIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReportAd provides a mock function with given fields: ctx, adID, reason, comment
+func (_m *App) ReportAd(ctx context.Context, adID int64, reason ads.ReportReason, comment string) (*ads.Report, error) {
+	ret := _m.Called(ctx, adID, reason, comment)
+
+	var r0 *ads.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.ReportReason, string) (*ads.Report, error)); ok {
+		return rf(ctx, adID, reason, comment)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.ReportReason, string) *ads.Report); ok {
+		r0 = rf(ctx, adID, reason, comment)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, ads.ReportReason, string) error); ok {
+		r1 = rf(ctx, adID, reason, comment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResolveReport provides a mock function with given fields: ctx, reportID, status
+func (_m *App) ResolveReport(ctx context.Context, reportID int64, status ads.ReportStatus) (*ads.Report, error) {
+	ret := _m.Called(ctx, reportID, status)
+
+	var r0 *ads.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.ReportStatus) (*ads.Report, error)); ok {
+		return rf(ctx, reportID, status)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.ReportStatus) *ads.Report); ok {
+		r0 = rf(ctx, reportID, status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, ads.ReportStatus) error); ok {
+		r1 = rf(ctx, reportID, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestoreRevision provides a mock function with given fields: ctx, adID, number, version
+func (_m *App) RestoreRevision(ctx context.Context, adID int64, number int, version *int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, number, version)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, *int64) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, number, version)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, *int64) *ads.Ad); ok {
+		r0 = rf(ctx, adID, number, version)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int, *int64) error); ok {
+		r1 = rf(ctx, adID, number, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeUserSessions provides a mock function with given fields: ctx, userID
+func (_m *App) RevokeUserSessions(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SearchAds provides a mock function with given fields: ctx, q
+func (_m *App) SearchAds(ctx context.Context, q ads.Query) (*ads.List, error) {
+	ret := _m.Called(ctx, q)
+
+	var r0 *ads.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ads.Query) (*ads.List, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ads.Query) *ads.List); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ads.Query) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetScreeningRules provides a mock function with given fields: rules
+func (_m *App) SetScreeningRules(rules []app.ScreeningRule) error {
+	ret := _m.Called(rules)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]app.ScreeningRule) error); ok {
+		r0 = rf(rules)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetUserRole provides a mock function with given fields: ctx, userID, role
+func (_m *App) SetUserRole(ctx context.Context, userID int64, role user.Role) (*user.User, error) {
+	ret := _m.Called(ctx, userID, role)
+
+	var r0 *user.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, user.Role) (*user.User, error)); ok {
+		return rf(ctx, userID, role)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, user.Role) *user.User); ok {
+		r0 = rf(ctx, userID, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*user.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, user.Role) error); ok {
+		r1 = rf(ctx, userID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SignOut provides a mock function with given fields: ctx
+func (_m *App) SignOut(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SuggestAds provides a mock function with given fields: ctx, query, limit
+func (_m *App) SuggestAds(ctx context.Context, query string, limit int) (*ads.Suggestions, error) {
+	ret := _m.Called(ctx, query, limit)
+
+	var r0 *ads.Suggestions
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*ads.Suggestions, error)); ok {
+		return rf(ctx, query, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *ads.Suggestions); ok {
+		r0 = rf(ctx, query, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Suggestions)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, query, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SweepAds provides a mock function with given fields: ctx
+func (_m *App) SweepAds(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateAd provides a mock function with given fields: ctx, title, text, price, currency, place, version, adID
+func (_m *App) UpdateAd(ctx context.Context, title string, text string, price int64, currency string, place *ads.Place, version *int64, adID int64) (*ads.Ad, error) {
+	ret := _m.Called(ctx, title, text, price, currency, place, version, adID)
+
+	var r0 *ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, string, *ads.Place, *int64, int64) (*ads.Ad, error)); ok {
+		return rf(ctx, title, text, price, currency, place, version, adID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, int64, string, *ads.Place, *int64, int64) *ads.Ad); ok {
+		r0 = rf(ctx, title, text, price, currency, place, version, adID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, int64, string, *ads.Place, *int64, int64) error); ok {
+		r1 = rf(ctx, title, text, price, currency, place, version, adID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCategory provides a mock function with given fields: ctx, categoryID, parentID, name, slug
+func (_m *App) UpdateCategory(ctx context.Context, categoryID int64, parentID *int64, name string, slug string) (*ads.Category, error) {
+	ret := _m.Called(ctx, categoryID, parentID, name, slug)
+
+	var r0 *ads.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64, string, string) (*ads.Category, error)); ok {
+		return rf(ctx, categoryID, parentID, name, slug)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64, string, string) *ads.Category); ok {
+		r0 = rf(ctx, categoryID, parentID, name, slug)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *int64, string, string) error); ok {
+		r1 = rf(ctx, categoryID, parentID, name, slug)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -3,9 +3,11 @@
 package mocks
 
 import (
-	user "ads/internal/user"
+	context "context"
 
 	mock "github.com/stretchr/testify/mock"
+
+	user "ads/internal/user"
 )
 
 // RepositoryDbUser is an autogenerated mock type for the RepositoryDbUser type
@@ -13,22 +15,46 @@ type RepositoryDbUser struct {
 	mock.Mock
 }
 
+// CreateSession provides a mock function with given fields: ctx, session
+func (_m *RepositoryDbUser) CreateSession(ctx context.Context, session *user.Session) (int64, error) {
+	ret := _m.Called(ctx, session)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *user.Session) (int64, error)); ok {
+		return rf(ctx, session)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *user.Session) int64); ok {
+		r0 = rf(ctx, session)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *user.Session) error); ok {
+		r1 = rf(ctx, session)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateUserDb provides a mock function with given fields: _a0
-func (_m *RepositoryDbUser) CreateUserDb(_a0 user.UserDb) (int, error) {
+func (_m *RepositoryDbUser) CreateUserDb(_a0 user.User) (int, error) {
 	ret := _m.Called(_a0)
 
 	var r0 int
 	var r1 error
-	if rf, ok := ret.Get(0).(func(user.UserDb) (int, error)); ok {
+	if rf, ok := ret.Get(0).(func(user.User) (int, error)); ok {
 		return rf(_a0)
 	}
-	if rf, ok := ret.Get(0).(func(user.UserDb) int); ok {
+	if rf, ok := ret.Get(0).(func(user.User) int); ok {
 		r0 = rf(_a0)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 
-	if rf, ok := ret.Get(1).(func(user.UserDb) error); ok {
+	if rf, ok := ret.Get(1).(func(user.User) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -37,6 +63,200 @@ func (_m *RepositoryDbUser) CreateUserDb(_a0 user.UserDb) (int, error) {
 	return r0, r1
 }
 
+// GetSessionByTokenHash provides a mock function with given fields: ctx, tokenHash
+func (_m *RepositoryDbUser) GetSessionByTokenHash(ctx context.Context, tokenHash string) (*user.Session, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	var r0 *user.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*user.Session, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *user.Session); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*user.Session)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUser provides a mock function with given fields: ctx, user_id
+func (_m *RepositoryDbUser) GetUser(ctx context.Context, user_id int64) (*user.User, error) {
+	ret := _m.Called(ctx, user_id)
+
+	var r0 *user.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*user.User, error)); ok {
+		return rf(ctx, user_id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *user.User); ok {
+		r0 = rf(ctx, user_id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*user.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, user_id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUserByUsername provides a mock function with given fields: ctx, username
+func (_m *RepositoryDbUser) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
+	ret := _m.Called(ctx, username)
+
+	var r0 *user.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*user.User, error)); ok {
+		return rf(ctx, username)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *user.User); ok {
+		r0 = rf(ctx, username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*user.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeSession provides a mock function with given fields: ctx, sessionID
+func (_m *RepositoryDbUser) RevokeSession(ctx context.Context, sessionID int64) (bool, error) {
+	ret := _m.Called(ctx, sessionID)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return rf(ctx, sessionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = rf(ctx, sessionID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, sessionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeSessionFamily provides a mock function with given fields: ctx, familyID
+func (_m *RepositoryDbUser) RevokeSessionFamily(ctx context.Context, familyID string) error {
+	ret := _m.Called(ctx, familyID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, familyID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeUserSessions provides a mock function with given fields: ctx, userID
+func (_m *RepositoryDbUser) RevokeUserSessions(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SessionFamilyActive provides a mock function with given fields: ctx, familyID
+func (_m *RepositoryDbUser) SessionFamilyActive(ctx context.Context, familyID string) (bool, error) {
+	ret := _m.Called(ctx, familyID)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, familyID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, familyID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, familyID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetUserRole provides a mock function with given fields: ctx, userID, role
+func (_m *RepositoryDbUser) SetUserRole(ctx context.Context, userID int64, role user.Role) (*user.User, error) {
+	ret := _m.Called(ctx, userID, role)
+
+	var r0 *user.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, user.Role) (*user.User, error)); ok {
+		return rf(ctx, userID, role)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, user.Role) *user.User); ok {
+		r0 = rf(ctx, userID, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*user.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, user.Role) error); ok {
+		r1 = rf(ctx, userID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePasswordHash provides a mock function with given fields: ctx, userID, hash
+func (_m *RepositoryDbUser) UpdatePasswordHash(ctx context.Context, userID int64, hash string) error {
+	ret := _m.Called(ctx, userID, hash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, hash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 type mockConstructorTestingTNewRepositoryDbUser interface {
 	mock.TestingT
 	Cleanup(func())
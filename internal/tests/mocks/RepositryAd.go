@@ -3,10 +3,13 @@
 package mocks
 
 import (
-	context "context"
 	ads "ads/internal/ads"
 
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // RepositryAd is an autogenerated mock type for the RepositryAd type
@@ -38,25 +41,147 @@ func (_m *RepositryAd) Add(ctx context.Context, ad *ads.Ad) (int64, error) {
 	return r0, r1
 }
 
-// ChangeStatus provides a mock function with given fields: ctx, adID, published, authorID
-func (_m *RepositryAd) ChangeStatus(ctx context.Context, adID int64, published bool, authorID int64) (*ads.Ad, error) {
-	ret := _m.Called(ctx, adID, published, authorID)
+// AddCategory provides a mock function with given fields: ctx, category
+func (_m *RepositryAd) AddCategory(ctx context.Context, category *ads.Category) (int64, error) {
+	ret := _m.Called(ctx, category)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Category) (int64, error)); ok {
+		return rf(ctx, category)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Category) int64); ok {
+		r0 = rf(ctx, category)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ads.Category) error); ok {
+		r1 = rf(ctx, category)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddImage provides a mock function with given fields: ctx, image
+func (_m *RepositryAd) AddImage(ctx context.Context, image *ads.Image) (int64, error) {
+	ret := _m.Called(ctx, image)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Image) (int64, error)); ok {
+		return rf(ctx, image)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Image) int64); ok {
+		r0 = rf(ctx, image)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ads.Image) error); ok {
+		r1 = rf(ctx, image)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddRendition provides a mock function with given fields: ctx, adID, rendition
+func (_m *RepositryAd) AddRendition(ctx context.Context, adID int64, rendition *ads.Rendition) (int64, error) {
+	ret := _m.Called(ctx, adID, rendition)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *ads.Rendition) (int64, error)); ok {
+		return rf(ctx, adID, rendition)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *ads.Rendition) int64); ok {
+		r0 = rf(ctx, adID, rendition)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *ads.Rendition) error); ok {
+		r1 = rf(ctx, adID, rendition)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddReport provides a mock function with given fields: ctx, report
+func (_m *RepositryAd) AddReport(ctx context.Context, report *ads.Report) (int64, error) {
+	ret := _m.Called(ctx, report)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Report) (int64, error)); ok {
+		return rf(ctx, report)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Report) int64); ok {
+		r0 = rf(ctx, report)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ads.Report) error); ok {
+		r1 = rf(ctx, report)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChangeStatus provides a mock function with given fields: ctx, adID, from, to, reason, expiresAt, held
+func (_m *RepositryAd) ChangeStatus(ctx context.Context, adID int64, from ads.Status, to ads.Status, reason string, expiresAt *time.Time, held bool) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, from, to, reason, expiresAt, held)
 
 	var r0 *ads.Ad
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, bool, int64) (*ads.Ad, error)); ok {
-		return rf(ctx, adID, published, authorID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Status, ads.Status, string, *time.Time, bool) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, from, to, reason, expiresAt, held)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, bool, int64) *ads.Ad); ok {
-		r0 = rf(ctx, adID, published, authorID)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Status, ads.Status, string, *time.Time, bool) *ads.Ad); ok {
+		r0 = rf(ctx, adID, from, to, reason, expiresAt, held)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*ads.Ad)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, bool, int64) error); ok {
-		r1 = rf(ctx, adID, published, authorID)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, ads.Status, ads.Status, string, *time.Time, bool) error); ok {
+		r1 = rf(ctx, adID, from, to, reason, expiresAt, held)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimExpiring provides a mock function with given fields: ctx, before
+func (_m *RepositryAd) ClaimExpiring(ctx context.Context, before time.Time) ([]*ads.Ad, error) {
+	ret := _m.Called(ctx, before)
+
+	var r0 []*ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]*ads.Ad, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []*ads.Ad); ok {
+		r0 = rf(ctx, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -90,6 +215,98 @@ func (_m *RepositryAd) DeleteAd(ctx context.Context, authorID int64, adId int64)
 	return r0, r1
 }
 
+// DeleteCategory provides a mock function with given fields: ctx, categoryID
+func (_m *RepositryAd) DeleteCategory(ctx context.Context, categoryID int64) error {
+	ret := _m.Called(ctx, categoryID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, categoryID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteImage provides a mock function with given fields: ctx, adID, imageID
+func (_m *RepositryAd) DeleteImage(ctx context.Context, adID int64, imageID int64) (*ads.Image, error) {
+	ret := _m.Called(ctx, adID, imageID)
+
+	var r0 *ads.Image
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (*ads.Image, error)); ok {
+		return rf(ctx, adID, imageID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) *ads.Image); ok {
+		r0 = rf(ctx, adID, imageID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Image)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, adID, imageID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ExpireAds provides a mock function with given fields: ctx, now
+func (_m *RepositryAd) ExpireAds(ctx context.Context, now time.Time) ([]*ads.Ad, error) {
+	ret := _m.Called(ctx, now)
+
+	var r0 []*ads.Ad
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) ([]*ads.Ad, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) []*ads.Ad); ok {
+		r0 = rf(ctx, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Ad)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Find provides a mock function with given fields: ctx, q
+func (_m *RepositryAd) Find(ctx context.Context, q ads.Query) (*ads.List, error) {
+	ret := _m.Called(ctx, q)
+
+	var r0 *ads.List
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, ads.Query) (*ads.List, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, ads.Query) *ads.List); ok {
+		r0 = rf(ctx, q)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.List)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, ads.Query) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAd provides a mock function with given fields: ctx, adID
 func (_m *RepositryAd) GetAd(ctx context.Context, adID int64) (*ads.Ad, error) {
 	ret := _m.Called(ctx, adID)
@@ -116,20 +333,150 @@ func (_m *RepositryAd) GetAd(ctx context.Context, adID int64) (*ads.Ad, error) {
 	return r0, r1
 }
 
-// ListAds provides a mock function with given fields: ctx
-func (_m *RepositryAd) ListAds(ctx context.Context) ([]*ads.Ad, error) {
+// GetCategory provides a mock function with given fields: ctx, categoryID
+func (_m *RepositryAd) GetCategory(ctx context.Context, categoryID int64) (*ads.Category, error) {
+	ret := _m.Called(ctx, categoryID)
+
+	var r0 *ads.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*ads.Category, error)); ok {
+		return rf(ctx, categoryID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *ads.Category); ok {
+		r0 = rf(ctx, categoryID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, categoryID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCategoryBySlug provides a mock function with given fields: ctx, slug
+func (_m *RepositryAd) GetCategoryBySlug(ctx context.Context, slug string) (*ads.Category, error) {
+	ret := _m.Called(ctx, slug)
+
+	var r0 *ads.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*ads.Category, error)); ok {
+		return rf(ctx, slug)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *ads.Category); ok {
+		r0 = rf(ctx, slug)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetReport provides a mock function with given fields: ctx, reportID
+func (_m *RepositryAd) GetReport(ctx context.Context, reportID int64) (*ads.Report, error) {
+	ret := _m.Called(ctx, reportID)
+
+	var r0 *ads.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (*ads.Report, error)); ok {
+		return rf(ctx, reportID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) *ads.Report); ok {
+		r0 = rf(ctx, reportID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, reportID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRevision provides a mock function with given fields: ctx, adID, number
+func (_m *RepositryAd) GetRevision(ctx context.Context, adID int64, number int) (*ads.Revision, error) {
+	ret := _m.Called(ctx, adID, number)
+
+	var r0 *ads.Revision
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) (*ads.Revision, error)); ok {
+		return rf(ctx, adID, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) *ads.Revision); ok {
+		r0 = rf(ctx, adID, number)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Revision)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = rf(ctx, adID, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ImagesLackingRenditions provides a mock function with given fields: ctx, names, before
+func (_m *RepositryAd) ImagesLackingRenditions(ctx context.Context, names []string, before time.Time) ([]*ads.Image, error) {
+	ret := _m.Called(ctx, names, before)
+
+	var r0 []*ads.Image
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string, time.Time) ([]*ads.Image, error)); ok {
+		return rf(ctx, names, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string, time.Time) []*ads.Image); ok {
+		r0 = rf(ctx, names, before)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Image)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string, time.Time) error); ok {
+		r1 = rf(ctx, names, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCategories provides a mock function with given fields: ctx
+func (_m *RepositryAd) ListCategories(ctx context.Context) ([]*ads.Category, error) {
 	ret := _m.Called(ctx)
 
-	var r0 []*ads.Ad
+	var r0 []*ads.Category
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context) ([]*ads.Ad, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context) ([]*ads.Category, error)); ok {
 		return rf(ctx)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context) []*ads.Ad); ok {
+	if rf, ok := ret.Get(0).(func(context.Context) []*ads.Category); ok {
 		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*ads.Ad)
+			r0 = ret.Get(0).([]*ads.Category)
 		}
 	}
 
@@ -142,25 +489,25 @@ func (_m *RepositryAd) ListAds(ctx context.Context) ([]*ads.Ad, error) {
 	return r0, r1
 }
 
-// ListAdsAuthor provides a mock function with given fields: ctx, author
-func (_m *RepositryAd) ListAdsAuthor(ctx context.Context, author int64) ([]*ads.Ad, error) {
-	ret := _m.Called(ctx, author)
+// ListReports provides a mock function with given fields: ctx, q
+func (_m *RepositryAd) ListReports(ctx context.Context, q ads.ReportQuery) ([]*ads.Report, error) {
+	ret := _m.Called(ctx, q)
 
-	var r0 []*ads.Ad
+	var r0 []*ads.Report
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Ad, error)); ok {
-		return rf(ctx, author)
+	if rf, ok := ret.Get(0).(func(context.Context, ads.ReportQuery) ([]*ads.Report, error)); ok {
+		return rf(ctx, q)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Ad); ok {
-		r0 = rf(ctx, author)
+	if rf, ok := ret.Get(0).(func(context.Context, ads.ReportQuery) []*ads.Report); ok {
+		r0 = rf(ctx, q)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*ads.Ad)
+			r0 = ret.Get(0).([]*ads.Report)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, author)
+	if rf, ok := ret.Get(1).(func(context.Context, ads.ReportQuery) error); ok {
+		r1 = rf(ctx, q)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -168,25 +515,25 @@ func (_m *RepositryAd) ListAdsAuthor(ctx context.Context, author int64) ([]*ads.
 	return r0, r1
 }
 
-// ListAdsDate provides a mock function with given fields: ctx, day
-func (_m *RepositryAd) ListAdsDate(ctx context.Context, day int64) ([]*ads.Ad, error) {
-	ret := _m.Called(ctx, day)
+// ListRevisions provides a mock function with given fields: ctx, adID
+func (_m *RepositryAd) ListRevisions(ctx context.Context, adID int64) ([]*ads.Revision, error) {
+	ret := _m.Called(ctx, adID)
 
-	var r0 []*ads.Ad
+	var r0 []*ads.Revision
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Ad, error)); ok {
-		return rf(ctx, day)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]*ads.Revision, error)); ok {
+		return rf(ctx, adID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Ad); ok {
-		r0 = rf(ctx, day)
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []*ads.Revision); ok {
+		r0 = rf(ctx, adID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*ads.Ad)
+			r0 = ret.Get(0).([]*ads.Revision)
 		}
 	}
 
 	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
-		r1 = rf(ctx, day)
+		r1 = rf(ctx, adID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -194,25 +541,65 @@ func (_m *RepositryAd) ListAdsDate(ctx context.Context, day int64) ([]*ads.Ad, e
 	return r0, r1
 }
 
-// Search provides a mock function with given fields: ctx, title
-func (_m *RepositryAd) Search(ctx context.Context, title string) ([]*ads.Ad, error) {
-	ret := _m.Called(ctx, title)
+// Renew provides a mock function with given fields: ctx, adID, from, expiresAt
+func (_m *RepositryAd) Renew(ctx context.Context, adID int64, from ads.Status, expiresAt time.Time) (*ads.Ad, error) {
+	ret := _m.Called(ctx, adID, from, expiresAt)
 
-	var r0 []*ads.Ad
+	var r0 *ads.Ad
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*ads.Ad, error)); ok {
-		return rf(ctx, title)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Status, time.Time) (*ads.Ad, error)); ok {
+		return rf(ctx, adID, from, expiresAt)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string) []*ads.Ad); ok {
-		r0 = rf(ctx, title)
+	if rf, ok := ret.Get(0).(func(context.Context, int64, ads.Status, time.Time) *ads.Ad); ok {
+		r0 = rf(ctx, adID, from, expiresAt)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*ads.Ad)
+			r0 = ret.Get(0).(*ads.Ad)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
-		r1 = rf(ctx, title)
+	if rf, ok := ret.Get(1).(func(context.Context, int64, ads.Status, time.Time) error); ok {
+		r1 = rf(ctx, adID, from, expiresAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReorderImages provides a mock function with given fields: ctx, adID, imageIDs
+func (_m *RepositryAd) ReorderImages(ctx context.Context, adID int64, imageIDs []int64) error {
+	ret := _m.Called(ctx, adID, imageIDs)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []int64) error); ok {
+		r0 = rf(ctx, adID, imageIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ResolveReports provides a mock function with given fields: ctx, adID, reportID, status, moderatorID
+func (_m *RepositryAd) ResolveReports(ctx context.Context, adID int64, reportID *int64, status ads.ReportStatus, moderatorID int64) ([]*ads.Report, error) {
+	ret := _m.Called(ctx, adID, reportID, status, moderatorID)
+
+	var r0 []*ads.Report
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64, ads.ReportStatus, int64) ([]*ads.Report, error)); ok {
+		return rf(ctx, adID, reportID, status, moderatorID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, *int64, ads.ReportStatus, int64) []*ads.Report); ok {
+		r0 = rf(ctx, adID, reportID, status, moderatorID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ads.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, *int64, ads.ReportStatus, int64) error); ok {
+		r1 = rf(ctx, adID, reportID, status, moderatorID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -220,25 +607,77 @@ func (_m *RepositryAd) Search(ctx context.Context, title string) ([]*ads.Ad, err
 	return r0, r1
 }
 
-// Update provides a mock function with given fields: ctx, authorID, title, text, adID
-func (_m *RepositryAd) Update(ctx context.Context, authorID int64, title string, text string, adID int64) (*ads.Ad, error) {
-	ret := _m.Called(ctx, authorID, title, text, adID)
+// Suggest provides a mock function with given fields: ctx, query, limit
+func (_m *RepositryAd) Suggest(ctx context.Context, query string, limit int) (*ads.Suggestions, error) {
+	ret := _m.Called(ctx, query, limit)
+
+	var r0 *ads.Suggestions
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) (*ads.Suggestions, error)); ok {
+		return rf(ctx, query, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) *ads.Suggestions); ok {
+		r0 = rf(ctx, query, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Suggestions)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int) error); ok {
+		r1 = rf(ctx, query, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: ctx, edit, screening, version, review
+func (_m *RepositryAd) Update(ctx context.Context, edit *ads.Revision, screening ads.Screening, version *int64, review bool) (*ads.Ad, error) {
+	ret := _m.Called(ctx, edit, screening, version, review)
 
 	var r0 *ads.Ad
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, int64) (*ads.Ad, error)); ok {
-		return rf(ctx, authorID, title, text, adID)
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Revision, ads.Screening, *int64, bool) (*ads.Ad, error)); ok {
+		return rf(ctx, edit, screening, version, review)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, int64) *ads.Ad); ok {
-		r0 = rf(ctx, authorID, title, text, adID)
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Revision, ads.Screening, *int64, bool) *ads.Ad); ok {
+		r0 = rf(ctx, edit, screening, version, review)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*ads.Ad)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string, int64) error); ok {
-		r1 = rf(ctx, authorID, title, text, adID)
+	if rf, ok := ret.Get(1).(func(context.Context, *ads.Revision, ads.Screening, *int64, bool) error); ok {
+		r1 = rf(ctx, edit, screening, version, review)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateCategory provides a mock function with given fields: ctx, category
+func (_m *RepositryAd) UpdateCategory(ctx context.Context, category *ads.Category) (*ads.Category, error) {
+	ret := _m.Called(ctx, category)
+
+	var r0 *ads.Category
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Category) (*ads.Category, error)); ok {
+		return rf(ctx, category)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *ads.Category) *ads.Category); ok {
+		r0 = rf(ctx, category)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*ads.Category)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *ads.Category) error); ok {
+		r1 = rf(ctx, category)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,358 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"ads/internal/ads"
+	"ads/internal/app"
+	grpcPort "ads/internal/ports/grpc"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStatusTransitions(t *testing.T) {
+	tests := []struct {
+		from ads.Status
+		to   ads.Status
+		can  bool
+	}{
+		{ads.StatusDraft, ads.StatusPendingReview, true},
+		{ads.StatusDraft, ads.StatusPublished, false},
+		{ads.StatusPendingReview, ads.StatusPublished, true},
+		{ads.StatusPendingReview, ads.StatusRejected, true},
+		{ads.StatusPublished, ads.StatusArchived, true},
+		{ads.StatusPublished, ads.StatusRejected, false},
+		{ads.StatusRejected, ads.StatusPublished, false},
+		{ads.StatusRejected, ads.StatusPendingReview, true},
+		{ads.StatusArchived, ads.StatusPendingReview, true},
+		{ads.StatusArchived, ads.StatusRejected, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.can, tt.from.CanBecome(tt.to), "%s to %s", tt.from, tt.to)
+	}
+	assert.False(t, ads.Status("deleted").Valid())
+}
+
+func TestReviewBeforePublish(t *testing.T) {
+	client := getTestClient(app.WithReview())
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	assert.Equal(t, "draft", ad.Data.Status)
+
+	ad, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", ad.Data.Status)
+	assert.False(t, ad.Data.Published)
+	assert.Nil(t, ad.Data.ExpiresAt)
+
+	_, err = client.listAds()
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	_, err = client.moderationQueue(author.Data.UserID, "")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.approveAd(author.Data.UserID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	queue, err := client.moderationQueue(moderatorID, "")
+	assert.NoError(t, err)
+	assert.Len(t, queue.Data, 1)
+	assert.Equal(t, ad.Data.ID, queue.Data[0].ID)
+
+	ad, err = client.approveAd(moderatorID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status)
+	assert.True(t, ad.Data.Published)
+	assert.NotNil(t, ad.Data.ExpiresAt)
+
+	_, err = client.approveAd(moderatorID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	list, err := client.listAds()
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 1)
+
+	queue, err = client.moderationQueue(moderatorID, "")
+	assert.NoError(t, err)
+	assert.Empty(t, queue.Data)
+}
+
+func TestReviewAfterEdit(t *testing.T) {
+	client := getTestClient(app.WithReview())
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	ad, err = client.approveAd(moderatorID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status)
+	published := ad.Data.Version
+
+	ad, err = client.updateAd(author.Data.UserID, ad.Data.ID, "hello", "buy it elsewhere")
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", ad.Data.Status, "an edit is approved before it is published")
+	assert.False(t, ad.Data.Published)
+	assert.Nil(t, ad.Data.ExpiresAt)
+	assert.Equal(t, published+1, ad.Data.Version, "the edit goes to review in the same change")
+	_, err = client.listAds()
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	queue, err := client.moderationQueue(moderatorID, "")
+	assert.NoError(t, err)
+	assert.Len(t, queue.Data, 1)
+	assert.Equal(t, "buy it elsewhere", queue.Data[0].Text)
+
+	ad, err = client.approveAd(moderatorID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status)
+
+	draft, err := client.createAd(author.Data.UserID, "draft", "world")
+	assert.NoError(t, err)
+	draft, err = client.updateAd(author.Data.UserID, draft.Data.ID, "draft", "new world")
+	assert.NoError(t, err)
+	assert.Equal(t, "draft", draft.Data.Status, "drafts are reviewed once submitted")
+}
+
+func TestRejectAd(t *testing.T) {
+	client := getTestClient(app.WithReview())
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+
+	_, err = client.rejectAd(moderatorID, ad.Data.ID, "spam")
+	assert.ErrorIs(t, err, ErrBadRequest, "a draft is not under review")
+
+	_, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+
+	_, err = client.rejectAd(moderatorID, ad.Data.ID, "  ")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.rejectAd(author.Data.UserID, ad.Data.ID, "looks fine to me")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	ad, err = client.rejectAd(moderatorID, ad.Data.ID, "no contacts in the text")
+	assert.NoError(t, err)
+	assert.Equal(t, "rejected", ad.Data.Status)
+	assert.Equal(t, "no contacts in the text", ad.Data.RejectionReason)
+
+	ad, err = client.getAd(ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "no contacts in the text", ad.Data.RejectionReason)
+
+	_, err = client.approveAd(moderatorID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	ad, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", ad.Data.Status)
+	assert.Empty(t, ad.Data.RejectionReason)
+}
+
+func TestWithdrawAndArchive(t *testing.T) {
+	client := getTestClient(app.WithReview())
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+
+	ad, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "draft", ad.Data.Status)
+
+	_, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	_, err = client.approveAd(moderatorID, ad.Data.ID)
+	assert.NoError(t, err)
+
+	ad, err = client.changeAdStatus(moderatorID, ad.Data.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "archived", ad.Data.Status)
+	assert.Nil(t, ad.Data.ExpiresAt)
+
+	_, err = client.renewAd(author.Data.UserID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrBadRequest, "a taken down ad is not renewed")
+
+	ad, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "archived", ad.Data.Status)
+
+	ad, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", ad.Data.Status)
+}
+
+func TestPublishWithoutReview(t *testing.T) {
+	client := getTestClient()
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(u.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+
+	ad, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status)
+
+	ad, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "archived", ad.Data.Status)
+
+	ad, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status)
+}
+
+func TestTakenDownWithoutReview(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+
+	ad, err = client.changeAdStatus(moderatorID, ad.Data.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "archived", ad.Data.Status)
+
+	ad, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", ad.Data.Status, "a taken down ad is reviewed again")
+
+	ad, err = client.rejectAd(moderatorID, ad.Data.ID, "spam")
+	assert.NoError(t, err)
+	assert.Equal(t, "rejected", ad.Data.Status)
+
+	ad, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", ad.Data.Status, "a rejected ad is reviewed again")
+
+	_, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, false)
+	assert.ErrorIs(t, err, ErrForbidden, "a held ad is not withdrawn from review")
+
+	_, err = client.approveAd(moderatorID, ad.Data.ID)
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, false)
+	assert.NoError(t, err)
+	ad, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status, "an approved ad is no longer held")
+}
+
+func TestModerationQueueOrder(t *testing.T) {
+	client := getTestClient(app.WithReview())
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	var ids []int64
+	for _, title := range []string{"first", "second", "third"} {
+		ad, err := client.createAd(author.Data.UserID, title, "text")
+		assert.NoError(t, err)
+		ids = append(ids, ad.Data.ID)
+	}
+	// submitted in reverse, so the queue is not in the order of ids
+	for i := len(ids) - 1; i >= 0; i-- {
+		_, err = client.changeAdStatus(author.Data.UserID, ids[i], true)
+		assert.NoError(t, err)
+	}
+	_, err = client.createAd(author.Data.UserID, "draft", "text")
+	assert.NoError(t, err)
+
+	queue, err := client.moderationQueue(moderatorID, "limit=2")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"third", "second"}, titles(queue.Data))
+	assert.NotEmpty(t, queue.NextCursor)
+
+	queue, err = client.moderationQueue(moderatorID, "limit=2&cursor="+queue.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first"}, titles(queue.Data))
+
+	_, err = client.listAdsQuery("status=pending_review&sort=title")
+	assert.ErrorIs(t, err, ErrForbidden, "only moderators list the ads under review")
+	_, err = client.listAdsQueryAs(author.Data.UserID, "status=pending_review")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.listAdsQuery("published=false")
+	assert.ErrorIs(t, err, ErrForbidden)
+	list, err := client.listAdsQueryAs(author.Data.UserID, fmt.Sprintf("status=pending_review&sort=title&author_id=%d", author.Data.UserID))
+	assert.NoError(t, err, "authors list their own ads")
+	assert.Equal(t, []string{"first", "second", "third"}, titles(list.Data))
+	list, err = client.listAdsQueryAs(moderatorID, "status=pending_review&sort=title")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second", "third"}, titles(list.Data))
+
+	_, err = client.listAdsQuery("status=hidden")
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestGRPCModeration(t *testing.T) {
+	client, ctx, a := newClient(t, app.WithReview())
+	authorCtx, _ := signedIn(t, a, ctx, "alex")
+	moderatorCtx, _ := signedInAs(t, a, ctx, "moderator", user.RoleModerator)
+
+	first, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "first", Text: "world"})
+	assert.NoError(t, err, "client.CreateAd")
+	second, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "second", Text: "world"})
+	assert.NoError(t, err, "client.CreateAd")
+	for _, ad := range []*grpcPort.AdResponse{first, second} {
+		ad, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+		assert.NoError(t, err, "client.ChangeAdStatus")
+		assert.Equal(t, "pending_review", ad.Status)
+	}
+
+	_, err = client.ModerationQueue(authorCtx, &grpcPort.ModerationQueueRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = client.ModerationQueue(ctx, &grpcPort.ModerationQueueRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	queue, err := client.ModerationQueue(moderatorCtx, &grpcPort.ModerationQueueRequest{})
+	assert.NoError(t, err, "client.ModerationQueue")
+	assert.Len(t, queue.List, 2)
+	assert.Equal(t, first.Id, queue.List[0].Ad.Id)
+	assert.Empty(t, queue.List[0].ScreeningFlags)
+
+	ad, err := client.ApproveAd(moderatorCtx, &grpcPort.ApproveAdRequest{AdId: first.Id})
+	assert.NoError(t, err, "client.ApproveAd")
+	assert.Equal(t, "published", ad.Status)
+	assert.True(t, ad.Published)
+
+	_, err = client.RejectAd(moderatorCtx, &grpcPort.RejectAdRequest{AdId: second.Id})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	ad, err = client.RejectAd(moderatorCtx, &grpcPort.RejectAdRequest{AdId: second.Id, Reason: "duplicate"})
+	assert.NoError(t, err, "client.RejectAd")
+	assert.Equal(t, "rejected", ad.Status)
+	assert.Equal(t, "duplicate", ad.RejectionReason)
+
+	_, err = client.ListAds(ctx, &grpcPort.ListAdsRequest{Status: "rejected"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	list, err := client.ListAds(moderatorCtx, &grpcPort.ListAdsRequest{Status: "rejected"})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, list.List, 1)
+	assert.Equal(t, second.Id, list.List[0].Id)
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"ads/internal/ads"
+	grpcPort "ads/internal/ports/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// publishAds creates and publishes an ad for each title.
+func publishAds(t *testing.T, client *testClient, userID int64, titles ...string) []adData {
+	var result []adData
+	for _, title := range titles {
+		published, err := client.publishAd(userID, map[string]any{"title": title})
+		assert.NoError(t, err)
+		result = append(result, published.Data)
+	}
+	return result
+}
+
+func titles(list []adData) []string {
+	result := make([]string, 0, len(list))
+	for _, ad := range list {
+		result = append(result, ad.Title)
+	}
+	return result
+}
+
+func TestListAdsPages(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	publishAds(t, client, u.Data.UserID, "d", "b", "e", "a", "c")
+
+	var seen []string
+	query := "limit=2&sort=title"
+	for pages := 0; ; pages++ {
+		assert.Less(t, pages, 3)
+		list, err := client.listAdsQuery(query)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, len(list.Data), 2)
+		seen = append(seen, titles(list.Data)...)
+		if list.NextCursor == "" {
+			break
+		}
+		query = "limit=2&sort=title&cursor=" + url.QueryEscape(list.NextCursor)
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, seen)
+}
+
+func TestListAdsPagesSurviveWrites(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	created := publishAds(t, client, u.Data.UserID, "b", "d", "f")
+
+	first, err := client.listAdsQuery("limit=2&sort=title")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"b", "d"}, titles(first.Data))
+
+	// one ad goes before the cursor and one after it, and a seen one is removed
+	publishAds(t, client, u.Data.UserID, "a", "e")
+	_, err = client.deleteAd(created[0].ID, u.Data.UserID)
+	assert.NoError(t, err)
+
+	second, err := client.listAdsQuery("limit=2&sort=title&cursor=" + url.QueryEscape(first.NextCursor))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"e", "f"}, titles(second.Data))
+	assert.Empty(t, second.NextCursor)
+}
+
+func TestListAdsSortOrders(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	publishAds(t, client, u.Data.UserID, "b", "c", "a")
+
+	type Test struct {
+		Sort   ads.Sort
+		Expect []string
+	}
+
+	tests := [...]Test{
+		{"", []string{"b", "c", "a"}},
+		{ads.SortCreatedAsc, []string{"b", "c", "a"}},
+		{ads.SortCreatedDesc, []string{"a", "c", "b"}},
+		{ads.SortUpdatedDesc, []string{"a", "c", "b"}},
+		{ads.SortTitleAsc, []string{"a", "b", "c"}},
+		{ads.SortTitleDesc, []string{"c", "b", "a"}},
+		// equal prices fall back to the order of ids
+		{ads.SortPriceDesc, []string{"b", "c", "a"}},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.Sort), func(t *testing.T) {
+			for limit := 1; limit <= 3; limit++ {
+				var seen []string
+				query := fmt.Sprintf("limit=%d&sort=%s", limit, test.Sort)
+				for {
+					list, err := client.listAdsQuery(query)
+					assert.NoError(t, err)
+					seen = append(seen, titles(list.Data)...)
+					if list.NextCursor == "" {
+						break
+					}
+					query = fmt.Sprintf("limit=%d&sort=%s&cursor=%s", limit, test.Sort, url.QueryEscape(list.NextCursor))
+				}
+				assert.Equal(t, test.Expect, seen, "limit %d", limit)
+			}
+		})
+	}
+}
+
+func TestListAdsAuthorPages(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	publishAds(t, client, u.Data.UserID, "a", "b", "c")
+
+	list, err := client.listAdsQueryAs(u.Data.UserID, fmt.Sprintf("filter=author&author_id=%d&limit=2&sort=-title", u.Data.UserID))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c", "b"}, titles(list.Data))
+	assert.NotEmpty(t, list.NextCursor)
+}
+
+func TestListAdsInvalidPage(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	publishAds(t, client, u.Data.UserID, "a", "b")
+
+	first, err := client.listAdsQuery("limit=1&sort=title")
+	assert.NoError(t, err)
+
+	queries := [...]string{
+		"limit=ten",
+		"limit=-1",
+		fmt.Sprintf("limit=%d", ads.MaxLimit+1),
+		"sort=author",
+		"sort=-",
+		"cursor=garbage",
+		// a cursor only fits the sort it was made for
+		"sort=-title&cursor=" + url.QueryEscape(first.NextCursor),
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			_, err := client.listAdsQuery(query)
+			assert.ErrorIs(t, err, ErrBadRequest)
+		})
+	}
+}
+
+func TestGRPCListAdsPages(t *testing.T) {
+	client, ctx, a := newClient(t)
+	authorCtx, _ := signedIn(t, a, ctx, "alex")
+
+	for _, title := range []string{"c", "a", "b"} {
+		ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: title, Text: "world"})
+		assert.NoError(t, err, "client.CreateAd")
+		_, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+		assert.NoError(t, err, "client.ChangeAdStatus")
+	}
+
+	first, err := client.ListAds(ctx, &grpcPort.ListAdsRequest{Limit: 2, Sort: "title"})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, first.List, 2)
+	assert.Equal(t, "a", first.List[0].Title)
+
+	second, err := client.ListAds(ctx, &grpcPort.ListAdsRequest{Limit: 2, Sort: "title", Cursor: first.NextCursor})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, second.List, 1)
+	assert.Equal(t, "c", second.List[0].Title)
+	assert.Empty(t, second.NextCursor)
+
+	_, err = client.ListAds(ctx, &grpcPort.ListAdsRequest{Sort: "price", Cursor: first.NextCursor})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
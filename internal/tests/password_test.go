@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"testing"
+
+	"ads/internal/adapters/adrepo"
+	"ads/internal/adapters/userrepo"
+	"ads/internal/app"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgon2idHasher(t *testing.T) {
+	hash, err := testHasher.Hash("qwerty")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	other, err := testHasher.Hash("qwerty")
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, other)
+
+	ok, rehash, err := testHasher.Verify("qwerty", hash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	ok, _, err = testHasher.Verify("qwerty1", hash)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestArgon2idHasherRehashOnNewParams(t *testing.T) {
+	hash, err := testHasher.Hash("qwerty")
+	assert.NoError(t, err)
+
+	stronger := app.NewArgon2idHasher(app.Argon2idParams{Memory: 2048, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	ok, rehash, err := stronger.Verify("qwerty", hash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash)
+}
+
+func TestBcryptHasher(t *testing.T) {
+	hasher := app.NewBcryptHasher(4)
+
+	hash, err := hasher.Hash("qwerty")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$2a$04$"))
+
+	ok, rehash, err := hasher.Verify("qwerty", hash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, rehash)
+
+	ok, _, err = hasher.Verify("qwerty1", hash)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSignInUpgradesLegacyHash(t *testing.T) {
+	ctx := context.Background()
+	users := userrepo.New()
+	a := app.NewApp(adrepo.New(), users, users, app.WithPasswordHasher(testHasher))
+
+	// the format generatePasswordHash stored before argon2id
+	sum := sha1.New()
+	sum.Write([]byte("qwerty"))
+	legacy := fmt.Sprintf("%x", sum.Sum([]byte("hjqrhjqw124617ajfhajs")))
+
+	id, err := users.CreateUserDb(user.User{NickName: "Alex", Username: "alex", Password: legacy})
+	assert.NoError(t, err)
+
+	_, err = a.GenerateToken(ctx, "alex", "qwerty1")
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+
+	u, err := users.GetUser(ctx, int64(id))
+	assert.NoError(t, err)
+	assert.Equal(t, legacy, u.Password)
+
+	_, err = a.GenerateToken(ctx, "alex", "qwerty")
+	assert.NoError(t, err)
+
+	u, err = users.GetUser(ctx, int64(id))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(u.Password, "$argon2id$"))
+
+	_, err = a.GenerateToken(ctx, "alex", "qwerty")
+	assert.NoError(t, err)
+}
+
+func TestSignInUpgradesBcryptHash(t *testing.T) {
+	ctx := context.Background()
+	users := userrepo.New()
+	old := app.NewApp(adrepo.New(), users, users, app.WithPasswordHasher(app.NewBcryptHasher(4)))
+	a := app.NewApp(adrepo.New(), users, users, app.WithPasswordHasher(testHasher))
+
+	id, err := old.CreateUserDb(user.User{NickName: "Alex", Username: "alex", Password: "qwerty"})
+	assert.NoError(t, err)
+
+	_, err = a.GenerateToken(ctx, "alex", "qwerty")
+	assert.NoError(t, err)
+
+	u, err := users.GetUser(ctx, int64(id))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(u.Password, "$argon2id$"))
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"ads/internal/adapters/pgrepo"
+	"ads/internal/ads"
+	"ads/internal/user"
+	"ads/schema"
+)
+
+// postgresAds returns the Postgres repository of ads on an empty, migrated
+// database at TEST_POSTGRES_DSN, e.g.
+// "host=localhost user=postgres password=qwerty sslmode=disable", and an
+// author for its ads. Without the variable the test is skipped.
+func postgresAds(t *testing.T) (*pgrepo.AdPostgres, int64) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	ctx := context.Background()
+
+	db, err := sqlx.Connect("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := pgrepo.NewMigrator(db, schema.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ExecContext(ctx, "TRUNCATE users, ads, categories RESTART IDENTITY CASCADE"); err != nil {
+		t.Fatal(err)
+	}
+
+	authorID, err := pgrepo.NewUserPostgres(db).AddUser(ctx, &user.User{NickName: "alex", Email: "alex@mai.com", Activate: true, Role: user.RoleUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pgrepo.NewAdPostgres(db), authorID
+}
+
+// addPostgresAd stores a published ad of authorID.
+func addPostgresAd(t *testing.T, repo *pgrepo.AdPostgres, authorID int64, title string, text string) *ads.Ad {
+	now := time.Now().UTC()
+	ad := ads.Ad{Title: title, Text: text, AuthorID: authorID, Currency: "RUB", Status: ads.StatusPublished, CreateDate: now, UpdateDate: now, Version: 1}
+	_, err := repo.Add(context.Background(), &ad)
+	assert.NoError(t, err)
+	return &ad
+}
+
+func TestPostgresSearchPhraseStaysInField(t *testing.T) {
+	repo, authorID := postgresAds(t)
+	bike := addPostgresAd(t, repo, authorID, "red bike", "for sale")
+	addPostgresAd(t, repo, authorID, "selling red", "bike in good shape")
+
+	published := true
+	for _, search := range []string{`"red bike"`, `"for sale"`, `"red bike" sale`} {
+		list, err := repo.Find(context.Background(), ads.Query{Published: &published, Search: search})
+		assert.NoError(t, err, search)
+		if assert.Len(t, list.Ads, 1, search) {
+			assert.Equal(t, bike.ID, list.Ads[0].ID, search)
+		}
+	}
+
+	list, err := repo.Find(context.Background(), ads.Query{Published: &published, Search: `"bike for"`})
+	assert.NoError(t, err)
+	assert.Empty(t, list.Ads, "a phrase does not run from the title into the text")
+}
+
+func TestPostgresRenewChecksStatus(t *testing.T) {
+	repo, authorID := postgresAds(t)
+	ad := addPostgresAd(t, repo, authorID, "red bike", "for sale")
+	ctx := context.Background()
+
+	_, err := repo.ChangeStatus(ctx, ad.ID, ads.StatusPublished, ads.StatusArchived, "", nil, true)
+	assert.NoError(t, err)
+	_, err = repo.Renew(ctx, ad.ID, ads.StatusPublished, time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, ads.ErrStatusChanged)
+
+	renewed, err := repo.Renew(ctx, ad.ID, ads.StatusArchived, time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, ads.StatusPublished, renewed.Status)
+}
+
+func TestPostgresUpdateForReview(t *testing.T) {
+	repo, authorID := postgresAds(t)
+	ad := addPostgresAd(t, repo, authorID, "red bike", "for sale")
+	ctx := context.Background()
+
+	edit := &ads.Revision{AdID: ad.ID, EditorID: authorID, Title: "red bike", Text: "call me", Currency: "RUB"}
+	updated, err := repo.Update(ctx, edit, ads.Screening{}, nil, true)
+	assert.NoError(t, err)
+	assert.Equal(t, ads.StatusPendingReview, updated.Status, "the edit is not published before it is reviewed")
+	assert.Equal(t, "call me", updated.Text)
+	assert.Equal(t, ad.Version+1, updated.Version)
+
+	edit = &ads.Revision{AdID: ad.ID, EditorID: authorID, Title: "red bike", Text: "call me later", Currency: "RUB"}
+	updated, err = repo.Update(ctx, edit, ads.Screening{}, nil, true)
+	assert.NoError(t, err)
+	assert.Equal(t, ads.StatusPendingReview, updated.Status)
+}
+
+func TestPostgresUpdateChecksVersion(t *testing.T) {
+	repo, authorID := postgresAds(t)
+	ad := addPostgresAd(t, repo, authorID, "red bike", "for sale")
+	ctx := context.Background()
+
+	stale := ad.Version + 1
+	edit := &ads.Revision{AdID: ad.ID, EditorID: authorID, Title: "red bike", Text: "stale", Currency: "RUB"}
+	_, err := repo.Update(ctx, edit, ads.Screening{}, &stale, false)
+	assert.ErrorIs(t, err, ads.ErrVersionMismatch)
+
+	// concurrent updates to the same version wait for the lock, and only the
+	// first one finds the ad still at it
+	const editors = 5
+	errs := make(chan error, editors)
+	for i := 0; i < editors; i++ {
+		go func() {
+			edit := &ads.Revision{AdID: ad.ID, EditorID: authorID, Title: "red bike", Text: "fresh", Currency: "RUB"}
+			_, err := repo.Update(ctx, edit, ads.Screening{}, &ad.Version, false)
+			errs <- err
+		}()
+	}
+	var updated, mismatched int
+	for i := 0; i < editors; i++ {
+		err := <-errs
+		if err == nil {
+			updated++
+		} else if assert.ErrorIs(t, err, ads.ErrVersionMismatch) {
+			mismatched++
+		}
+	}
+	assert.Equal(t, 1, updated)
+	assert.Equal(t, editors-1, mismatched)
+
+	got, err := repo.GetAd(ctx, ad.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, ad.Version+1, got.Version)
+	revisions, err := repo.ListRevisions(ctx, ad.ID)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 2, "the created ad and the one update")
+}
+
+func TestPostgresRestoreRevision(t *testing.T) {
+	repo, authorID := postgresAds(t)
+	ad := addPostgresAd(t, repo, authorID, "red bike", "for sale")
+	ctx := context.Background()
+
+	edit := &ads.Revision{AdID: ad.ID, EditorID: authorID, Title: "blue bike", Text: "sold", Currency: "RUB"}
+	updated, err := repo.Update(ctx, edit, ads.Screening{}, &ad.Version, false)
+	assert.NoError(t, err)
+
+	// restored as the app does it: the content of revision 1 as the next one
+	rev, err := repo.GetRevision(ctx, ad.ID, 1)
+	assert.NoError(t, err)
+	restore := *rev
+	restore.EditorID = authorID
+	restore.RestoredFrom = &rev.Number
+
+	_, err = repo.Update(ctx, &restore, ads.Screening{}, &ad.Version, false)
+	assert.ErrorIs(t, err, ads.ErrVersionMismatch, "a restore checks the version like any update")
+
+	restored, err := repo.Update(ctx, &restore, ads.Screening{}, &updated.Version, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "red bike", restored.Title)
+	assert.Equal(t, "for sale", restored.Text)
+	assert.Equal(t, updated.Version+1, restored.Version)
+
+	revisions, err := repo.ListRevisions(ctx, ad.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, revisions, 3) {
+		last := revisions[2]
+		assert.Equal(t, 3, last.Number)
+		if assert.NotNil(t, last.RestoredFrom) {
+			assert.Equal(t, 1, *last.RestoredFrom)
+		}
+		assert.Equal(t, "red bike", last.Title)
+	}
+}
@@ -0,0 +1,152 @@
+package tests
+
+import (
+	"testing"
+
+	grpcPort "ads/internal/ports/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateAdWithPrice(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAdPriced(u.Data.UserID, "bike", "for sale", 1500000, "USD")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1500000), ad.Data.Price)
+	assert.Equal(t, "USD", ad.Data.Currency)
+
+	ad, err = client.createAd(u.Data.UserID, "sofa", "for free")
+	assert.NoError(t, err)
+	assert.Zero(t, ad.Data.Price)
+	assert.Equal(t, "RUB", ad.Data.Currency)
+}
+
+func TestCreateAdWithInvalidPrice(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	_, err = client.createAdPriced(u.Data.UserID, "bike", "for sale", -1, "RUB")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	_, err = client.createAdPriced(u.Data.UserID, "bike", "for sale", 100, "usd")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	_, err = client.createAdPriced(u.Data.UserID, "bike", "for sale", 100, "XYZ")
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestUpdateAdPrice(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAdPriced(u.Data.UserID, "bike", "for sale", 1000, "EUR")
+	assert.NoError(t, err)
+
+	// without a currency the price is left as is
+	updated, err := client.updateAd(u.Data.UserID, ad.Data.ID, "bike", "still for sale")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1000), updated.Data.Price)
+	assert.Equal(t, "EUR", updated.Data.Currency)
+
+	updated, err = client.updateAdPriced(u.Data.UserID, ad.Data.ID, "bike", "cheaper", 800, "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(800), updated.Data.Price)
+
+	_, err = client.updateAdPriced(u.Data.UserID, ad.Data.ID, "bike", "cheaper", -800, "EUR")
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestListAdsByPrice(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	for _, price := range []int64{300, 100, 200} {
+		ad, err := client.createAdPriced(u.Data.UserID, "ad", "text", price, "RUB")
+		assert.NoError(t, err)
+		_, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, true)
+		assert.NoError(t, err)
+	}
+	ad, err := client.createAdPriced(u.Data.UserID, "ad", "text", 150, "USD")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	_, err = client.createAdPriced(u.Data.UserID, "draft", "text", 150, "RUB")
+	assert.NoError(t, err)
+
+	list, err := client.listAdsPrice("currency=RUB&sort=price")
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 3)
+	assert.Equal(t, []int64{100, 200, 300}, prices(list.Data))
+
+	list, err = client.listAdsPrice("min_price=150&max_price=300&sort=-price")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{300, 200, 150}, prices(list.Data))
+
+	list, err = client.listAdsPrice("currency=USD")
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 1)
+}
+
+func TestListAdsByPriceInvalid(t *testing.T) {
+	client := getTestClient()
+
+	queries := [...]string{
+		"min_price=ten",
+		"min_price=300&max_price=100",
+		"currency=rub",
+		"sort=cheapest",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			_, err := client.listAdsPrice(query)
+			assert.ErrorIs(t, err, ErrBadRequest)
+		})
+	}
+}
+
+func TestGRPCListAdsByPrice(t *testing.T) {
+	client, ctx, a := newClient(t)
+	authorCtx, _ := signedIn(t, a, ctx, "alex")
+
+	for _, price := range []int64{500, 100} {
+		ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world", Price: price, Currency: "EUR"})
+		assert.NoError(t, err, "client.CreateAd")
+		assert.Equal(t, price, ad.Price)
+		_, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+		assert.NoError(t, err, "client.ChangeAdStatus")
+	}
+
+	maxPrice := int64(400)
+	list, err := client.ListAds(ctx, &grpcPort.ListAdsRequest{MaxPrice: &maxPrice, Currency: "EUR"})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, list.List, 1)
+	assert.Equal(t, int64(100), list.List[0].Price)
+
+	list, err = client.ListAds(ctx, &grpcPort.ListAdsRequest{Sort: "-price"})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Equal(t, int64(500), list.List[0].Price)
+
+	_, err = client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world", Price: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func prices(list []adData) []int64 {
+	result := make([]int64, 0, len(list))
+	for _, ad := range list {
+		result = append(result, ad.Price)
+	}
+	return result
+}
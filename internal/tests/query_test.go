@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	grpcPort "ads/internal/ports/grpc"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestFindAdsComposedQuery(t *testing.T) {
+	client := getTestClient()
+
+	alex, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	bob, err := client.createAccount("bob", "bob@mai.com")
+	assert.NoError(t, err)
+
+	bike := publishAds(t, client, alex.Data.UserID, "Red Bike")[0]
+	publishAds(t, client, alex.Data.UserID, "sofa")
+	publishAds(t, client, bob.Data.UserID, "blue bike")
+	_, err = client.createAd(alex.Data.UserID, "draft", "old bike")
+	assert.NoError(t, err)
+
+	weekAgo := url.QueryEscape(time.Now().Add(-7 * 24 * time.Hour).Format(time.RFC3339))
+	list, err := client.listAdsQuery(fmt.Sprintf("author_id=%d&published=true&created_from=%s&text=BIKE", alex.Data.UserID, weekAgo))
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 1)
+	assert.Equal(t, bike.ID, list.Data[0].ID)
+
+	// the text is looked for in the body too
+	list, err = client.listAdsQueryAs(alex.Data.UserID, fmt.Sprintf("author_id=%d&published=any&text=bike", alex.Data.UserID))
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 2)
+
+	_, err = client.listAdsQuery(fmt.Sprintf("author_id=%d&published=false", alex.Data.UserID))
+	assert.ErrorIs(t, err, ErrForbidden, "the drafts are for the author")
+	list, err = client.listAdsQueryAs(alex.Data.UserID, fmt.Sprintf("author_id=%d&published=false", alex.Data.UserID))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"draft"}, titles(list.Data))
+
+	list, err = client.listAdsQuery("text=bike&sort=-title")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"blue bike", "Red Bike"}, titles(list.Data))
+}
+
+func TestFindAdsCreatedRange(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	ad := publishAds(t, client, u.Data.UserID, "hello")[0]
+
+	from := url.QueryEscape(ad.CreateDate.Add(-time.Minute).Format(time.RFC3339))
+	to := url.QueryEscape(ad.CreateDate.Add(time.Minute).Format(time.RFC3339))
+
+	list, err := client.listAdsQuery("created_from=" + from + "&created_to=" + to)
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 1)
+
+	_, err = client.listAdsQuery("created_from=" + to)
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestFindAdsCategoryAndPrice(t *testing.T) {
+	client := getTestClient()
+
+	adminID, err := client.createStaff("admin")
+	assert.NoError(t, err)
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	cars, err := client.createCategory(adminID, nil, "Cars", "cars")
+	assert.NoError(t, err)
+
+	client.categoryID = cars.Data.ID
+	for _, price := range []int64{100, 500} {
+		ad, err := client.createAdPriced(u.Data.UserID, "car", "text", price, "RUB")
+		assert.NoError(t, err)
+		_, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, true)
+		assert.NoError(t, err)
+	}
+
+	list, err := client.listAdsQuery("category=cars&max_price=300&currency=RUB")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{100}, prices(list.Data))
+
+	_, err = client.listAdsQuery("category=boats")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestFindAdsInvalidQuery(t *testing.T) {
+	client := getTestClient()
+
+	queries := [...]string{
+		"author_id=alex",
+		"published=yes",
+		"created_from=yesterday",
+		"created_from=2024-02-01T00:00:00Z&created_to=2024-01-01T00:00:00Z",
+		"day=32",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			_, err := client.listAdsQuery(query)
+			assert.ErrorIs(t, err, ErrBadRequest)
+		})
+	}
+}
+
+func TestGRPCFindAds(t *testing.T) {
+	client, ctx, a := newClient(t)
+	alexCtx, alexID := signedIn(t, a, ctx, "alex")
+	bobCtx, _ := signedIn(t, a, ctx, "bob")
+
+	for _, author := range []context.Context{alexCtx, bobCtx} {
+		ad, err := client.CreateAd(author, &grpcPort.CreateAdRequest{Title: "bike", Text: "for sale"})
+		assert.NoError(t, err, "client.CreateAd")
+		_, err = client.ChangeAdStatus(author, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+		assert.NoError(t, err, "client.ChangeAdStatus")
+	}
+	_, err := client.CreateAd(alexCtx, &grpcPort.CreateAdRequest{Title: "bike", Text: "draft"})
+	assert.NoError(t, err, "client.CreateAd")
+
+	from := timestamppb.New(time.Now().Add(-time.Hour))
+	list, err := client.ListAds(ctx, &grpcPort.ListAdsRequest{AuthorId: &alexID, Text: "BIKE", CreatedFrom: from})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, list.List, 1)
+
+	_, err = client.ListAds(bobCtx, &grpcPort.ListAdsRequest{AuthorId: &alexID, Published: "any"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	list, err = client.ListAds(alexCtx, &grpcPort.ListAdsRequest{AuthorId: &alexID, Published: "any"})
+	assert.NoError(t, err, "client.ListAds")
+	assert.Len(t, list.List, 2)
+
+	_, err = client.ListAds(ctx, &grpcPort.ListAdsRequest{CreatedFrom: timestamppb.New(time.Now().Add(time.Hour))})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.ListAds(ctx, &grpcPort.ListAdsRequest{Published: "maybe"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestLegacyFiltersHideDrafts(t *testing.T) {
+	client := getTestClient()
+	alex, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	bob, err := client.createAccount("bob", "bob@mai.com")
+	assert.NoError(t, err)
+	publishAds(t, client, alex.Data.UserID, "published")
+	_, err = client.createAd(alex.Data.UserID, "draft", "text")
+	assert.NoError(t, err)
+
+	byAuthor := fmt.Sprintf("filter=author&author_id=%d", alex.Data.UserID)
+	list, err := client.listAdsQuery(byAuthor)
+	assert.NoError(t, err, "the old filters stay public")
+	assert.Equal(t, []string{"published"}, titles(list.Data))
+	list, err = client.listAdsQueryAs(bob.Data.UserID, byAuthor)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"published"}, titles(list.Data))
+	list, err = client.listAdsQueryAs(alex.Data.UserID, byAuthor)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"published", "draft"}, titles(list.Data), "authors see their drafts")
+
+	byDate := fmt.Sprintf("filter=date&day=%d", time.Now().UTC().Day())
+	list, err = client.listAdsQuery(byDate)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"published"}, titles(list.Data))
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+	list, err = client.listAdsQueryAs(moderatorID, byDate)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"published", "draft"}, titles(list.Data))
+}
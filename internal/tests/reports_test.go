@@ -0,0 +1,272 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+
+	"ads/internal/app"
+	grpcPort "ads/internal/ports/grpc"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// publishedAd creates a published ad of a new user and returns the ids of
+// the user and the ad.
+func (tc *testClient) publishedAd(t *testing.T, name string) (int64, int64) {
+	author, err := tc.createAccount(name, name+"@mai.com")
+	assert.NoError(t, err)
+	ad, err := tc.publishAd(author.Data.UserID, map[string]any{"text": "world"})
+	assert.NoError(t, err)
+	return author.Data.UserID, ad.Data.ID
+}
+
+func TestReportAd(t *testing.T) {
+	client := getTestClient()
+	authorID, adID := client.publishedAd(t, "alex")
+	reader, err := client.createAccount("bob", "bob@mai.com")
+	assert.NoError(t, err)
+
+	report, err := client.reportAd(reader.Data.UserID, adID, "spam", " same ad every day ")
+	assert.NoError(t, err)
+	assert.Equal(t, adID, report.Data.AdID)
+	assert.Equal(t, reader.Data.UserID, report.Data.ReporterID)
+	assert.Equal(t, "spam", report.Data.Reason)
+	assert.Equal(t, "same ad every day", report.Data.Comment)
+	assert.Equal(t, "open", report.Data.Status)
+
+	_, err = client.reportAd(reader.Data.UserID, adID, "scam", "")
+	assert.ErrorIs(t, err, ErrBadRequest, "one report per user per ad")
+	_, err = client.reportAd(authorID, adID, "spam", "")
+	assert.ErrorIs(t, err, ErrBadRequest, "authors cannot report their own ads")
+
+	other, err := client.createAccount("carl", "carl@mai.com")
+	assert.NoError(t, err)
+	_, err = client.reportAd(other.Data.UserID, adID, "rude", "")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.reportAd(other.Data.UserID, adID, "other", " ")
+	assert.ErrorIs(t, err, ErrBadRequest, "other needs a comment")
+	_, err = client.reportAd(other.Data.UserID, 100, "spam", "")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	draft, err := client.createAd(authorID, "draft", "text")
+	assert.NoError(t, err)
+	_, err = client.reportAd(other.Data.UserID, draft.Data.ID, "spam", "")
+	assert.ErrorIs(t, err, ErrBadRequest, "only published ads can be reported")
+}
+
+func TestReportThresholdHidesAd(t *testing.T) {
+	client := getTestClient(app.WithReportThreshold(2))
+	_, adID := client.publishedAd(t, "alex")
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	bob, err := client.createAccount("bob", "bob@mai.com")
+	assert.NoError(t, err)
+	_, err = client.reportAd(bob.Data.UserID, adID, "spam", "")
+	assert.NoError(t, err)
+	ad, err := client.getAd(adID)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status)
+
+	carl, err := client.createAccount("carl", "carl@mai.com")
+	assert.NoError(t, err)
+	_, err = client.reportAd(carl.Data.UserID, adID, "scam", "")
+	assert.NoError(t, err)
+	ad, err = client.getAd(adID)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", ad.Data.Status)
+
+	_, err = client.listAdsQuery("")
+	assert.ErrorIs(t, err, ErrBadRequest, "the hidden ad is not listed")
+	_, err = client.listAdsQueryAs(bob.Data.UserID, "status=pending_review")
+	assert.ErrorIs(t, err, ErrForbidden, "readers cannot list the hidden ads")
+	_, err = client.searchAdsAs(carl.Data.UserID, "q=hello&published=any")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	queue, err := client.moderationQueue(moderatorID, "")
+	assert.NoError(t, err)
+	assert.Len(t, queue.Data, 1)
+
+	reports, err := client.listReports(moderatorID, fmt.Sprintf("ad_id=%d", adID))
+	assert.NoError(t, err)
+	assert.Len(t, reports.Data, 2)
+	for _, report := range reports.Data {
+		resolved, err := client.resolveReport(moderatorID, report.ID, "dismissed")
+		assert.NoError(t, err)
+		assert.Equal(t, "dismissed", resolved.Data.Status)
+		assert.Equal(t, &moderatorID, resolved.Data.ResolvedBy)
+	}
+	_, err = client.resolveReport(moderatorID, reports.Data[0].ID, "upheld")
+	assert.ErrorIs(t, err, ErrBadRequest, "the report is resolved already")
+
+	ad, err = client.approveAd(moderatorID, adID)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status)
+}
+
+func TestReportHiddenAdStaysInReview(t *testing.T) {
+	client := getTestClient(app.WithReportThreshold(1))
+	authorID, adID := client.publishedAd(t, "alex")
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+	bob, err := client.createAccount("bob", "bob@mai.com")
+	assert.NoError(t, err)
+
+	_, err = client.reportAd(bob.Data.UserID, adID, "spam", "")
+	assert.NoError(t, err)
+
+	_, err = client.changeAdStatus(authorID, adID, false)
+	assert.ErrorIs(t, err, ErrForbidden, "the author cannot take the hidden ad out of review")
+	ad, err := client.changeAdStatus(authorID, adID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", ad.Data.Status, "nor publish it without review")
+
+	_, err = client.getAdAs(bob.Data.UserID, adID)
+	assert.ErrorIs(t, err, ErrForbidden, "readers do not see the hidden ad")
+	_, err = client.getAdAs(-1, adID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	ad, err = client.getAdAs(authorID, adID)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", ad.Data.Status)
+	_, err = client.getAdAs(moderatorID, adID)
+	assert.NoError(t, err)
+
+	_, err = client.approveAd(moderatorID, adID)
+	assert.NoError(t, err)
+	ad, err = client.getAdAs(bob.Data.UserID, adID)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status)
+}
+
+func TestUpholdReport(t *testing.T) {
+	client := getTestClient(app.WithReportThreshold(0))
+	_, adID := client.publishedAd(t, "alex")
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	var reportIDs []int64
+	for _, name := range []string{"bob", "carl", "dan"} {
+		reader, err := client.createAccount(name, name+"@mai.com")
+		assert.NoError(t, err)
+		report, err := client.reportAd(reader.Data.UserID, adID, "prohibited", "")
+		assert.NoError(t, err)
+		reportIDs = append(reportIDs, report.Data.ID)
+	}
+	ad, err := client.getAd(adID)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status, "no threshold, the ad is never hidden")
+
+	_, err = client.resolveReport(moderatorID, reportIDs[0], "open")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	report, err := client.resolveReport(moderatorID, reportIDs[1], "upheld")
+	assert.NoError(t, err)
+	assert.Equal(t, "upheld", report.Data.Status)
+
+	ad, err = client.getAd(adID)
+	assert.NoError(t, err)
+	assert.Equal(t, "archived", ad.Data.Status)
+
+	open, err := client.listReports(moderatorID, "")
+	assert.NoError(t, err)
+	assert.Empty(t, open.Data, "the other reports of the ad are upheld too")
+	upheld, err := client.listReports(moderatorID, "status=upheld")
+	assert.NoError(t, err)
+	assert.Len(t, upheld.Data, 3)
+}
+
+func TestUpholdReportRejectsHiddenAd(t *testing.T) {
+	client := getTestClient(app.WithReportThreshold(1))
+	_, adID := client.publishedAd(t, "alex")
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+	reader, err := client.createAccount("bob", "bob@mai.com")
+	assert.NoError(t, err)
+
+	report, err := client.reportAd(reader.Data.UserID, adID, "offensive", "")
+	assert.NoError(t, err)
+	_, err = client.resolveReport(moderatorID, report.Data.ID, "upheld")
+	assert.NoError(t, err)
+
+	ad, err := client.getAd(adID)
+	assert.NoError(t, err)
+	assert.Equal(t, "rejected", ad.Data.Status)
+	assert.Equal(t, "reported as offensive", ad.Data.RejectionReason)
+}
+
+func TestListReports(t *testing.T) {
+	client := getTestClient(app.WithReportThreshold(0))
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+	reader, err := client.createAccount("bob", "bob@mai.com")
+	assert.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, adID := client.publishedAd(t, fmt.Sprintf("author%d", i))
+		_, err := client.reportAd(reader.Data.UserID, adID, "spam", "")
+		assert.NoError(t, err)
+	}
+
+	_, err = client.listReports(reader.Data.UserID, "")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.resolveReport(reader.Data.UserID, 0, "dismissed")
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.listReports(moderatorID, "status=closed")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.listReports(moderatorID, "cursor=abc")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	var ids []int64
+	query := "limit=2"
+	for {
+		page, err := client.listReports(moderatorID, query)
+		assert.NoError(t, err)
+		for _, report := range page.Data {
+			ids = append(ids, report.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query = "limit=2&cursor=" + page.NextCursor
+	}
+	assert.Equal(t, []int64{0, 1, 2, 3, 4}, ids)
+}
+
+func TestGRPCReports(t *testing.T) {
+	client, ctx, a := newClient(t, app.WithReportThreshold(1))
+	authorCtx, _ := signedIn(t, a, ctx, "alex")
+	readerCtx, _ := signedIn(t, a, ctx, "bob")
+	moderatorCtx, _ := signedInAs(t, a, ctx, "moderator", user.RoleModerator)
+
+	ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err, "client.CreateAd")
+	_, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+	assert.NoError(t, err, "client.ChangeAdStatus")
+
+	_, err = client.ReportAd(ctx, &grpcPort.ReportAdRequest{AdId: ad.Id, Reason: "spam"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.ReportAd(readerCtx, &grpcPort.ReportAdRequest{AdId: ad.Id, Reason: "rude"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	report, err := client.ReportAd(readerCtx, &grpcPort.ReportAdRequest{AdId: ad.Id, Reason: "spam"})
+	assert.NoError(t, err, "client.ReportAd")
+	assert.Equal(t, "open", report.Status)
+
+	_, err = client.ListReports(readerCtx, &grpcPort.ListReportsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	list, err := client.ListReports(moderatorCtx, &grpcPort.ListReportsRequest{AdId: &ad.Id})
+	assert.NoError(t, err, "client.ListReports")
+	assert.Len(t, list.Reports, 1)
+
+	report, err = client.ResolveReport(moderatorCtx, &grpcPort.ResolveReportRequest{ReportId: report.Id, Resolution: "upheld"})
+	assert.NoError(t, err, "client.ResolveReport")
+	assert.Equal(t, "upheld", report.Status)
+	assert.NotNil(t, report.ResolveDate)
+
+	queue, err := client.ModerationQueue(moderatorCtx, &grpcPort.ModerationQueueRequest{})
+	assert.NoError(t, err, "client.ModerationQueue")
+	assert.Empty(t, queue.List, "the hidden ad is rejected")
+}
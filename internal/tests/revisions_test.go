@@ -0,0 +1,191 @@
+package tests
+
+import (
+	"testing"
+
+	"ads/internal/app"
+	grpcPort "ads/internal/ports/grpc"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRevisions(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.updateAd(author.Data.UserID, ad.Data.ID, "hello", "new world")
+	assert.NoError(t, err)
+	_, err = client.updateAdPriced(author.Data.UserID, ad.Data.ID, "bike", "new world", 1000, "RUB")
+	assert.NoError(t, err)
+
+	list, err := client.listRevisions(author.Data.UserID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 3)
+	for i, rev := range list.Data {
+		assert.Equal(t, i+1, rev.Number)
+		assert.Equal(t, author.Data.UserID, rev.EditorID)
+	}
+	assert.Equal(t, "hello", list.Data[0].Title)
+	assert.Equal(t, "world", list.Data[0].Text)
+	assert.Equal(t, []changeData{{Field: "text", Old: "world", New: "new world"}}, list.Data[1].Diff)
+	assert.Equal(t, []changeData{
+		{Field: "title", Old: "hello", New: "bike"},
+		{Field: "price", Old: "0", New: "1000"},
+	}, list.Data[2].Diff)
+
+	rev, err := client.getRevision(author.Data.UserID, ad.Data.ID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "new world", rev.Data.Text)
+	_, err = client.getRevision(author.Data.UserID, ad.Data.ID, 4)
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.getRevision(author.Data.UserID, ad.Data.ID, 0)
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestRestoreRevision(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.updateAdPriced(author.Data.UserID, ad.Data.ID, "bike", "red bike", 500, "RUB")
+	assert.NoError(t, err)
+
+	restored, err := client.restoreRevision(author.Data.UserID, ad.Data.ID, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", restored.Data.Title)
+	assert.Equal(t, "world", restored.Data.Text)
+	assert.Equal(t, int64(0), restored.Data.Price)
+
+	list, err := client.listRevisions(author.Data.UserID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 3, "restoring adds a revision")
+	last := list.Data[2]
+	assert.Equal(t, 3, last.Number)
+	assert.Equal(t, 1, *last.RestoredFrom)
+	assert.Equal(t, "hello", last.Title)
+	assert.Len(t, last.Diff, 3)
+	assert.Nil(t, list.Data[1].RestoredFrom)
+
+	_, err = client.restoreRevision(author.Data.UserID, ad.Data.ID, 10)
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestRestoreRevisionIfMatch(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.updateAd(author.Data.UserID, ad.Data.ID, "bike", "world")
+	assert.NoError(t, err)
+
+	_, _, err = client.restoreRevisionIfMatch(author.Data.UserID, ad.Data.ID, 1, `"1"`, nil)
+	assert.ErrorIs(t, err, ErrPreconditionFailed, "the ad was updated since")
+	_, _, err = client.restoreRevisionIfMatch(author.Data.UserID, ad.Data.ID, 1, "", map[string]any{"expected_version": 1})
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	got, err := client.getAd(ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "bike", got.Data.Title)
+
+	restored, etag, err := client.restoreRevisionIfMatch(author.Data.UserID, ad.Data.ID, 1, `"2"`, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello", restored.Data.Title)
+	assert.Equal(t, `"3"`, etag)
+	_, _, err = client.restoreRevisionIfMatch(author.Data.UserID, ad.Data.ID, 2, "", map[string]any{"expected_version": 3})
+	assert.NoError(t, err)
+}
+
+func TestRestoreRevisionIsScreened(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(author.Data.UserID, "casino", "text")
+	assert.NoError(t, err)
+	_, err = client.updateAd(author.Data.UserID, ad.Data.ID, "bike", "text")
+	assert.NoError(t, err)
+
+	rules, err := app.ParseScreeningRules([]byte(testScreeningRules))
+	assert.NoError(t, err)
+	assert.NoError(t, client.app.SetScreeningRules(rules))
+
+	_, err = client.restoreRevision(author.Data.UserID, ad.Data.ID, 1)
+	assert.ErrorIs(t, err, ErrBadRequest, "the rules apply to restored content too")
+	list, err := client.listRevisions(author.Data.UserID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Len(t, list.Data, 2)
+}
+
+func TestRevisionsAccess(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	other, err := client.createAccount("bob", "bob@mai.com")
+	assert.NoError(t, err)
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.updateAd(author.Data.UserID, ad.Data.ID, "hello", "there")
+	assert.NoError(t, err)
+
+	_, err = client.listRevisions(other.Data.UserID, ad.Data.ID)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.getRevision(other.Data.UserID, ad.Data.ID, 1)
+	assert.ErrorIs(t, err, ErrForbidden)
+	_, err = client.restoreRevision(other.Data.UserID, ad.Data.ID, 1)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	list, err := client.listRevisions(moderatorID, ad.Data.ID)
+	assert.NoError(t, err, "moderators read the history for disputes")
+	assert.Len(t, list.Data, 2)
+	_, err = client.restoreRevision(moderatorID, ad.Data.ID, 1)
+	assert.ErrorIs(t, err, ErrForbidden, "only the author restores")
+
+	_, err = client.listRevisions(author.Data.UserID, 100)
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestGRPCRevisions(t *testing.T) {
+	client, ctx, a := newClient(t)
+	authorCtx, authorID := signedIn(t, a, ctx, "alex")
+	otherCtx, _ := signedIn(t, a, ctx, "bob")
+
+	ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err, "client.CreateAd")
+	_, err = client.UpdateAd(authorCtx, &grpcPort.UpdateAdRequest{AdId: ad.Id, Title: "bye", Text: "world"})
+	assert.NoError(t, err, "client.UpdateAd")
+
+	_, err = client.ListRevisions(ctx, &grpcPort.ListRevisionsRequest{AdId: ad.Id})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = client.ListRevisions(otherCtx, &grpcPort.ListRevisionsRequest{AdId: ad.Id})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	list, err := client.ListRevisions(authorCtx, &grpcPort.ListRevisionsRequest{AdId: ad.Id})
+	assert.NoError(t, err, "client.ListRevisions")
+	assert.Len(t, list.Revisions, 2)
+	assert.Equal(t, authorID, list.Revisions[1].EditorId)
+	assert.Len(t, list.Revisions[1].Diff, 1)
+	assert.Equal(t, "title", list.Revisions[1].Diff[0].Field)
+
+	rev, err := client.GetRevision(authorCtx, &grpcPort.GetRevisionRequest{AdId: ad.Id, Number: 1})
+	assert.NoError(t, err, "client.GetRevision")
+	assert.Equal(t, "hello", rev.Title)
+
+	_, err = client.RestoreRevision(authorCtx, &grpcPort.RestoreRevisionRequest{AdId: ad.Id, Number: 1, ExpectedVersion: &ad.Version})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	restored, err := client.RestoreRevision(authorCtx, &grpcPort.RestoreRevisionRequest{AdId: ad.Id, Number: 1})
+	assert.NoError(t, err, "client.RestoreRevision")
+	assert.Equal(t, "hello", restored.Title)
+
+	_, err = client.GetRevision(authorCtx, &grpcPort.GetRevisionRequest{AdId: ad.Id, Number: 9})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"ads/internal/app"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy(t *testing.T) {
+	author := app.Principal{UserID: 1, Role: user.RoleUser}
+	other := app.Principal{UserID: 2, Role: user.RoleUser}
+	moderator := app.Principal{UserID: 3, Role: user.RoleModerator}
+	admin := app.Principal{UserID: 4, Role: user.RoleAdmin}
+
+	type Test struct {
+		Name   string
+		Actor  app.Principal
+		Action app.Action
+		Expect bool
+	}
+
+	tests := [...]Test{
+		{"author updates ad", author, app.ActionUpdateAd, true},
+		{"author publishes ad", author, app.ActionPublishAd, true},
+		{"author deletes ad", author, app.ActionDeleteAd, true},
+		{"other updates ad", other, app.ActionUpdateAd, false},
+		{"other unpublishes ad", other, app.ActionUnpublishAd, false},
+		{"other deletes ad", other, app.ActionDeleteAd, false},
+		{"moderator updates ad", moderator, app.ActionUpdateAd, false},
+		{"moderator publishes ad", moderator, app.ActionPublishAd, false},
+		{"moderator unpublishes ad", moderator, app.ActionUnpublishAd, true},
+		{"moderator deletes ad", moderator, app.ActionDeleteAd, true},
+		{"admin deletes ad", admin, app.ActionDeleteAd, true},
+		{"user updates themselves", author, app.ActionUpdateUser, true},
+		{"user deletes another user", other, app.ActionDeleteUser, false},
+		{"moderator deletes a user", moderator, app.ActionDeleteUser, false},
+		{"admin deletes a user", admin, app.ActionDeleteUser, true},
+		{"user sets own role", author, app.ActionSetUserRole, false},
+		{"moderator sets a role", moderator, app.ActionSetUserRole, false},
+		{"admin sets a role", admin, app.ActionSetUserRole, true},
+		{"admin revokes sessions", admin, app.ActionRevokeUserSessions, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			assert.Equal(t, test.Expect, app.Can(test.Actor, test.Action, author.UserID))
+		})
+	}
+}
+
+func TestModeratorTakesDownAd(t *testing.T) {
+	client := getTestClient()
+
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	_, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+
+	_, err = client.updateAd(moderatorID, ad.Data.ID, "bye", "world")
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	response, err := client.changeAdStatus(moderatorID, ad.Data.ID, false)
+	assert.NoError(t, err)
+	assert.False(t, response.Data.Published)
+	assert.Equal(t, author.Data.UserID, response.Data.AuthorID)
+
+	_, err = client.changeAdStatus(moderatorID, ad.Data.ID, true)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	response, err = client.deleteAd(ad.Data.ID, moderatorID)
+	assert.NoError(t, err)
+	assert.Equal(t, ad.Data.ID, response.Data.ID)
+}
+
+func TestUserCannotManageAnotherUser(t *testing.T) {
+	client := getTestClient()
+
+	alex, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	oleg, err := client.createAccount("oleg", "oleg@mai.com")
+	assert.NoError(t, err)
+
+	client.tokens[alex.Data.UserID] = client.tokens[oleg.Data.UserID]
+
+	_, err = client.updateUser("alexey", "alex@mai.com", alex.Data.UserID, true)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = client.deleteUser(alex.Data.UserID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	err = client.revokeUserSessions(oleg.Data.UserID, alex.Data.UserID)
+	assert.ErrorIs(t, err, ErrForbidden)
+
+	_, err = client.setUserRole(oleg.Data.UserID, oleg.Data.UserID, user.RoleAdmin)
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestAdminManagesUsers(t *testing.T) {
+	client := getTestClient()
+
+	alex, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	adminID, err := client.createStaff(user.RoleAdmin)
+	assert.NoError(t, err)
+
+	_, err = client.setUserRole(adminID, alex.Data.UserID, "owner")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	response, err := client.setUserRole(adminID, alex.Data.UserID, user.RoleModerator)
+	assert.NoError(t, err)
+	assert.Equal(t, "moderator", response.Data.Role)
+
+	// the role change signed alex out
+	_, err = client.createAd(alex.Data.UserID, "hello", "world")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	token, err := client.signIn("user0", "qwerty")
+	assert.NoError(t, err)
+	client.tokens[alex.Data.UserID] = token.Token
+
+	err = client.revokeUserSessions(adminID, alex.Data.UserID)
+	assert.NoError(t, err)
+
+	_, err = client.createAd(alex.Data.UserID, "hello", "world")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	delete(client.tokens, alex.Data.UserID)
+	_, err = client.updateUser("alexey", "alex@mai.com", alex.Data.UserID, true)
+	assert.NoError(t, err)
+
+	_, err = client.deleteUser(alex.Data.UserID)
+	assert.NoError(t, err)
+}
+
+func TestForbiddenUserChangeAnsweredOnce(t *testing.T) {
+	client := getTestClient()
+	alex, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	oleg, err := client.createAccount("oleg", "oleg@mai.com")
+	assert.NoError(t, err)
+
+	for _, method := range []string{http.MethodPut, http.MethodDelete} {
+		path := "/api/v1/user/update/%d"
+		if method == http.MethodDelete {
+			path = "/api/v1/user/delete/%d"
+		}
+		body := fmt.Sprintf(`{"user_id": %d, "nickname": "alexey", "email": "alex@mai.com"}`, alex.Data.UserID)
+		req, err := http.NewRequest(method, fmt.Sprintf(client.baseURL+path, alex.Data.UserID), strings.NewReader(body))
+		assert.NoError(t, err)
+		req.Header.Add("Content-Type", "application/json")
+		client.authorize(req, oleg.Data.UserID)
+
+		resp, err := client.client.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode, method)
+
+		dec := json.NewDecoder(resp.Body)
+		var answer map[string]any
+		assert.NoError(t, dec.Decode(&answer), method)
+		assert.ErrorIs(t, dec.Decode(&answer), io.EOF, "%s answers with one response only", method)
+		resp.Body.Close()
+	}
+}
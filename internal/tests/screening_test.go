@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"testing"
+
+	"ads/internal/ads"
+	"ads/internal/app"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testScreeningRules = `[
+  {"name": "banned", "kind": "terms", "terms": ["casino", "easy money"], "action": "reject"},
+  {"name": "links", "kind": "links", "max": 1, "action": "flag", "score": 2},
+  {"name": "phones", "kind": "regexp", "pattern": "\\+7\\d{10}", "action": "score", "score": 1.5},
+  {"name": "copies", "kind": "duplicate", "action": "flag", "min_length": 20}
+]`
+
+func getScreeningClient(t *testing.T, opts ...app.Option) *testClient {
+	client := getTestClient(opts...)
+	rules, err := app.ParseScreeningRules([]byte(testScreeningRules))
+	assert.NoError(t, err)
+	assert.NoError(t, client.app.SetScreeningRules(rules))
+	return client
+}
+
+// screening reads the screening of an ad from the app, as the API shows it
+// in the moderation queue only.
+func (tc *testClient) screening(t *testing.T, adID int64) ads.Screening {
+	ad, err := tc.app.GetAd(operatorContext(), adID)
+	assert.NoError(t, err)
+	return ad.Screening
+}
+
+func TestScreeningRejects(t *testing.T) {
+	client := getScreeningClient(t)
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	_, err = client.createAd(u.Data.UserID, "Best CASINO", "come in")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	_, err = client.createAd(u.Data.UserID, "hello", "make easy, money")
+	assert.ErrorIs(t, err, ErrBadRequest, "the words of a term go together whatever is between them")
+
+	ad, err := client.createAd(u.Data.UserID, "casinos", "money is not easy")
+	assert.NoError(t, err, "terms match whole words in order only")
+
+	_, err = client.updateAd(u.Data.UserID, ad.Data.ID, "casino", "text")
+	assert.ErrorIs(t, err, ErrBadRequest)
+	ad, err = client.getAd(ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "casinos", ad.Data.Title)
+}
+
+func TestScreeningFlags(t *testing.T) {
+	client := getScreeningClient(t)
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	moderatorID, err := client.createStaff(user.RoleModerator)
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(u.Data.UserID, "bike", "see https://example.com")
+	assert.NoError(t, err)
+	assert.Empty(t, client.screening(t, ad.Data.ID).Flags, "one link is allowed")
+
+	ad, err = client.createAd(u.Data.UserID, "bike", "see https://example.com and www.example.org")
+	assert.NoError(t, err)
+	assert.Equal(t, ads.Screening{Score: 2, Flags: "links"}, client.screening(t, ad.Data.ID))
+	assert.Empty(t, ad.Data.ScreeningFlags, "only moderators see the screening")
+
+	ad, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", ad.Data.Status, "a flagged ad is reviewed even without WithReview")
+
+	got, err := client.getAd(ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, got.Data.ScreeningFlags)
+	assert.Zero(t, got.Data.ScreeningScore)
+	queue, err := client.moderationQueue(moderatorID, "")
+	assert.NoError(t, err)
+	assert.Len(t, queue.Data, 1)
+	assert.Equal(t, "links", queue.Data[0].ScreeningFlags)
+	assert.Equal(t, 2.0, queue.Data[0].ScreeningScore)
+
+	ad, err = client.approveAd(moderatorID, ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status)
+}
+
+func TestScreeningFlagsPublishedAd(t *testing.T) {
+	client := getScreeningClient(t)
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(u.Data.UserID, "bike", "call +79991234567")
+	assert.NoError(t, err)
+	assert.Equal(t, ads.Screening{Score: 1.5}, client.screening(t, ad.Data.ID))
+
+	ad, err = client.changeAdStatus(u.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, "published", ad.Data.Status, "a score alone does not hold the ad")
+
+	ad, err = client.updateAd(u.Data.UserID, ad.Data.ID, "bike", "http://a.example http://b.example")
+	assert.NoError(t, err)
+	assert.Equal(t, "pending_review", ad.Data.Status)
+	assert.Equal(t, ads.Screening{Score: 2, Flags: "links"}, client.screening(t, ad.Data.ID))
+}
+
+func TestScreeningDuplicates(t *testing.T) {
+	client := getScreeningClient(t)
+	alex, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	bob, err := client.createAccount("bob", "bob@mai.com")
+	assert.NoError(t, err)
+
+	const text = "Selling a red bike, almost new"
+	first, err := client.createAd(alex.Data.UserID, "bike", text)
+	assert.NoError(t, err)
+	assert.Empty(t, client.screening(t, first.Data.ID).Flags)
+
+	draft, err := client.createAd(bob.Data.UserID, "bike", text)
+	assert.NoError(t, err)
+	assert.Empty(t, client.screening(t, draft.Data.ID).Flags, "drafts are not copied from")
+
+	_, err = client.changeAdStatus(alex.Data.UserID, first.Data.ID, true)
+	assert.NoError(t, err)
+	first, err = client.updateAd(alex.Data.UserID, first.Data.ID, "red bike", text)
+	assert.NoError(t, err)
+	assert.Empty(t, client.screening(t, first.Data.ID).Flags, "an ad is no copy of itself")
+	own, err := client.createAd(alex.Data.UserID, "bike", text)
+	assert.NoError(t, err)
+	assert.Empty(t, client.screening(t, own.Data.ID).Flags, "nor of another ad of the author")
+
+	second, err := client.createAd(bob.Data.UserID, "bike", "selling a RED bike, almost new")
+	assert.NoError(t, err)
+	assert.Equal(t, "copies", client.screening(t, second.Data.ID).Flags)
+
+	short, err := client.createAd(bob.Data.UserID, "bike", "bike")
+	assert.NoError(t, err)
+	assert.Empty(t, client.screening(t, short.Data.ID).Flags, "short texts are not compared")
+}
+
+func TestSetScreeningRules(t *testing.T) {
+	client := getScreeningClient(t)
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	invalid := [][]app.ScreeningRule{
+		{{Name: "bad", Kind: app.RuleRegexp, Pattern: "(", Action: app.RuleFlag}},
+		{{Name: "bad", Kind: "smell", Action: app.RuleFlag}},
+		{{Name: "bad", Kind: app.RuleLinks, Action: "ban"}},
+		{{Name: "bad", Kind: app.RuleTerms, Terms: []string{" ! "}, Action: app.RuleReject}},
+		{{Name: "", Kind: app.RuleLinks, Action: app.RuleFlag}},
+		{{Name: "twice", Kind: app.RuleLinks, Action: app.RuleFlag}, {Name: "twice", Kind: app.RuleLinks, Action: app.RuleScore}},
+	}
+	for _, rules := range invalid {
+		assert.Error(t, client.app.SetScreeningRules(rules))
+	}
+	_, err = client.createAd(u.Data.UserID, "casino", "text")
+	assert.ErrorIs(t, err, ErrBadRequest, "the rules in use are kept")
+
+	assert.NoError(t, client.app.SetScreeningRules([]app.ScreeningRule{{Name: "bingo", Kind: app.RuleTerms, Terms: []string{"bingo"}, Action: app.RuleReject}}))
+	_, err = client.createAd(u.Data.UserID, "casino", "text")
+	assert.NoError(t, err)
+	_, err = client.createAd(u.Data.UserID, "bingo", "text")
+	assert.ErrorIs(t, err, ErrBadRequest)
+
+	assert.NoError(t, client.app.SetScreeningRules(nil))
+	_, err = client.createAd(u.Data.UserID, "bingo", "text")
+	assert.NoError(t, err)
+
+	_, err = app.ParseScreeningRules([]byte(`{"name": "not a list"}`))
+	assert.Error(t, err)
+}
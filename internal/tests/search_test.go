@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	grpcPort "ads/internal/ports/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// publishSearchAds publishes three ads about bikes and keeps a fourth one
+// in drafts.
+func publishSearchAds(t *testing.T, client *testClient) (userID int64, published []adData) {
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	for _, ad := range [][2]string{
+		{"Red bike", "almost new"},
+		{"Sofa", "fits a bike"},
+		{"Bike lights", "for a red bike"},
+	} {
+		ad, err := client.publishAd(u.Data.UserID, map[string]any{"title": ad[0], "text": ad[1]})
+		assert.NoError(t, err)
+		published = append(published, ad.Data)
+	}
+
+	_, err = client.createAd(u.Data.UserID, "Bike", "draft")
+	assert.NoError(t, err)
+
+	return u.Data.UserID, published
+}
+
+func TestSearchAdsRanking(t *testing.T) {
+	client := getTestClient()
+	authorID, _ := publishSearchAds(t, client)
+
+	type Test struct {
+		Query  string
+		Params string
+		Expect []string
+	}
+
+	tests := [...]Test{
+		// a word in the title weighs more than one in the text
+		{"bike", "", []string{"Bike lights", "Red bike", "Sofa"}},
+		{"BIKE red", "", []string{"Red bike", "Bike lights"}},
+		{`"red bike"`, "", []string{"Red bike", "Bike lights"}},
+		{`"red bike" lights`, "", []string{"Bike lights"}},
+		// equal ranks fall back to the order of ids
+		{"bike", fmt.Sprintf("&published=any&author_id=%d", authorID), []string{"Bike lights", "Red bike", "Bike", "Sofa"}},
+		{"bike", "&sort=title", []string{"Bike lights", "Red bike", "Sofa"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Query+test.Params, func(t *testing.T) {
+			list, err := client.searchAdsAs(authorID, "q="+url.QueryEscape(test.Query)+test.Params)
+			assert.NoError(t, err)
+			assert.Equal(t, test.Expect, titles(list.Data))
+		})
+	}
+
+	list, err := client.searchAds("q=bike")
+	assert.NoError(t, err)
+	for i := 1; i < len(list.Data); i++ {
+		assert.Greater(t, list.Data[i-1].Rank, list.Data[i].Rank)
+	}
+}
+
+func TestSearchAdsPhrases(t *testing.T) {
+	client := getTestClient()
+	publishSearchAds(t, client)
+
+	// the words must come in order and a phrase doesn't run from the title
+	// into the text
+	for _, query := range []string{`"bike red"`, `"lights for"`, "scooter"} {
+		_, err := client.searchAds("q=" + url.QueryEscape(query))
+		assert.ErrorIs(t, err, ErrBadRequest, query)
+	}
+}
+
+func TestSearchAdsPages(t *testing.T) {
+	client := getTestClient()
+	publishSearchAds(t, client)
+
+	var seen []string
+	query := "q=bike&limit=1"
+	for pages := 0; ; pages++ {
+		assert.Less(t, pages, 3)
+		list, err := client.searchAds(query)
+		assert.NoError(t, err)
+		seen = append(seen, titles(list.Data)...)
+		if list.NextCursor == "" {
+			break
+		}
+		query = "q=bike&limit=1&cursor=" + url.QueryEscape(list.NextCursor)
+	}
+
+	assert.Equal(t, []string{"Bike lights", "Red bike", "Sofa"}, seen)
+}
+
+func TestSearchAdsFollowsWrites(t *testing.T) {
+	client := getTestClient()
+	userID, published := publishSearchAds(t, client)
+
+	_, err := client.updateAd(userID, published[1].ID, "Sofa", "big and soft")
+	assert.NoError(t, err)
+	_, err = client.deleteAd(published[0].ID, userID)
+	assert.NoError(t, err)
+
+	list, err := client.searchAds("q=bike")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bike lights"}, titles(list.Data))
+
+	list, err = client.searchAds("q=soft")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Sofa"}, titles(list.Data))
+
+	// the old API named the query title
+	list, err = client.searchAdByName("sofa")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Sofa"}, titles(list.Data))
+}
+
+func TestSearchAdsInvalid(t *testing.T) {
+	client := getTestClient()
+	publishSearchAds(t, client)
+
+	for _, query := range []string{"", "q=", "q=%21%3F", "q=bike&sort=-relevance"} {
+		_, err := client.searchAds(query)
+		assert.ErrorIs(t, err, ErrBadRequest, query)
+	}
+
+	_, err := client.listAdsQuery("sort=relevance")
+	assert.ErrorIs(t, err, ErrBadRequest)
+}
+
+func TestGRPCSearchAds(t *testing.T) {
+	client, ctx, a := newClient(t)
+	authorCtx, authorID := signedIn(t, a, ctx, "alex")
+
+	for _, title := range []string{"old bike", "bike"} {
+		ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: title, Text: "for a bike ride"})
+		assert.NoError(t, err, "client.CreateAd")
+		_, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+		assert.NoError(t, err, "client.ChangeAdStatus")
+	}
+	_, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "bike", Text: "draft"})
+	assert.NoError(t, err, "client.CreateAd")
+
+	list, err := client.SearchAds(ctx, &grpcPort.SearchAdsRequest{Query: "Bike"})
+	assert.NoError(t, err, "client.SearchAds")
+	assert.Len(t, list.List, 2)
+	assert.Positive(t, list.List[0].Rank)
+
+	_, err = client.SearchAds(ctx, &grpcPort.SearchAdsRequest{Query: "bike", Filter: &grpcPort.ListAdsRequest{Published: "any"}})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	list, err = client.SearchAds(authorCtx, &grpcPort.SearchAdsRequest{Query: `"old bike"`, Filter: &grpcPort.ListAdsRequest{Published: "any", AuthorId: &authorID}})
+	assert.NoError(t, err, "client.SearchAds")
+	assert.Len(t, list.List, 1)
+	assert.Equal(t, "old bike", list.List[0].Title)
+
+	list, err = client.SearchAds(authorCtx, &grpcPort.SearchAdsRequest{Query: "bike", Filter: &grpcPort.ListAdsRequest{Published: "any", AuthorId: &authorID, Limit: 2}})
+	assert.NoError(t, err, "client.SearchAds")
+	assert.Len(t, list.List, 2)
+	assert.NotEmpty(t, list.NextCursor)
+
+	_, err = client.SearchAds(ctx, &grpcPort.SearchAdsRequest{Query: " "})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
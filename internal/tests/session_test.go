@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ads/internal/adapters/adrepo"
+	"ads/internal/adapters/userrepo"
+	"ads/internal/app"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshRotatesTokens(t *testing.T) {
+	client := getTestClient()
+
+	signed, err := client.signUp("Alex", "alex", "qwerty")
+	assert.NoError(t, err)
+
+	first, err := client.signIn("alex", "qwerty")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.RefreshToken)
+
+	second, err := client.refresh(first.RefreshToken)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+	client.tokens[signed.ID] = second.Token
+	_, err = client.createAd(signed.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	third, err := client.refresh(second.RefreshToken)
+	assert.NoError(t, err)
+
+	client.tokens[signed.ID] = third.Token
+	_, err = client.createAd(signed.ID, "hello", "world")
+	assert.NoError(t, err)
+}
+
+func TestRefreshTokenReuseRevokesFamily(t *testing.T) {
+	client := getTestClient()
+
+	signed, err := client.signUp("Alex", "alex", "qwerty")
+	assert.NoError(t, err)
+
+	first, err := client.signIn("alex", "qwerty")
+	assert.NoError(t, err)
+
+	second, err := client.refresh(first.RefreshToken)
+	assert.NoError(t, err)
+
+	_, err = client.refresh(first.RefreshToken)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = client.refresh(second.RefreshToken)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	client.tokens[signed.ID] = second.Token
+	_, err = client.createAd(signed.ID, "hello", "world")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	other, err := client.signIn("alex", "qwerty")
+	assert.NoError(t, err)
+
+	client.tokens[signed.ID] = other.Token
+	_, err = client.createAd(signed.ID, "hello", "world")
+	assert.NoError(t, err)
+}
+
+func TestRefreshUnknownToken(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.refresh("token")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestSignOut(t *testing.T) {
+	client := getTestClient()
+
+	signed, err := client.signUp("Alex", "alex", "qwerty")
+	assert.NoError(t, err)
+
+	phone, err := client.signIn("alex", "qwerty")
+	assert.NoError(t, err)
+	laptop, err := client.signIn("alex", "qwerty")
+	assert.NoError(t, err)
+
+	err = client.signOut(phone.Token)
+	assert.NoError(t, err)
+
+	client.tokens[signed.ID] = phone.Token
+	_, err = client.createAd(signed.ID, "hello", "world")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = client.refresh(phone.RefreshToken)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	client.tokens[signed.ID] = laptop.Token
+	_, err = client.createAd(signed.ID, "hello", "world")
+	assert.NoError(t, err)
+
+	err = client.signOut("token")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestRefreshTokenExpired(t *testing.T) {
+	ctx := context.Background()
+	users := userrepo.New()
+	a := app.NewApp(adrepo.New(), users, users, app.WithPasswordHasher(testHasher), app.WithRefreshTokenTTL(-time.Minute))
+
+	_, err := a.CreateUserDb(user.User{NickName: "Alex", Username: "alex", Password: "qwerty"})
+	assert.NoError(t, err)
+
+	tokens, err := a.GenerateToken(ctx, "alex", "qwerty")
+	assert.NoError(t, err)
+
+	_, err = a.RefreshToken(ctx, tokens.RefreshToken)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
+
+func TestRevokeUserSessions(t *testing.T) {
+	ctx := context.Background()
+	users := userrepo.New()
+	a := app.NewApp(adrepo.New(), users, users, app.WithPasswordHasher(testHasher))
+
+	id, err := a.CreateUserDb(user.User{NickName: "Alex", Username: "alex", Password: "qwerty"})
+	assert.NoError(t, err)
+	_, err = a.CreateUserDb(user.User{NickName: "Oleg", Username: "oleg", Password: "qwerty"})
+	assert.NoError(t, err)
+
+	alex, err := a.GenerateToken(ctx, "alex", "qwerty")
+	assert.NoError(t, err)
+	oleg, err := a.GenerateToken(ctx, "oleg", "qwerty")
+	assert.NoError(t, err)
+
+	err = a.RevokeUserSessions(ctx, int64(id))
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+
+	principal, err := a.ParseToken(ctx, oleg.AccessToken)
+	assert.NoError(t, err)
+	err = a.RevokeUserSessions(app.ContextWithPrincipal(ctx, principal), int64(id))
+	assert.ErrorIs(t, err, app.ErrForbidden)
+
+	principal, err = a.ParseToken(ctx, alex.AccessToken)
+	assert.NoError(t, err)
+	err = a.RevokeUserSessions(app.ContextWithPrincipal(ctx, principal), int64(id))
+	assert.NoError(t, err)
+
+	_, err = a.ParseToken(ctx, alex.AccessToken)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+	_, err = a.RefreshToken(ctx, alex.RefreshToken)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+
+	_, err = a.ParseToken(ctx, oleg.AccessToken)
+	assert.NoError(t, err)
+}
+
+func TestDeleteUserRevokesSessions(t *testing.T) {
+	ctx := context.Background()
+	users := userrepo.New()
+	a := app.NewApp(adrepo.New(), users, users, app.WithPasswordHasher(testHasher))
+
+	id, err := a.CreateUserDb(user.User{NickName: "Alex", Username: "alex", Password: "qwerty"})
+	assert.NoError(t, err)
+
+	tokens, err := a.GenerateToken(ctx, "alex", "qwerty")
+	assert.NoError(t, err)
+
+	principal, err := a.ParseToken(ctx, tokens.AccessToken)
+	assert.NoError(t, err)
+
+	err = a.DeleteUser(app.ContextWithPrincipal(ctx, principal), int64(id))
+	assert.NoError(t, err)
+
+	_, err = a.ParseToken(ctx, tokens.AccessToken)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
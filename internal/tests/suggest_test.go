@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"testing"
+
+	"ads/internal/ads"
+	grpcPort "ads/internal/ports/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func suggested(list []suggestionData) []string {
+	result := make([]string, 0, len(list))
+	for _, s := range list {
+		result = append(result, s.Title)
+	}
+	return result
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, ads.Similarity("Bicycle", "bicycle"))
+	assert.Equal(t, 0.0, ads.Similarity("bicycle", "sofa"))
+	// "  c", " ca", "cat", "at " and "  c", " co", "cot", "ot " share one
+	assert.InDelta(t, 1.0/7, ads.Similarity("cat", "cot"), 1e-9)
+	assert.InDelta(t, 5.0/11, ads.Similarity("bicycel", "bicycle"), 1e-9)
+}
+
+func TestSuggestAds(t *testing.T) {
+	client := getTestClient()
+
+	u, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	publishAds(t, client, u.Data.UserID, "Bicycle helmet", "Bicycle", "bicycle", "Bike")
+	_, err = client.createAd(u.Data.UserID, "Bicycle pump", "draft")
+	assert.NoError(t, err)
+
+	response, err := client.suggestAds("q=BIC")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bicycle", "Bicycle helmet"}, suggested(response.Data.Completions))
+	assert.Empty(t, response.Data.Corrections)
+	assert.Greater(t, response.Data.Completions[0].Score, response.Data.Completions[1].Score)
+
+	response, err = client.suggestAds("q=bicycel")
+	assert.NoError(t, err)
+	assert.Empty(t, response.Data.Completions)
+	assert.Equal(t, []string{"Bicycle"}, suggested(response.Data.Corrections))
+
+	response, err = client.suggestAds("q=bic&limit=1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Bicycle"}, suggested(response.Data.Completions))
+
+	response, err = client.suggestAds("q=scooter")
+	assert.NoError(t, err)
+	assert.Empty(t, response.Data.Completions)
+	assert.Empty(t, response.Data.Corrections)
+}
+
+func TestSuggestAdsInvalid(t *testing.T) {
+	client := getTestClient()
+
+	for _, query := range []string{"", "q=+", "q=bic&limit=ten", "q=bic&limit=-1", "q=bic&limit=21"} {
+		_, err := client.suggestAds(query)
+		assert.ErrorIs(t, err, ErrBadRequest, query)
+	}
+}
+
+func TestGRPCSuggestAds(t *testing.T) {
+	client, ctx, a := newClient(t)
+	authorCtx, _ := signedIn(t, a, ctx, "alex")
+
+	for _, title := range []string{"Laptop", "Laptop bag"} {
+		ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: title, Text: "for sale"})
+		assert.NoError(t, err, "client.CreateAd")
+		_, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+		assert.NoError(t, err, "client.ChangeAdStatus")
+	}
+
+	response, err := client.SuggestAds(ctx, &grpcPort.SuggestAdsRequest{Query: "lap"})
+	assert.NoError(t, err, "client.SuggestAds")
+	assert.Len(t, response.Completions, 2)
+	assert.Equal(t, "Laptop", response.Completions[0].Title)
+
+	response, err = client.SuggestAds(ctx, &grpcPort.SuggestAdsRequest{Query: "labtop"})
+	assert.NoError(t, err, "client.SuggestAds")
+	assert.Empty(t, response.Completions)
+	assert.Len(t, response.Corrections, 1)
+	assert.Equal(t, "Laptop", response.Corrections[0].Title)
+
+	_, err = client.SuggestAds(ctx, &grpcPort.SuggestAdsRequest{Query: ""})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
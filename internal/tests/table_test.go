@@ -9,7 +9,7 @@ import (
 func TestTableTestCreateAd(t *testing.T) {
 	client := getTestClient()
 
-	u, err := client.createUser("nick", "@")
+	u, err := client.createAccount("nick", "@")
 	assert.NoError(t, err)
 
 	type AdField struct {
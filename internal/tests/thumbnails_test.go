@@ -0,0 +1,259 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"testing"
+	"time"
+
+	"ads/internal/adapters/adrepo"
+	"ads/internal/adapters/fsblob"
+	"ads/internal/adapters/userrepo"
+	"ads/internal/ads"
+	"ads/internal/app"
+	grpcPort "ads/internal/ports/grpc"
+	"ads/internal/thumbnail"
+	"ads/internal/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRenditions = []ads.RenditionSpec{
+	{Name: "thumb", Width: 50, Height: 50, Format: "jpeg"},
+	{Name: "wide", Width: 100, Format: "png"},
+}
+
+// halves is an image of width by height, red on the left and blue on the
+// right.
+func halves(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, image.Rect(0, 0, width/2, height), image.NewUniform(color.RGBA{R: 255, A: 255}), image.Point{}, draw.Src)
+	draw.Draw(img, image.Rect(width/2, 0, width, height), image.NewUniform(color.RGBA{B: 255, A: 255}), image.Point{}, draw.Src)
+	return img
+}
+
+// jpegWithOrientation encodes img with an EXIF segment holding orientation.
+func jpegWithOrientation(t *testing.T, img image.Image, orientation byte) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, jpeg.Encode(&buf, img, nil))
+	data := buf.Bytes()
+
+	exif := []byte("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08\x00\x01\x01\x12\x00\x03\x00\x00\x00\x01\x00")
+	exif = append(exif, orientation, 0, 0, 0, 0, 0, 0)
+	segment := append([]byte{0xFF, 0xE1, 0, byte(len(exif) + 2)}, exif...)
+
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r > 0xc000 && g < 0x4000 && b < 0x4000
+}
+
+func isBlue(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return b > 0xc000 && r < 0x4000 && g < 0x4000
+}
+
+func TestFitRendition(t *testing.T) {
+	w, h := thumbnail.Fit(400, 200, 100, 100)
+	assert.Equal(t, []int{100, 50}, []int{w, h})
+	w, h = thumbnail.Fit(400, 200, 0, 50)
+	assert.Equal(t, []int{100, 50}, []int{w, h})
+	w, h = thumbnail.Fit(40, 20, 100, 100)
+	assert.Equal(t, []int{40, 20}, []int{w, h})
+	w, h = thumbnail.Fit(1000, 1, 10, 10)
+	assert.Equal(t, []int{10, 1}, []int{w, h})
+}
+
+func TestParseRenditionSpecs(t *testing.T) {
+	specs, err := ads.ParseRenditionSpecs("thumb:160x160:jpeg, wide:1280x0:png")
+	assert.NoError(t, err)
+	assert.Equal(t, []ads.RenditionSpec{
+		{Name: "thumb", Width: 160, Height: 160, Format: "jpeg"},
+		{Name: "wide", Width: 1280, Height: 0, Format: "png"},
+	}, specs)
+
+	for _, value := range []string{"", "thumb", "thumb:160:jpeg", "thumb:0x0:jpeg", "thumb:160x160:gif", "a_b:10x10:png", "a:10x10:png,a:20x20:png"} {
+		_, err := ads.ParseRenditionSpecs(value)
+		assert.Error(t, err, value)
+	}
+
+	assert.Equal(t, "ads/1/abc_thumb.jpg", ads.RenditionKey("ads/1/abc.png", specs[0]))
+	assert.Equal(t, "ads/1/abc_wide.png", ads.RenditionKey("ads/1/abc.png", specs[1]))
+}
+
+func TestRenderThumbnail(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, halves(400, 200)))
+
+	result, err := thumbnail.Render(buf.Bytes(), ads.RenditionSpec{Name: "small", Width: 100, Height: 100, Format: "png"})
+	assert.NoError(t, err)
+	assert.Equal(t, 100, result.Width)
+	assert.Equal(t, 50, result.Height)
+
+	img, format, err := image.Decode(bytes.NewReader(result.Data))
+	assert.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, image.Rect(0, 0, 100, 50), img.Bounds())
+	assert.True(t, isRed(img.At(10, 25)))
+	assert.True(t, isBlue(img.At(90, 25)))
+
+	_, err = thumbnail.Render([]byte("not an image"), testRenditions[0])
+	assert.Error(t, err)
+}
+
+func TestRenderThumbnailOrientation(t *testing.T) {
+	data := jpegWithOrientation(t, halves(40, 20), 6)
+	assert.Equal(t, 6, thumbnail.Orientation(data))
+
+	result, err := thumbnail.Render(data, ads.RenditionSpec{Name: "small", Width: 100, Height: 100, Format: "jpeg"})
+	assert.NoError(t, err)
+	assert.Equal(t, 20, result.Width)
+	assert.Equal(t, 40, result.Height)
+	assert.NotContains(t, string(result.Data), "Exif")
+	assert.Equal(t, 1, thumbnail.Orientation(result.Data))
+
+	// turned right, the left half is on top
+	img, err := jpeg.Decode(bytes.NewReader(result.Data))
+	assert.NoError(t, err)
+	assert.True(t, isRed(img.At(10, 5)))
+	assert.True(t, isBlue(img.At(10, 35)))
+
+	assert.Equal(t, 1, thumbnail.Orientation(gifImage))
+	assert.Equal(t, 1, thumbnail.Orientation(jpegWithOrientation(t, halves(4, 2), 9)))
+}
+
+func TestUploadImageThumbnails(t *testing.T) {
+	store, err := fsblob.New(t.TempDir(), "/media")
+	assert.NoError(t, err)
+	media := newMediaServer(t, store)
+	client := getTestClient(app.WithBlobStore(store), app.WithThumbnails(testRenditions, 2))
+
+	u, err := client.createAccount("alex", "alex@mail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(u.Data.UserID, "Bicycle", "red")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, halves(200, 100)))
+	ad, err = client.uploadImages(u.Data.UserID, ad.Data.ID, buf.Bytes(), gifImage)
+	assert.NoError(t, err)
+	assert.Len(t, ad.Data.Images, 2)
+
+	// waits for the workers
+	assert.NoError(t, client.app.Close())
+
+	images, err := client.listImages(ad.Data.ID)
+	assert.NoError(t, err)
+	renditions := images[0].Renditions
+	assert.Len(t, renditions, 2)
+	assert.Equal(t, "thumb", renditions[0].Name)
+	assert.Equal(t, "image/jpeg", renditions[0].ContentType)
+	assert.Equal(t, []int{50, 25}, []int{renditions[0].Width, renditions[0].Height})
+	assert.Equal(t, "wide", renditions[1].Name)
+	assert.Equal(t, "image/png", renditions[1].ContentType)
+	assert.Equal(t, []int{100, 50}, []int{renditions[1].Width, renditions[1].Height})
+	assert.Len(t, images[1].Renditions, 2)
+
+	code, body := download(t, media, renditions[0].URL)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, renditions[0].Size, int64(len(body)))
+	img, format, err := image.Decode(bytes.NewReader(body))
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, image.Rect(0, 0, 50, 25), img.Bounds())
+
+	_, err = client.deleteImage(u.Data.UserID, ad.Data.ID, images[0].ID)
+	assert.NoError(t, err)
+	for _, r := range renditions {
+		code, _ := download(t, media, r.URL)
+		assert.Equal(t, http.StatusNotFound, code)
+	}
+}
+
+func TestGRPCImageRenditions(t *testing.T) {
+	store, err := fsblob.New(t.TempDir(), "/media")
+	assert.NoError(t, err)
+	client, ctx, a := newClient(t, app.WithBlobStore(store), app.WithThumbnails(testRenditions, 1))
+	authorCtx, authorID := signedIn(t, a, ctx, "alex")
+
+	ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err)
+
+	principal := app.ContextWithPrincipal(ctx, app.Principal{UserID: authorID, Role: user.RoleUser})
+	pic := jpegWithOrientation(t, halves(40, 20), 8)
+	_, err = a.AddImage(principal, ad.Id, bytes.NewReader(pic), int64(len(pic)))
+	assert.NoError(t, err)
+	assert.NoError(t, a.Close())
+
+	ad, err = client.ChangeAdStatus(authorCtx, &grpcPort.ChangeAdStatusRequest{AdId: ad.Id, Published: true})
+	assert.NoError(t, err)
+	renditions := ad.Images[0].Renditions
+	assert.Len(t, renditions, 2)
+	assert.Equal(t, "thumb", renditions[0].Name)
+	assert.Equal(t, int32(20), renditions[0].Width)
+	assert.Equal(t, int32(40), renditions[0].Height)
+	assert.Contains(t, renditions[0].Url, "_thumb.jpg")
+}
+
+func TestRetryRenditions(t *testing.T) {
+	ctx := context.Background()
+	store, err := fsblob.New(t.TempDir(), "/media")
+	assert.NoError(t, err)
+	repo := adrepo.New()
+	users := userrepo.New()
+
+	adID, err := repo.Add(ctx, &ads.Ad{Title: "Bicycle", Text: "red", AuthorID: 1, Status: ads.StatusDraft, Version: 1})
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, halves(200, 100)))
+	for i, key := range []string{"ads/1/a.png", "ads/1/b.png"} {
+		assert.NoError(t, store.Put(ctx, key, "image/png", bytes.NewReader(buf.Bytes()), int64(buf.Len())))
+		// uploaded by a run that stopped before making their renditions
+		image := ads.Image{AdID: adID, Key: key, URL: store.URL(key), ContentType: "image/png", Size: int64(buf.Len()), Position: i, CreateDate: time.Now().UTC().Add(-time.Hour)}
+		_, err = repo.AddImage(ctx, &image)
+		assert.NoError(t, err)
+	}
+	_, err = repo.AddRendition(ctx, adID, &ads.Rendition{ImageID: 1, Name: "thumb", Key: "ads/1/b_thumb.jpg", ContentType: "image/jpeg"})
+	assert.NoError(t, err)
+
+	a := app.NewApp(repo, users, users, app.WithBlobStore(store), app.WithThumbnails(testRenditions, 1))
+	assert.NoError(t, a.Close())
+
+	ad, err := repo.GetAd(ctx, adID)
+	assert.NoError(t, err)
+	var names [][]string
+	for _, image := range ad.Images {
+		var imageNames []string
+		for _, r := range image.Renditions {
+			imageNames = append(imageNames, r.Name)
+		}
+		names = append(names, imageNames)
+	}
+	assert.Equal(t, [][]string{{"thumb", "wide"}, {"thumb", "wide"}}, names, "only the missing renditions are made")
+	assert.Equal(t, "ads/1/b_thumb.jpg", ad.Images[1].Renditions[0].Key)
+
+	principal := app.ContextWithPrincipal(ctx, app.Principal{UserID: 1, Role: user.RoleUser})
+	_, err = a.AddImage(principal, adID, bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err, "an upload after Close leaves its renditions to the retry")
+}
+
+func TestUploadUndecodableImage(t *testing.T) {
+	store, err := fsblob.New(t.TempDir(), "/media")
+	assert.NoError(t, err)
+	client := getTestClient(app.WithBlobStore(store), app.WithThumbnails(testRenditions, 1))
+	u, err := client.createAccount("alex", "alex@mail.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(u.Data.UserID, "Bicycle", "red")
+	assert.NoError(t, err)
+
+	_, err = client.uploadImages(u.Data.UserID, ad.Data.ID, []byte("\x89PNG\r\n\x1a\nnot really a png"))
+	assert.ErrorIs(t, err, ErrUnsupportedMedia, "an image without renditions is refused")
+}
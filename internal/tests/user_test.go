@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"testing"
 
+	"ads/internal/user"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,6 +27,9 @@ func TestUserUpdate(t *testing.T) {
 	response, err := client.createUser("hello", "world")
 	assert.NoError(t, err)
 
+	_, err = client.createStaff(user.RoleAdmin)
+	assert.NoError(t, err)
+
 	response, err = client.updateUser(response.Data.NickName, response.Data.Email, response.Data.UserID, true)
 	assert.NoError(t, err)
 	assert.True(t, response.Data.Activate)
@@ -47,6 +52,9 @@ func TestDeleteUser(t *testing.T) {
 	_, err = client.createUser("hello", "world")
 	assert.NoError(t, err)
 
+	_, err = client.createStaff(user.RoleAdmin)
+	assert.NoError(t, err)
+
 	response, err := client.deleteUser(1)
 	assert.NoError(t, err)
 	assert.Equal(t, response.Data.UserID, int64(1))
@@ -95,5 +103,48 @@ func TestErrDeleteUser(t *testing.T) {
 func TestUserUpdateErr(t *testing.T) {
 	client := getTestClient()
 	_, err := client.updateUser("NickName", "Email", 10, true)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	_, err = client.createStaff(user.RoleAdmin)
+	assert.NoError(t, err)
+
+	_, err = client.updateUser("NickName", "Email", 10, true)
 	assert.ErrorIs(t, err, ErrNotFound)
 }
+
+func TestSignUpUserIsRegularUser(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.createUser("hello", "world")
+	assert.NoError(t, err)
+
+	signed, err := client.signUp("Alex", "alex", "qwerty")
+	assert.NoError(t, err)
+	assert.Equal(t, signed.ID, int64(1))
+
+	response, err := client.getUser(signed.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, response.Data.NickName, "Alex")
+	assert.Equal(t, response.Data.Role, "user")
+
+	token, err := client.signIn("alex", "qwerty")
+	assert.NoError(t, err)
+	client.tokens[signed.ID] = token.Token
+
+	response, err = client.updateUser("Alexey", "alex@mai.com", signed.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, response.Data.Email, "alex@mai.com")
+
+	_, err = client.deleteUser(signed.ID)
+	assert.NoError(t, err)
+}
+
+func TestSignUpUsernameTaken(t *testing.T) {
+	client := getTestClient()
+
+	_, err := client.signUp("Alex", "alex", "qwerty")
+	assert.NoError(t, err)
+
+	_, err = client.signUp("Alexey", "alex", "qwerty")
+	assert.Error(t, err)
+}
@@ -2,9 +2,11 @@ package tests
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"time"
@@ -14,6 +16,7 @@ import (
 	"ads/internal/app"
 	"ads/internal/ports/httpgin"
 	"ads/internal/tests/mocks"
+	"ads/internal/user"
 
 	"github.com/sirupsen/logrus"
 )
@@ -24,8 +27,41 @@ type adData struct {
 	Text      string `json:"text"`
 	AuthorID  int64  `json:"author_id"`
 	Published bool   `json:"published"`
+	Status    string `json:"status"`
+	RejectionReason string `json:"rejection_reason"`
+	ScreeningScore float64 `json:"screening_score"`
+	ScreeningFlags string `json:"screening_flags"`
+	CategoryID int64 `json:"category_id"`
+	Price     int64  `json:"price"`
+	Currency  string `json:"currency"`
 	CreateDate time.Time `json:"create_date"`
 	UpdateDate time.Time `json:"update_date"`
+	Version   int64     `json:"version"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	Lat       *float64 `json:"lat"`
+	Lon       *float64 `json:"lon"`
+	City      string   `json:"city"`
+	Rank      float64 `json:"rank"`
+	DistanceKm *float64 `json:"distance_km"`
+	Images    []imageData `json:"images"`
+}
+
+type imageData struct {
+	ID          int64  `json:"id"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Position    int    `json:"position"`
+	Renditions  []renditionData `json:"renditions"`
+}
+
+type renditionData struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int64  `json:"size"`
 }
 
 type adResponse struct {
@@ -33,7 +69,101 @@ type adResponse struct {
 }
 
 type adsResponse struct {
-	Data []adData `json:"data"`
+	Data       []adData    `json:"data"`
+	NextCursor string      `json:"next_cursor"`
+	Facets     *facetsData `json:"facets"`
+}
+
+type idCountData struct {
+	ID    int64 `json:"id"`
+	Count int64 `json:"count"`
+}
+
+type facetsData struct {
+	Categories []idCountData `json:"categories"`
+	Authors    []idCountData `json:"authors"`
+	Prices     []struct {
+		Min   int64  `json:"min"`
+		Max   *int64 `json:"max"`
+		Count int64  `json:"count"`
+	} `json:"prices"`
+	Months []struct {
+		Month string `json:"month"`
+		Count int64  `json:"count"`
+	} `json:"months"`
+}
+
+type categoryData struct {
+	ID       int64  `json:"id"`
+	ParentID *int64 `json:"parent_id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+}
+
+type categoryResponse struct {
+	Data categoryData `json:"data"`
+}
+
+type categoriesResponse struct {
+	Data []categoryData `json:"data"`
+}
+
+type suggestionData struct {
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+type suggestionsResponse struct {
+	Data struct {
+		Completions []suggestionData `json:"completions"`
+		Corrections []suggestionData `json:"corrections"`
+	} `json:"data"`
+}
+
+type reportData struct {
+	ID         int64  `json:"id"`
+	AdID       int64  `json:"ad_id"`
+	ReporterID int64  `json:"reporter_id"`
+	Reason     string `json:"reason"`
+	Comment    string `json:"comment"`
+	Status     string `json:"status"`
+	ResolvedBy *int64 `json:"resolved_by"`
+}
+
+type reportResponse struct {
+	Data reportData `json:"data"`
+}
+
+type reportsResponse struct {
+	Data       []reportData `json:"data"`
+	NextCursor string       `json:"next_cursor"`
+}
+
+type changeData struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+type revisionData struct {
+	ID           int64        `json:"id"`
+	AdID         int64        `json:"ad_id"`
+	Number       int          `json:"number"`
+	EditorID     int64        `json:"editor_id"`
+	Title        string       `json:"title"`
+	Text         string       `json:"text"`
+	Price        int64        `json:"price"`
+	Currency     string       `json:"currency"`
+	RestoredFrom *int         `json:"restored_from"`
+	Diff         []changeData `json:"diff"`
+}
+
+type revisionResponse struct {
+	Data revisionData `json:"data"`
+}
+
+type revisionsResponse struct {
+	Data []revisionData `json:"data"`
 }
 
 type userData struct {
@@ -41,12 +171,22 @@ type userData struct {
 	NickName string `json:"nickname"`
 	Email   string `json:"email"`
 	Activate bool	`json:"activate"`
+	Role    string `json:"role"`
 }
 
 type userResponse struct {
 	Data userData `json:"data"`
 }
 
+type signUpResponse struct {
+	ID int64 `json:"id"`
+}
+
+type signInResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
 type userDeleteData struct {
 	UserID  int64 `json:"user_id"`
 }
@@ -59,104 +199,258 @@ var (
 	ErrBadRequest = fmt.Errorf("bad request")
 	ErrForbidden  = fmt.Errorf("forbidden")
 	ErrNotFound = fmt.Errorf("not found user in db")
+	ErrUnauthorized = fmt.Errorf("unauthorized")
+	ErrTooLarge = fmt.Errorf("request entity too large")
+	ErrUnsupportedMedia = fmt.Errorf("unsupported media type")
+	ErrPreconditionFailed = fmt.Errorf("precondition failed")
 )
 
 type testClient struct {
 	client  *http.Client
 	baseURL string
+	app     app.App
+	// tokens holds access tokens of accounts made by createAccount.
+	tokens map[int64]string
+	// adminID is -1 until createStaff makes an admin.
+	adminID int64
+	// categoryID is the category createAd puts ads into.
+	categoryID int64
 }
 
-func getTestClient() *testClient {
+// testHasher keeps sign-ups in tests fast; production uses app.DefaultArgon2idParams.
+var testHasher = app.NewArgon2idHasher(app.Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+
+func getTestClient(opts ...app.Option) *testClient {
 	logrus.SetFormatter(new(logrus.JSONFormatter))
 
-	db := &mocks.RepositoryDbUser{}
-	a := app.NewApp(adrepo.New(), userrepo.New(), db)
+	users := userrepo.New()
+	a := app.NewApp(adrepo.New(), users, users, append([]app.Option{app.WithPasswordHasher(testHasher)}, opts...)...)
 	server := httpgin.NewHTTPServer(":18080", a)
 	testServer := httptest.NewServer(server.Handler)
 
+	category, err := a.CreateCategory(operatorContext(), nil, "Other", "other")
+	if err != nil {
+		panic(err)
+	}
+
 	return &testClient{
-		client:  testServer.Client(),
-		baseURL: testServer.URL,
+		client:     testServer.Client(),
+		baseURL:    testServer.URL,
+		app:        a,
+		tokens:     map[int64]string{},
+		adminID:    -1,
+		categoryID: category.ID,
+	}
+}
+
+// operatorContext acts as an admin the way the commands in cmd/main do.
+func operatorContext() context.Context {
+	return app.ContextWithPrincipal(context.Background(), app.Principal{Role: user.RoleAdmin})
+}
+
+// authorize sends the request as userID if createAccount signed that user in.
+func (tc *testClient) authorize(req *http.Request, userID int64) {
+	if token, ok := tc.tokens[userID]; ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// authorizeUser sends a request about userID as that user if they are
+// signed in, or else as the admin made by createStaff.
+func (tc *testClient) authorizeUser(req *http.Request, userID int64) {
+	if _, ok := tc.tokens[userID]; ok {
+		tc.authorize(req, userID)
+		return
+	}
+	tc.authorize(req, tc.adminID)
+}
+
+// authorizeAdmin sends req as the admin, made by createStaff on first use,
+// who sees every ad. The mock app has no accounts and lets anyone see them.
+func (tc *testClient) authorizeAdmin(req *http.Request) error {
+	if _, mocked := tc.app.(*mocks.App); !mocked && tc.adminID == -1 {
+		if _, err := tc.createStaff(user.RoleAdmin); err != nil {
+			return err
+		}
+	}
+	tc.authorize(req, tc.adminID)
+	return nil
+}
+
+// createStaff signs up a user with role, granted the way the "users role"
+// command does it, and signs them in.
+func (tc *testClient) createStaff(role user.Role) (int64, error) {
+	username := fmt.Sprintf("staff%d", len(tc.tokens))
+	signed, err := tc.signUp(string(role), username, "qwerty")
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := tc.app.SetUserRole(operatorContext(), signed.ID, role); err != nil {
+		return 0, err
+	}
+
+	token, err := tc.signIn(username, "qwerty")
+	if err != nil {
+		return 0, err
+	}
+	tc.tokens[signed.ID] = token.Token
+	if role == user.RoleAdmin {
+		tc.adminID = signed.ID
 	}
+
+	return signed.ID, nil
+}
+
+// createAccount signs a user up and in, so that ad requests can act as them.
+func (tc *testClient) createAccount(nickname string, email string) (userResponse, error) {
+	username := fmt.Sprintf("user%d", len(tc.tokens))
+	signed, err := tc.signUp(nickname, username, "qwerty")
+	if err != nil {
+		return userResponse{}, err
+	}
+
+	token, err := tc.signIn(username, "qwerty")
+	if err != nil {
+		return userResponse{}, err
+	}
+	tc.tokens[signed.ID] = token.Token
+
+	return userResponse{Data: userData{UserID: signed.ID, NickName: nickname, Email: email}}, nil
 }
 
 func (tc *testClient) getResponse(req *http.Request, out any) error {
+	_, err := tc.getResponseHeader(req, out)
+	return err
+}
+
+// getResponseHeader is getResponse that returns the headers of a successful
+// response too.
+func (tc *testClient) getResponseHeader(req *http.Request, out any) (http.Header, error) {
 	resp, err := tc.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("unexpected error: %w", err)
+		return nil, fmt.Errorf("unexpected error: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusBadRequest {
-			return ErrBadRequest
+			return nil, ErrBadRequest
 		}
 		if resp.StatusCode == http.StatusForbidden {
-			return ErrForbidden
+			return nil, ErrForbidden
 		}
 		if resp.StatusCode == 404 {
-			return ErrNotFound
+			return nil, ErrNotFound
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			return nil, ErrUnauthorized
+		}
+		if resp.StatusCode == http.StatusRequestEntityTooLarge {
+			return nil, ErrTooLarge
+		}
+		if resp.StatusCode == http.StatusUnsupportedMediaType {
+			return nil, ErrUnsupportedMedia
 		}
-		return fmt.Errorf("unexpected status code: %s", resp.Status)
+		if resp.StatusCode == http.StatusPreconditionFailed {
+			return nil, ErrPreconditionFailed
+		}
+		return nil, fmt.Errorf("unexpected status code: %s", resp.Status)
 	}
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("unable to read response: %w", err)
+		return nil, fmt.Errorf("unable to read response: %w", err)
 	}
 
 	err = json.Unmarshal(respBody, out)
 	if err != nil {
-		return fmt.Errorf("unable to unmarshal: %w", err)
+		return nil, fmt.Errorf("unable to unmarshal: %w", err)
 	}
 
-	return nil
+	return resp.Header, nil
 }
 
 func (tc *testClient) createAd(userID int64, title string, text string) (adResponse, error) {
+	return tc.createAdPriced(userID, title, text, 0, "")
+}
+
+// createAdPriced leaves the currency out of the request when it is empty.
+func (tc *testClient) createAdPriced(userID int64, title string, text string, price int64, currency string) (adResponse, error) {
 	body := map[string]any{
-		"user_id": userID,
-		"title":   title,
-		"text":    text,
+		"user_id":     userID,
+		"title":       title,
+		"text":        text,
+		"category_id": tc.categoryID,
+		"price":       price,
 	}
-
-	data, err := json.Marshal(body)
-	if err != nil {
-		return adResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	if currency != "" {
+		body["currency"] = currency
 	}
 
-	req, err := http.NewRequest(http.MethodPost, tc.baseURL+"/api/v1/ads", bytes.NewReader(data))
-	if err != nil {
-		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
+	return tc.sendAd(http.MethodPost, "/api/v1/ads", userID, body)
+}
+
+// createAdAt creates an ad with the fields of place, such as lat, lon and
+// city, added to the request.
+func (tc *testClient) createAdAt(userID int64, title string, place map[string]any) (adResponse, error) {
+	body := map[string]any{
+		"title":       title,
+		"text":        "text",
+		"category_id": tc.categoryID,
+	}
+	for name, value := range place {
+		body[name] = value
 	}
 
-	req.Header.Add("Content-Type", "application/json")
+	return tc.sendAd(http.MethodPost, "/api/v1/ads", userID, body)
+}
 
-	var response adResponse
-	err = tc.getResponse(req, &response)
+// publishAd creates an ad of userID from fields, such as title, text, price
+// or lat, lon and city, and publishes it. The title is "hello" and the text
+// "text" unless given.
+func (tc *testClient) publishAd(userID int64, fields map[string]any) (adResponse, error) {
+	body := map[string]any{
+		"title":       "hello",
+		"text":        "text",
+		"category_id": tc.categoryID,
+	}
+	for name, value := range fields {
+		body[name] = value
+	}
+
+	ad, err := tc.sendAd(http.MethodPost, "/api/v1/ads", userID, body)
 	if err != nil {
 		return adResponse{}, err
 	}
-
-	return response, nil
+	return tc.changeAdStatus(userID, ad.Data.ID, true)
 }
 
-func (tc *testClient) changeAdStatus(userID int64, adID int64, published bool) (adResponse, error) {
+// updateAdAt updates an ad with the fields of place added to the request.
+func (tc *testClient) updateAdAt(userID int64, adID int64, title string, place map[string]any) (adResponse, error) {
 	body := map[string]any{
-		"user_id":   userID,
-		"published": published,
+		"title": title,
+		"text":  "text",
 	}
+	for name, value := range place {
+		body[name] = value
+	}
+
+	return tc.sendAd(http.MethodPut, fmt.Sprintf("/api/v1/ads/%d", adID), userID, body)
+}
 
+func (tc *testClient) sendAd(method string, path string, userID int64, body map[string]any) (adResponse, error) {
 	data, err := json.Marshal(body)
 	if err != nil {
 		return adResponse{}, fmt.Errorf("unable to marshal: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/status", adID), bytes.NewReader(data))
+	req, err := http.NewRequest(method, tc.baseURL+path, bytes.NewReader(data))
 	if err != nil {
 		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
 	}
 
 	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
 
 	var response adResponse
 	err = tc.getResponse(req, &response)
@@ -167,11 +461,10 @@ func (tc *testClient) changeAdStatus(userID int64, adID int64, published bool) (
 	return response, nil
 }
 
-func (tc *testClient) updateAd(userID int64, adID int64, title string, text string) (adResponse, error) {
+func (tc *testClient) changeAdStatus(userID int64, adID int64, published bool) (adResponse, error) {
 	body := map[string]any{
-		"user_id": userID,
-		"title":   title,
-		"text":    text,
+		"user_id":   userID,
+		"published": published,
 	}
 
 	data, err := json.Marshal(body)
@@ -179,12 +472,13 @@ func (tc *testClient) updateAd(userID int64, adID int64, title string, text stri
 		return adResponse{}, fmt.Errorf("unable to marshal: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d", adID), bytes.NewReader(data))
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/status", adID), bytes.NewReader(data))
 	if err != nil {
 		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
 	}
 
 	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
 
 	var response adResponse
 	err = tc.getResponse(req, &response)
@@ -195,6 +489,22 @@ func (tc *testClient) updateAd(userID int64, adID int64, title string, text stri
 	return response, nil
 }
 
+func (tc *testClient) updateAd(userID int64, adID int64, title string, text string) (adResponse, error) {
+	return tc.updateAdPriced(userID, adID, title, text, 0, "")
+}
+
+func (tc *testClient) updateAdPriced(userID int64, adID int64, title string, text string, price int64, currency string) (adResponse, error) {
+	body := map[string]any{
+		"user_id":  userID,
+		"title":    title,
+		"text":     text,
+		"price":    price,
+		"currency": currency,
+	}
+
+	return tc.sendAd(http.MethodPut, fmt.Sprintf("/api/v1/ads/%d", adID), userID, body)
+}
+
 func (tc *testClient) listAds() (adsResponse, error) {
 	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads", nil)
 	if err != nil {
@@ -246,6 +556,10 @@ func (tc *testClient) listAdsDate(day int64) (adsResponse, error) {
 	if err != nil {
 		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
 	}
+	// the old date filter lists the drafts to moderators only
+	if err := tc.authorizeAdmin(req); err != nil {
+		return adsResponse{}, err
+	}
 
 	var response adsResponse
 	err = tc.getResponse(req, &response)
@@ -255,11 +569,32 @@ func (tc *testClient) listAdsDate(day int64) (adsResponse, error) {
 	return response, nil
 }
 
+// getAd reads an ad as the admin, who sees it in any status; see getAdAs.
 func (tc *testClient) getAd(adID int64) (adResponse, error) {
 	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(tc.baseURL+"/api/v1/ads?ad_id=%d", adID), nil)
 	if err != nil {
 		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
 	}
+	if err := tc.authorizeAdmin(req); err != nil {
+		return adResponse{}, err
+	}
+
+	var response adResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adResponse{}, err
+	}
+
+	return response, nil
+}
+
+// getAdAs reads an ad as userID, or anonymously when they are not signed in.
+func (tc *testClient) getAdAs(userID int64, adID int64) (adResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(tc.baseURL+"/api/v1/ads?ad_id=%d", adID), nil)
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
 
 	var response adResponse
 	err = tc.getResponse(req, &response)
@@ -310,6 +645,7 @@ func (tc *testClient) deleteAd(adID int64, authorID int64) (adResponse, error) {
 	}
 
 	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, authorID)
 
 	var response adResponse
 	err = tc.getResponse(req, &response)
@@ -365,6 +701,8 @@ func (tc *testClient) updateUser(nickname string, email string, userID int64, ac
 		return userResponse{}, fmt.Errorf("unable to create request: %w", err)
 	}
 
+	tc.authorizeUser(req, userID)
+
 	req.Header.Add("Content-Type", "application/json")
 
 	var response userResponse
@@ -391,6 +729,8 @@ func (tc *testClient) deleteUser(user_id int64) (userDeleteResponse, error) {
 		return userDeleteResponse{}, fmt.Errorf("unable to create request: %w", err)
 	}
 
+	tc.authorizeUser(req, user_id)
+
 	req.Header.Add("Content-Type", "application/json")
 
 	var response userDeleteResponse
@@ -427,3 +767,552 @@ func (tc *testClient) getUser(userID int64) (userResponse, error) {
 
 	return response, nil
 }
+
+func (tc *testClient) signUp(name string, username string, password string) (signUpResponse, error) {
+	body := map[string]any{
+		"name":     name,
+		"username": username,
+		"password": password,
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return signUpResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, tc.baseURL+"/api/v1/sign-up", bytes.NewReader(data))
+	if err != nil {
+		return signUpResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	var response signUpResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return signUpResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) signIn(username string, password string) (signInResponse, error) {
+	body := map[string]any{
+		"username": username,
+		"password": password,
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return signInResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, tc.baseURL+"/api/v1/sign-in", bytes.NewReader(data))
+	if err != nil {
+		return signInResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	var response signInResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return signInResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) refresh(refreshToken string) (signInResponse, error) {
+	body := map[string]any{
+		"refresh_token": refreshToken,
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return signInResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, tc.baseURL+"/api/v1/refresh", bytes.NewReader(data))
+	if err != nil {
+		return signInResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	var response signInResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return signInResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) signOut(token string) error {
+	req, err := http.NewRequest(http.MethodPost, tc.baseURL+"/api/v1/sign-out", nil)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return tc.getResponse(req, &struct{}{})
+}
+
+func (tc *testClient) setUserRole(actorID int64, userID int64, role user.Role) (userResponse, error) {
+	body := map[string]any{
+		"role": role,
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return userResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf(tc.baseURL+"/api/v1/user/role/%d", userID), bytes.NewReader(data))
+	if err != nil {
+		return userResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, actorID)
+
+	var response userResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return userResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) revokeUserSessions(actorID int64, userID int64) error {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf(tc.baseURL+"/api/v1/user/revoke-sessions/%d", userID), nil)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+
+	tc.authorize(req, actorID)
+
+	return tc.getResponse(req, &struct{}{})
+}
+
+func (tc *testClient) createCategory(actorID int64, parentID *int64, name string, slug string) (categoryResponse, error) {
+	return tc.sendCategory(http.MethodPost, tc.baseURL+"/api/v1/categories", actorID, parentID, name, slug)
+}
+
+func (tc *testClient) updateCategory(actorID int64, categoryID int64, parentID *int64, name string, slug string) (categoryResponse, error) {
+	return tc.sendCategory(http.MethodPut, fmt.Sprintf(tc.baseURL+"/api/v1/categories/%d", categoryID), actorID, parentID, name, slug)
+}
+
+func (tc *testClient) sendCategory(method string, url string, actorID int64, parentID *int64, name string, slug string) (categoryResponse, error) {
+	body := map[string]any{
+		"parent_id": parentID,
+		"name":      name,
+		"slug":      slug,
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return categoryResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		return categoryResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, actorID)
+
+	var response categoryResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return categoryResponse{}, err
+	}
+
+	return response, nil
+}
+
+func (tc *testClient) deleteCategory(actorID int64, categoryID int64) error {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf(tc.baseURL+"/api/v1/categories/%d", categoryID), nil)
+	if err != nil {
+		return fmt.Errorf("unable to create request: %w", err)
+	}
+
+	tc.authorize(req, actorID)
+
+	return tc.getResponse(req, &struct{}{})
+}
+
+func (tc *testClient) listCategories() (categoriesResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/categories", nil)
+	if err != nil {
+		return categoriesResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	var response categoriesResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return categoriesResponse{}, err
+	}
+	return response, nil
+}
+
+// listAdsCategory filters by a category id or slug.
+func (tc *testClient) listAdsCategory(category string) (adsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads?filter=category&category="+category, nil)
+	if err != nil {
+		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	var response adsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adsResponse{}, err
+	}
+	return response, nil
+}
+
+// listAdsPrice sends query, e.g. "min_price=100&currency=RUB", with filter=price.
+func (tc *testClient) listAdsPrice(query string) (adsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads?filter=price&"+query, nil)
+	if err != nil {
+		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	var response adsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adsResponse{}, err
+	}
+	return response, nil
+}
+
+// listAdsQuery sends query as is, e.g. "filter=author&author_id=1&limit=2".
+func (tc *testClient) listAdsQuery(query string) (adsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads?"+query, nil)
+	if err != nil {
+		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	var response adsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adsResponse{}, err
+	}
+	return response, nil
+}
+
+// listAdsQueryAs is listAdsQuery signed in as userID.
+func (tc *testClient) listAdsQueryAs(userID int64, query string) (adsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads?"+query, nil)
+	if err != nil {
+		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response adsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adsResponse{}, err
+	}
+	return response, nil
+}
+
+func (tc *testClient) searchAds(query string) (adsResponse, error) {
+	return tc.searchAdsAs(-1, query)
+}
+
+// searchAdsAs is searchAds signed in as userID; -1 is nobody.
+func (tc *testClient) searchAdsAs(userID int64, query string) (adsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads/search?"+query, nil)
+	if err != nil {
+		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response adsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adsResponse{}, err
+	}
+	return response, nil
+}
+
+func (tc *testClient) suggestAds(query string) (suggestionsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/ads/suggest?"+query, nil)
+	if err != nil {
+		return suggestionsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+
+	var response suggestionsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return suggestionsResponse{}, err
+	}
+	return response, nil
+}
+
+// uploadImages sends files as the "image" field of one multipart request.
+func (tc *testClient) uploadImages(userID int64, adID int64, files ...[]byte) (adResponse, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for i, file := range files {
+		part, err := form.CreateFormFile("image", fmt.Sprintf("image%d", i))
+		if err != nil {
+			return adResponse{}, fmt.Errorf("unable to write form: %w", err)
+		}
+		if _, err := part.Write(file); err != nil {
+			return adResponse{}, fmt.Errorf("unable to write form: %w", err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		return adResponse{}, fmt.Errorf("unable to write form: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/images", adID), &body)
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Add("Content-Type", form.FormDataContentType())
+	tc.authorize(req, userID)
+
+	var response adResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adResponse{}, err
+	}
+	return response, nil
+}
+
+// listImages lists the images of an ad as the admin, who sees them in any
+// status of the ad.
+func (tc *testClient) listImages(adID int64) ([]imageData, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/images", adID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	if err := tc.authorizeAdmin(req); err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Data []imageData `json:"data"`
+	}
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+// listImagesAs lists the images of an ad as userID, or anonymously when
+// they are not signed in.
+func (tc *testClient) listImagesAs(userID int64, adID int64) ([]imageData, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/images", adID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response struct {
+		Data []imageData `json:"data"`
+	}
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return nil, err
+	}
+	return response.Data, nil
+}
+
+func (tc *testClient) reorderImages(userID int64, adID int64, imageIDs ...int64) (adResponse, error) {
+	data, err := json.Marshal(map[string]any{"image_ids": imageIDs})
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/images/order", adID), bytes.NewReader(data))
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
+
+	var response adResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adResponse{}, err
+	}
+	return response, nil
+}
+
+func (tc *testClient) deleteImage(userID int64, adID int64, imageID int64) (adResponse, error) {
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/images/%d", adID, imageID), nil)
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response adResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adResponse{}, err
+	}
+	return response, nil
+}
+
+func (tc *testClient) renewAd(userID int64, adID int64) (adResponse, error) {
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf(tc.baseURL+"/api/v1/ads/%d/renew", adID), nil)
+	if err != nil {
+		return adResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response adResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adResponse{}, err
+	}
+	return response, nil
+}
+
+// moderationQueue sends query as is, e.g. "limit=2", as userID.
+func (tc *testClient) moderationQueue(userID int64, query string) (adsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/moderation/ads?"+query, nil)
+	if err != nil {
+		return adsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response adsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return adsResponse{}, err
+	}
+	return response, nil
+}
+
+func (tc *testClient) approveAd(userID int64, adID int64) (adResponse, error) {
+	return tc.sendAd(http.MethodPost, fmt.Sprintf("/api/v1/moderation/ads/%d/approve", adID), userID, map[string]any{})
+}
+
+func (tc *testClient) rejectAd(userID int64, adID int64, reason string) (adResponse, error) {
+	return tc.sendAd(http.MethodPost, fmt.Sprintf("/api/v1/moderation/ads/%d/reject", adID), userID, map[string]any{"reason": reason})
+}
+
+func (tc *testClient) sendReport(path string, userID int64, body map[string]any) (reportResponse, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return reportResponse{}, fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, tc.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return reportResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	tc.authorize(req, userID)
+
+	var response reportResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return reportResponse{}, err
+	}
+	return response, nil
+}
+
+func (tc *testClient) reportAd(userID int64, adID int64, reason string, comment string) (reportResponse, error) {
+	return tc.sendReport(fmt.Sprintf("/api/v1/ads/%d/reports", adID), userID, map[string]any{"reason": reason, "comment": comment})
+}
+
+// listReports sends query as is, e.g. "status=any", as userID.
+func (tc *testClient) listReports(userID int64, query string) (reportsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, tc.baseURL+"/api/v1/moderation/reports?"+query, nil)
+	if err != nil {
+		return reportsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response reportsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return reportsResponse{}, err
+	}
+	return response, nil
+}
+
+func (tc *testClient) resolveReport(userID int64, reportID int64, resolution string) (reportResponse, error) {
+	return tc.sendReport(fmt.Sprintf("/api/v1/moderation/reports/%d/resolve", reportID), userID, map[string]any{"resolution": resolution})
+}
+
+func (tc *testClient) listRevisions(userID int64, adID int64) (revisionsResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/ads/%d/revisions", tc.baseURL, adID), nil)
+	if err != nil {
+		return revisionsResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response revisionsResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return revisionsResponse{}, err
+	}
+	return response, nil
+}
+
+func (tc *testClient) getRevision(userID int64, adID int64, number int) (revisionResponse, error) {
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/api/v1/ads/%d/revisions/%d", tc.baseURL, adID, number), nil)
+	if err != nil {
+		return revisionResponse{}, fmt.Errorf("unable to create request: %w", err)
+	}
+	tc.authorize(req, userID)
+
+	var response revisionResponse
+	err = tc.getResponse(req, &response)
+	if err != nil {
+		return revisionResponse{}, err
+	}
+	return response, nil
+}
+
+func (tc *testClient) restoreRevision(userID int64, adID int64, number int) (adResponse, error) {
+	return tc.sendAd(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/revisions/%d/restore", adID, number), userID, map[string]any{})
+}
+
+// updateAdIfMatch updates the ad with body and the If-Match header, unless
+// it is empty, and returns the ETag of the response.
+func (tc *testClient) updateAdIfMatch(userID int64, adID int64, ifMatch string, body map[string]any) (adResponse, string, error) {
+	return tc.sendAdIfMatch(http.MethodPut, fmt.Sprintf("/api/v1/ads/%d", adID), userID, ifMatch, body)
+}
+
+// restoreRevisionIfMatch is restoreRevision with the If-Match header, unless
+// it is empty, and body.
+func (tc *testClient) restoreRevisionIfMatch(userID int64, adID int64, number int, ifMatch string, body map[string]any) (adResponse, string, error) {
+	return tc.sendAdIfMatch(http.MethodPost, fmt.Sprintf("/api/v1/ads/%d/revisions/%d/restore", adID, number), userID, ifMatch, body)
+}
+
+func (tc *testClient) sendAdIfMatch(method string, path string, userID int64, ifMatch string, body map[string]any) (adResponse, string, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return adResponse{}, "", fmt.Errorf("unable to marshal: %w", err)
+	}
+
+	req, err := http.NewRequest(method, tc.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return adResponse{}, "", fmt.Errorf("unable to create request: %w", err)
+	}
+	req.Header.Add("Content-Type", "application/json")
+	if ifMatch != "" {
+		req.Header.Add("If-Match", ifMatch)
+	}
+	tc.authorize(req, userID)
+
+	var response adResponse
+	header, err := tc.getResponseHeader(req, &response)
+	if err != nil {
+		return adResponse{}, "", err
+	}
+	return response, header.Get("ETag"), nil
+}
@@ -10,10 +10,10 @@ import (
 func TestCreateAd_EmptyTitle(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com")
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com")
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com")
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com")
 	assert.NoError(t, err)
 
 	_, err = client.createAd(1, "", "world")
@@ -25,10 +25,10 @@ func TestCreateAd_TooLongTitle(t *testing.T) {
 
 	title := strings.Repeat("a", 101)
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com")
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com")
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com")
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com")
 	assert.NoError(t, err)
 
 	_, err = client.createAd(1, title, "world")
@@ -38,10 +38,10 @@ func TestCreateAd_TooLongTitle(t *testing.T) {
 func TestCreateAd_EmptyText(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com")
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com")
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com")
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com")
 	assert.NoError(t, err)
 
 	_, err = client.createAd(1, "title", "")
@@ -53,10 +53,10 @@ func TestCreateAd_TooLongText(t *testing.T) {
 
 	text := strings.Repeat("a", 501)
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com")
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com")
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com")
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com")
 	assert.NoError(t, err)
 
 	_, err = client.createAd(1, "title", text)
@@ -66,10 +66,10 @@ func TestCreateAd_TooLongText(t *testing.T) {
 func TestUpdateAd_EmptyTitle(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
 	assert.NoError(t, err)
 
 	resp, err := client.createAd(1, "hello", "world")
@@ -82,10 +82,10 @@ func TestUpdateAd_EmptyTitle(t *testing.T) {
 func TestUpdateAd_TooLongTitle(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
 	assert.NoError(t, err)
 
 	resp, err := client.createAd(1, "hello", "world")
@@ -100,10 +100,10 @@ func TestUpdateAd_TooLongTitle(t *testing.T) {
 func TestUpdateAd_EmptyText(t *testing.T) {
 	client := getTestClient()
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
 	assert.NoError(t, err)
 
 	resp, err := client.createAd(1, "hello", "world")
@@ -118,10 +118,10 @@ func TestUpdateAd_TooLongText(t *testing.T) {
 
 	text := strings.Repeat("a", 501)
 
-	_, err := client.createUser("Gopher Gopherich", "gopher@go.com") 
+	_, err := client.createAccount("Gopher Gopherich", "gopher@go.com") 
 	assert.NoError(t, err)
 
-	_, err = client.createUser("Gopher Goshevich", "gopher@go.com") 
+	_, err = client.createAccount("Gopher Goshevich", "gopher@go.com") 
 	assert.NoError(t, err)
 
 	resp, err := client.createAd(1, "hello", "world")
@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"testing"
+
+	grpcPort "ads/internal/ports/grpc"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAdVersions(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), ad.Data.Version)
+
+	ad, err = client.updateAd(author.Data.UserID, ad.Data.ID, "hello", "there")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), ad.Data.Version)
+
+	ad, err = client.changeAdStatus(author.Data.UserID, ad.Data.ID, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), ad.Data.Version, "submitting and publishing the ad are new versions too")
+
+	got, err := client.getAd(ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), got.Data.Version)
+}
+
+func TestUpdateIfMatch(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+
+	updated, etag, err := client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, `"1"`, map[string]any{"title": "first", "text": "world"})
+	assert.NoError(t, err)
+	assert.Equal(t, `"2"`, etag)
+	assert.Equal(t, int64(2), updated.Data.Version)
+
+	_, _, err = client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, `"1"`, map[string]any{"title": "second", "text": "world"})
+	assert.ErrorIs(t, err, ErrPreconditionFailed, "the other update came first")
+	got, err := client.getAd(ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "first", got.Data.Title)
+
+	_, etag, err = client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, etag, map[string]any{"title": "second", "text": "world"})
+	assert.NoError(t, err)
+	assert.Equal(t, `"3"`, etag)
+
+	_, etag, err = client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, "*", map[string]any{"title": "any", "text": "world"})
+	assert.NoError(t, err)
+	assert.Equal(t, `"4"`, etag)
+	_, etag, err = client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, "", map[string]any{"title": "blind", "text": "world"})
+	assert.NoError(t, err, "updates without a version are not checked")
+	assert.Equal(t, `"5"`, etag)
+
+	for _, ifMatch := range []string{"5", `"five"`, `"4", "5"`} {
+		_, _, err = client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, ifMatch, map[string]any{"title": "bad", "text": "world"})
+		assert.ErrorIs(t, err, ErrBadRequest, ifMatch)
+	}
+
+	_, _, err = client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, `W/"5"`, map[string]any{"title": "weak", "text": "world"})
+	assert.ErrorIs(t, err, ErrPreconditionFailed, "a weak tag never matches strongly")
+	_, _, err = client.restoreRevisionIfMatch(author.Data.UserID, ad.Data.ID, 1, `W/"5"`, nil)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	got, err = client.getAd(ad.Data.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "blind", got.Data.Title)
+}
+
+func TestImagesChangeVersion(t *testing.T) {
+	client, _ := newMediaClient(t)
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+
+	ad, err = client.uploadImages(author.Data.UserID, ad.Data.ID, pngImage(t), gifImage)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), ad.Data.Version, "every image is a new version")
+	first, second := ad.Data.Images[0], ad.Data.Images[1]
+
+	ad, err = client.reorderImages(author.Data.UserID, ad.Data.ID, second.ID, first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), ad.Data.Version)
+	ad, err = client.deleteImage(author.Data.UserID, ad.Data.ID, first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), ad.Data.Version)
+
+	_, _, err = client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, `"4"`, map[string]any{"title": "stale", "text": "world"})
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	_, etag, err := client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, `"5"`, map[string]any{"title": "fresh", "text": "world"})
+	assert.NoError(t, err)
+	assert.Equal(t, `"6"`, etag)
+}
+
+func TestUpdateExpectedVersion(t *testing.T) {
+	client := getTestClient()
+	author, err := client.createAccount("alex", "alex@mai.com")
+	assert.NoError(t, err)
+	ad, err := client.createAd(author.Data.UserID, "hello", "world")
+	assert.NoError(t, err)
+
+	_, _, err = client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, "", map[string]any{"title": "stale", "text": "world", "expected_version": 7})
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	updated, _, err := client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, "", map[string]any{"title": "fresh", "text": "world", "expected_version": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Data.Version)
+
+	_, _, err = client.updateAdIfMatch(author.Data.UserID, ad.Data.ID, `"2"`, map[string]any{"title": "both", "text": "world", "expected_version": 1})
+	assert.ErrorIs(t, err, ErrBadRequest, "If-Match and expected_version must agree")
+}
+
+func TestGRPCUpdateExpectedVersion(t *testing.T) {
+	client, ctx, a := newClient(t)
+	authorCtx, _ := signedIn(t, a, ctx, "alex")
+
+	ad, err := client.CreateAd(authorCtx, &grpcPort.CreateAdRequest{Title: "hello", Text: "world"})
+	assert.NoError(t, err, "client.CreateAd")
+	assert.Equal(t, int64(1), ad.Version)
+
+	stale := int64(5)
+	_, err = client.UpdateAd(authorCtx, &grpcPort.UpdateAdRequest{AdId: ad.Id, Title: "bye", Text: "world", ExpectedVersion: &stale})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	updated, err := client.UpdateAd(authorCtx, &grpcPort.UpdateAdRequest{AdId: ad.Id, Title: "bye", Text: "world", ExpectedVersion: &ad.Version})
+	assert.NoError(t, err, "client.UpdateAd")
+	assert.Equal(t, int64(2), updated.Version)
+}
@@ -0,0 +1,206 @@
+// Package thumbnail makes the renditions of ad images: it decodes an image,
+// turns it upright by its EXIF orientation, scales it down and encodes it
+// again. Nothing but the pixels is kept, so the metadata of the upload,
+// location included, doesn't reach the renditions.
+package thumbnail
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+
+	// decoders of the uploads
+	_ "image/gif"
+
+	"ads/internal/ads"
+)
+
+// MaxPixels bounds the images that are decoded, so that a small file of
+// huge dimensions can't exhaust the memory.
+const MaxPixels = 50_000_000
+
+const jpegQuality = 85
+
+// Result is an encoded rendition.
+type Result struct {
+	Data   []byte
+	Width  int
+	Height int
+}
+
+// Check reads the header of an encoded image and fails unless Render can
+// decode it.
+func Check(data []byte) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if config.Width*config.Height > MaxPixels {
+		return fmt.Errorf("image of %dx%d pixels is too large", config.Width, config.Height)
+	}
+	return nil
+}
+
+// Render makes the rendition of spec from an encoded JPEG, PNG or GIF
+// image, the types ads.ImageExtension accepts.
+func Render(data []byte, spec ads.RenditionSpec) (*Result, error) {
+	if err := Check(data); err != nil {
+		return nil, err
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	img := orient(toRGBA(src), Orientation(data))
+
+	width, height := Fit(img.Rect.Dx(), img.Rect.Dy(), spec.Width, spec.Height)
+	img = resize(img, width, height)
+
+	var buf bytes.Buffer
+	switch spec.Format {
+	case "jpeg":
+		err = jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: jpegQuality})
+	case "png":
+		err = png.Encode(&buf, img)
+	default:
+		err = fmt.Errorf("unknown rendition format %q", spec.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Data: buf.Bytes(), Width: width, Height: height}, nil
+}
+
+// Fit scales width by height down, keeping the proportions, to fit in
+// maxWidth by maxHeight, where 0 is no limit. Images are never scaled up.
+func Fit(width, height, maxWidth, maxHeight int) (int, int) {
+	scale := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		scale = float64(maxWidth) / float64(width)
+	}
+	if maxHeight > 0 && height > maxHeight {
+		if s := float64(maxHeight) / float64(height); s < scale {
+			scale = s
+		}
+	}
+	if scale == 1 {
+		return width, height
+	}
+
+	w, h := int(float64(width)*scale+0.5), int(float64(height)*scale+0.5)
+	if w < 1 {
+		w = 1
+	}
+	if h < 1 {
+		h = 1
+	}
+	return w, h
+}
+
+func toRGBA(src image.Image) *image.RGBA {
+	b := src.Bounds()
+	img := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(img, img.Rect, src, b.Min, draw.Src)
+	return img
+}
+
+// flatten puts img on white, as JPEG has no transparency.
+func flatten(img *image.RGBA) *image.RGBA {
+	flat := image.NewRGBA(img.Rect)
+	draw.Draw(flat, flat.Rect, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Rect, img, image.Point{}, draw.Over)
+	return flat
+}
+
+// resize scales img to width by height, averaging the source pixels that
+// fall into each pixel of the result. The pixels of image.RGBA are
+// premultiplied by alpha, so transparent ones don't darken the edges.
+func resize(img *image.RGBA, width, height int) *image.RGBA {
+	sw, sh := img.Rect.Dx(), img.Rect.Dy()
+	if sw == width && sh == height {
+		return img
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0, y1 := span(y, height, sh)
+		for x := 0; x < width; x++ {
+			x0, x1 := span(x, width, sw)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := img.Pix[sy*img.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+
+			i := dst.PixOffset(x, y)
+			dst.Pix[i] = uint8((r + n/2) / n)
+			dst.Pix[i+1] = uint8((g + n/2) / n)
+			dst.Pix[i+2] = uint8((b + n/2) / n)
+			dst.Pix[i+3] = uint8((a + n/2) / n)
+		}
+	}
+	return dst
+}
+
+// span is the range of source pixels under pixel i of n, with at least one
+// pixel in it when scaling up.
+func span(i, n, size int) (int, int) {
+	from := i * size / n
+	to := (i + 1) * size / n
+	if to <= from {
+		to = from + 1
+	}
+	return from, to
+}
+
+// orient applies an EXIF orientation, from 1 to 8, to img.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored
+				sx, sy = w-1-x, y
+			case 3: // upside down
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored upside down
+				sx, sy = x, h-1-y
+			case 5: // mirrored and turned left
+				sx, sy = y, x
+			case 6: // turned left, so it is turned right
+				sx, sy = y, h-1-x
+			case 7: // mirrored and turned right
+				sx, sy = w-1-y, h-1-x
+			case 8: // turned right, so it is turned left
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], img.Pix[img.PixOffset(sx, sy):img.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
@@ -12,5 +12,18 @@ type RepositoryUser interface {
 
 //go:generate mockery --output ../tests/mocks --name RepositoryDbUser
 type RepositoryDbUser interface {
-	CreateUserDb(user UserDb) (int, error)
+	CreateUserDb(user User) (int, error)
+	GetUserByUsername(ctx context.Context, username string) (*User, error)
+	UpdatePasswordHash(ctx context.Context, userID int64, hash string) error
+	GetUser(ctx context.Context, user_id int64) (*User, error)
+	SetUserRole(ctx context.Context, userID int64, role Role) (*User, error)
+
+	CreateSession(ctx context.Context, session *Session) (int64, error)
+	GetSessionByTokenHash(ctx context.Context, tokenHash string) (*Session, error)
+	// RevokeSession reports false if the session had already been revoked.
+	RevokeSession(ctx context.Context, sessionID int64) (bool, error)
+	RevokeSessionFamily(ctx context.Context, familyID string) error
+	RevokeUserSessions(ctx context.Context, userID int64) error
+	// SessionFamilyActive reports whether the family still has an unrevoked, unexpired session.
+	SessionFamilyActive(ctx context.Context, familyID string) (bool, error)
 }
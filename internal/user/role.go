@@ -0,0 +1,15 @@
+package user
+
+type Role string
+
+const (
+	RoleUser Role = "user"
+	// RoleModerator can take down any ad.
+	RoleModerator Role = "moderator"
+	// RoleAdmin can do what a moderator can and manage users.
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) Valid() bool {
+	return r == RoleUser || r == RoleModerator || r == RoleAdmin
+}
@@ -0,0 +1,15 @@
+package user
+
+import "time"
+
+// Session is one refresh token. Tokens rotated from the same sign-in share
+// a FamilyID, and the access tokens issued with them carry that FamilyID.
+type Session struct {
+	ID        int64      `db:"id"`
+	UserID    int64      `db:"user_id"`
+	FamilyID  string     `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	CreatedAt time.Time  `db:"created_at"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+}
@@ -1,15 +1,11 @@
 package user
 
 type User struct {
-	UserID  int64
-	NickName string
-	Email   string
-	Activate bool
-}
-
-type UserDb struct {
-	Id       int    `json:"-" db:"id"`
-	Name     string `json:"name" binding:"required"`
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	UserID   int64  `db:"id"`
+	NickName string `db:"name"`
+	Email    string `db:"email"`
+	Activate bool   `db:"activate"`
+	Username string `db:"username"`
+	Password string `db:"password_hash"`
+	Role     Role   `db:"role"`
 }
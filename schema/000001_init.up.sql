@@ -1,4 +1,4 @@
-CREATE TABLE users 
+CREATE TABLE IF NOT EXISTS users 
 (
     id serial not null unique,
     name varchar(255) not null,
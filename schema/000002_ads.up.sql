@@ -0,0 +1,13 @@
+CREATE TABLE ads
+(
+    id bigserial not null unique,
+    title varchar(255) not null,
+    text text not null,
+    author_id bigint not null,
+    published boolean not null default false,
+    create_date timestamptz not null,
+    update_date timestamptz not null
+);
+
+CREATE INDEX ads_author_id_idx ON ads (author_id);
+CREATE INDEX ads_published_idx ON ads (published);
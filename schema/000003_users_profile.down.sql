@@ -0,0 +1,9 @@
+ALTER TABLE ads DROP CONSTRAINT ads_author_id_fkey;
+
+DELETE FROM users WHERE username IS NULL OR password_hash IS NULL;
+
+ALTER TABLE users
+    DROP COLUMN email,
+    DROP COLUMN activate,
+    ALTER COLUMN username SET NOT NULL,
+    ALTER COLUMN password_hash SET NOT NULL;
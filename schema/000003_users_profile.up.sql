@@ -0,0 +1,8 @@
+ALTER TABLE users
+    ADD COLUMN email varchar(255) not null default '',
+    ADD COLUMN activate boolean not null default false,
+    ALTER COLUMN username DROP NOT NULL,
+    ALTER COLUMN password_hash DROP NOT NULL;
+
+ALTER TABLE ads
+    ADD CONSTRAINT ads_author_id_fkey FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE CASCADE;
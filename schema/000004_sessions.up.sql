@@ -0,0 +1,13 @@
+CREATE TABLE sessions
+(
+    id bigserial not null unique,
+    user_id bigint not null references users (id) on delete cascade,
+    family_id varchar(64) not null,
+    token_hash varchar(64) not null unique,
+    created_at timestamptz not null,
+    expires_at timestamptz not null,
+    revoked_at timestamptz
+);
+
+CREATE INDEX sessions_family_id_idx ON sessions (family_id);
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN role varchar(16) not null default 'user'
+        CONSTRAINT users_role_check CHECK (role IN ('user', 'moderator', 'admin'));
@@ -0,0 +1,3 @@
+ALTER TABLE ads DROP COLUMN category_id;
+
+DROP TABLE categories;
@@ -0,0 +1,18 @@
+CREATE TABLE categories
+(
+    id bigserial not null unique,
+    parent_id bigint references categories (id) on delete restrict,
+    name varchar(255) not null,
+    slug varchar(100) not null unique
+);
+
+CREATE INDEX categories_parent_id_idx ON categories (parent_id);
+
+-- ads created before categories existed are moved into "other"
+INSERT INTO categories (name, slug) VALUES ('Other', 'other');
+
+ALTER TABLE ads ADD COLUMN category_id bigint references categories (id) on delete restrict;
+UPDATE ads SET category_id = (SELECT id FROM categories WHERE slug = 'other');
+ALTER TABLE ads ALTER COLUMN category_id SET NOT NULL;
+
+CREATE INDEX ads_category_id_idx ON ads (category_id);
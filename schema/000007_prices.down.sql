@@ -0,0 +1,4 @@
+DROP INDEX ads_currency_price_idx;
+
+ALTER TABLE ads DROP COLUMN currency;
+ALTER TABLE ads DROP COLUMN price;
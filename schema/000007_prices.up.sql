@@ -0,0 +1,4 @@
+ALTER TABLE ads ADD COLUMN price bigint not null default 0 check (price >= 0);
+ALTER TABLE ads ADD COLUMN currency char(3) not null default 'RUB';
+
+CREATE INDEX ads_currency_price_idx ON ads (currency, price);
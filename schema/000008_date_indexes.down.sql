@@ -0,0 +1,2 @@
+DROP INDEX ads_update_date_idx;
+DROP INDEX ads_create_date_idx;
@@ -0,0 +1,2 @@
+CREATE INDEX ads_create_date_idx ON ads (create_date, id);
+CREATE INDEX ads_update_date_idx ON ads (update_date, id);
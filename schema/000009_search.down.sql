@@ -0,0 +1,3 @@
+DROP INDEX ads_search_idx;
+
+ALTER TABLE ads DROP COLUMN search_vector;
@@ -0,0 +1,3 @@
+DROP INDEX ads_title_trgm_idx;
+
+DROP EXTENSION IF EXISTS pg_trgm;
@@ -0,0 +1,3 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX ads_title_trgm_idx ON ads USING GIN (lower(title) gin_trgm_ops);
@@ -0,0 +1,13 @@
+CREATE TABLE ad_images
+(
+    id bigserial not null unique,
+    ad_id bigint not null references ads (id) on delete cascade,
+    key varchar(255) not null,
+    url text not null,
+    content_type varchar(100) not null,
+    size bigint not null,
+    position int not null check (position >= 0),
+    create_date timestamptz not null,
+    -- deferred, so reordering can swap positions
+    unique (ad_id, position) deferrable initially deferred
+);
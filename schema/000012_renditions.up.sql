@@ -0,0 +1,13 @@
+CREATE TABLE ad_image_renditions
+(
+    id bigserial not null unique,
+    image_id bigint not null references ad_images (id) on delete cascade,
+    name varchar(64) not null,
+    key varchar(255) not null,
+    url text not null,
+    content_type varchar(100) not null,
+    width int not null,
+    height int not null,
+    size bigint not null,
+    unique (image_id, name)
+);
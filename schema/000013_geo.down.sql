@@ -0,0 +1,11 @@
+DROP INDEX ads_city_idx;
+DROP INDEX ads_lat_lon_idx;
+DROP INDEX ads_location_idx;
+
+ALTER TABLE ads DROP CONSTRAINT ads_location_check;
+ALTER TABLE ads DROP COLUMN city;
+ALTER TABLE ads DROP COLUMN lon;
+ALTER TABLE ads DROP COLUMN lat;
+
+DROP EXTENSION IF EXISTS earthdistance;
+DROP EXTENSION IF EXISTS cube;
@@ -0,0 +1,11 @@
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+ALTER TABLE ads ADD COLUMN lat double precision check (lat between -90 and 90);
+ALTER TABLE ads ADD COLUMN lon double precision check (lon between -180 and 180);
+ALTER TABLE ads ADD COLUMN city varchar(100) not null default '';
+ALTER TABLE ads ADD CONSTRAINT ads_location_check check ((lat is null) = (lon is null));
+
+CREATE INDEX ads_location_idx ON ads USING GIST (ll_to_earth(lat, lon)) WHERE lat IS NOT NULL;
+CREATE INDEX ads_lat_lon_idx ON ads (lat, lon) WHERE lat IS NOT NULL;
+CREATE INDEX ads_city_idx ON ads (lower(city));
@@ -0,0 +1,4 @@
+DROP INDEX ads_expires_at_idx;
+
+ALTER TABLE ads DROP COLUMN expiry_notified;
+ALTER TABLE ads DROP COLUMN expires_at;
@@ -0,0 +1,8 @@
+ALTER TABLE ads ADD COLUMN expires_at timestamptz;
+ALTER TABLE ads ADD COLUMN expiry_notified boolean not null default false;
+
+-- the ads published so far get the default term from now on: 30 days, as
+-- SQL does not know AD_TTL
+UPDATE ads SET expires_at = now() + interval '30 days' WHERE published;
+
+CREATE INDEX ads_expires_at_idx ON ads (expires_at) WHERE published;
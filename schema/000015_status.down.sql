@@ -0,0 +1,10 @@
+ALTER TABLE ads ADD COLUMN published boolean not null default false;
+UPDATE ads SET published = true WHERE status = 'published';
+
+DROP INDEX ads_expires_at_idx;
+DROP INDEX ads_status_idx;
+ALTER TABLE ads DROP COLUMN rejection_reason;
+ALTER TABLE ads DROP COLUMN status;
+
+CREATE INDEX ads_published_idx ON ads (published);
+CREATE INDEX ads_expires_at_idx ON ads (expires_at) WHERE published;
@@ -0,0 +1,15 @@
+ALTER TABLE ads ADD COLUMN status varchar(20) not null default 'draft'
+    CHECK (status IN ('draft', 'pending_review', 'published', 'rejected', 'archived'));
+ALTER TABLE ads ADD COLUMN rejection_reason varchar(500) not null default '';
+
+-- the ads taken down by their expiry are archived, the other unpublished
+-- ones stay drafts
+UPDATE ads SET status = 'published' WHERE published;
+UPDATE ads SET status = 'archived' WHERE NOT published AND expires_at IS NOT NULL;
+
+DROP INDEX ads_expires_at_idx;
+DROP INDEX ads_published_idx;
+ALTER TABLE ads DROP COLUMN published;
+
+CREATE INDEX ads_status_idx ON ads (status, update_date, id);
+CREATE INDEX ads_expires_at_idx ON ads (expires_at) WHERE status = 'published';
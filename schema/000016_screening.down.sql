@@ -0,0 +1,2 @@
+ALTER TABLE ads DROP COLUMN screening_flags;
+ALTER TABLE ads DROP COLUMN screening_score;
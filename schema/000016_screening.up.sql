@@ -0,0 +1,2 @@
+ALTER TABLE ads ADD COLUMN screening_score double precision not null default 0;
+ALTER TABLE ads ADD COLUMN screening_flags text not null default '';
@@ -0,0 +1,16 @@
+CREATE TABLE ad_reports
+(
+    id bigserial not null unique,
+    ad_id bigint not null references ads (id) on delete cascade,
+    reporter_id bigint not null,
+    reason varchar(20) not null check (reason IN ('scam', 'spam', 'prohibited', 'offensive', 'other')),
+    comment varchar(1000) not null default '',
+    status varchar(20) not null default 'open' check (status IN ('open', 'upheld', 'dismissed')),
+    create_date timestamptz not null,
+    resolved_by bigint,
+    resolve_date timestamptz,
+    -- a user reports an ad once
+    unique (ad_id, reporter_id)
+);
+
+CREATE INDEX ad_reports_status_idx ON ad_reports (status, id);
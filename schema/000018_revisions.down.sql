@@ -0,0 +1 @@
+DROP TABLE ad_revisions;
@@ -0,0 +1,22 @@
+CREATE TABLE ad_revisions
+(
+    id bigserial not null unique,
+    ad_id bigint not null references ads (id) on delete cascade,
+    number int not null,
+    editor_id bigint not null,
+    title varchar(255) not null,
+    text text not null,
+    price bigint not null,
+    currency char(3) not null,
+    lat double precision,
+    lon double precision,
+    city varchar(100) not null default '',
+    restored_from int,
+    diff jsonb not null default '[]',
+    create_date timestamptz not null,
+    unique (ad_id, number)
+);
+
+-- the ads made before revisions start their history as they are now
+INSERT INTO ad_revisions (ad_id, number, editor_id, title, text, price, currency, lat, lon, city, create_date)
+SELECT id, 1, author_id, title, text, price, currency, lat, lon, city, update_date FROM ads;
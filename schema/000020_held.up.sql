@@ -0,0 +1,4 @@
+ALTER TABLE ads ADD COLUMN held boolean not null default false;
+
+-- the ads rejected so far were rejected by moderators
+UPDATE ads SET held = true WHERE status = 'rejected';
@@ -0,0 +1,8 @@
+DROP INDEX ads_search_idx;
+ALTER TABLE ads DROP COLUMN search_vector;
+
+ALTER TABLE ads ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', title), 'A') || setweight(to_tsvector('simple', text), 'B')
+) STORED;
+
+CREATE INDEX ads_search_idx ON ads USING GIN (search_vector);
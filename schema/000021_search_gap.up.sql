@@ -0,0 +1,11 @@
+DROP INDEX ads_search_idx;
+ALTER TABLE ads DROP COLUMN search_vector;
+
+-- a placeholder between the title and the text takes one position and is
+-- deleted, so that a phrase never runs from the title into the text; the
+-- parser never makes a lexeme of '#'
+ALTER TABLE ads ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    ts_delete(setweight(to_tsvector('simple', title), 'A') || '#:1'::tsvector || setweight(to_tsvector('simple', text), 'B'), '#')
+) STORED;
+
+CREATE INDEX ads_search_idx ON ads USING GIN (search_vector);
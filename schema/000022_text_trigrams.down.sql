@@ -0,0 +1,2 @@
+DROP INDEX ads_text_trgm_idx;
+DROP INDEX ads_title_like_trgm_idx;
@@ -0,0 +1,3 @@
+-- the ILIKE of the text filter and of the duplicate screening rule
+CREATE INDEX ads_title_like_trgm_idx ON ads USING GIN (title gin_trgm_ops);
+CREATE INDEX ads_text_trgm_idx ON ads USING GIN (text gin_trgm_ops);
@@ -0,0 +1,8 @@
+// Package schema embeds the SQL migrations so the service binary can apply
+// them without the schema directory next to it.
+package schema
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS